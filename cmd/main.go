@@ -4,13 +4,28 @@ import (
 	_ "app/docs"
 	"app/internal/api/handler"
 	"app/internal/api/middleware"
+	"app/internal/api/server"
+	"app/internal/config"
+	"app/internal/domain/model"
 	domainservice "app/internal/domain/service"
 	"app/internal/infra/db"
+	"app/internal/infra/events"
+	"app/internal/infra/health"
+	"app/internal/infra/notify"
+	"app/internal/infra/pubsub"
+	"app/internal/infra/ratelimit"
+	"app/internal/infra/search"
 	infraservice "app/internal/infra/service"
+	"app/internal/infra/worker"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
-	"net/http"
 	"os"
-	"time"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -29,72 +44,640 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 func main() {
-	database := db.Connect()
+	args := os.Args[1:]
+	if len(args) >= 2 && args[0] == "config" && args[1] == "print" {
+		printConfig(args[2:])
+		return
+	}
+	if len(args) >= 2 && args[0] == "users" && args[1] == "set-role" {
+		setUserRole(args[2:])
+		return
+	}
+
+	cfg := loadConfig(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	database := db.Connect(cfg.Database)
 
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "default_jwt_secret"
+	if err := db.Migrate(ctx, database); err != nil {
+		log.Fatal("Failed to run migrations:", err)
 	}
 
 	userRepository := db.NewUserRepository(database)
-	tokenService := infraservice.NewTokenService(jwtSecret, time.Hour)
+	tokenService := infraservice.NewTokenService(cfg.Auth.JWTSecret, cfg.Auth.TokenTTL)
 	authService := domainservice.NewAuthService(userRepository, tokenService)
 	authHandler := handler.NewAuthHandler(authService)
 
+	transactor := db.NewTransactor(database)
+	outboxRepository := db.NewOutboxRepository(database)
+
 	customerRepository := db.NewCustomerRepository(database)
-	customerService := domainservice.NewCustomerService(customerRepository)
-	customerHandler := handler.NewCustomerHandler(customerService)
+	customerSearchIndex := newCustomerSearchIndex(ctx, cfg.Search, database, customerRepository)
+	customerAttributeRepository := db.NewCustomerAttributeRepository(database)
+	customerService := domainservice.NewCustomerService(
+		customerRepository,
+		customerAttributeRepository,
+		customerSearchIndex,
+		transactor,
+		outboxRepository,
+		cfg.Customers.DeletionGracePeriod,
+	)
+	customerHandler := handler.NewCustomerHandler(customerService, cfg.Search.MaxPageSize)
+
+	customerAttributeService := domainservice.NewCustomerAttributeService(customerAttributeRepository)
+	customerAttributeHandler := handler.NewCustomerAttributeHandler(customerAttributeService)
 
 	favoriteRepository := db.NewFavoriteRepository(database)
-	productService := infraservice.NewProductService()
-	favoriteService := domainservice.NewFavoriteService(favoriteRepository, productService)
-	favoriteHandler := handler.NewFavoriteHandler(favoriteService)
+	favoriteChangeRepository := db.NewFavoriteChangeRepository(database)
+	productService := infraservice.NewProductService(cfg.Catalog.URL, cfg.Catalog.Timeout)
+	favoriteService := domainservice.NewFavoriteService(
+		favoriteRepository,
+		customerRepository,
+		productService,
+		transactor,
+		outboxRepository,
+		favoriteChangeRepository,
+	)
+	favoriteHandler := handler.NewFavoriteHandler(favoriteService, cfg.Favorites.BatchMaxOperations)
 
-	router := gin.Default()
+	favoriteCollectionRepository := db.NewFavoriteCollectionRepository(database)
+	favoriteCollectionService := domainservice.NewFavoriteCollectionService(
+		favoriteCollectionRepository,
+		customerRepository,
+		productService,
+	)
+	favoriteCollectionHandler := handler.NewFavoriteCollectionHandler(favoriteCollectionService)
+
+	shareLinkService := domainservice.NewShareLinkService(
+		db.NewShareLinkRepository(database),
+		customerRepository,
+		favoriteCollectionRepository,
+		favoriteRepository,
+		productService,
+	)
+	shareLinkHandler := handler.NewShareLinkHandler(shareLinkService, cfg.Sharing.CacheMaxAge)
+
+	favoriteChangeHub := pubsub.NewHub(cfg.Database.DSN(), db.FavoriteChangesChannel, cfg.Streams.ReconnectDelay)
+	favoriteStreamService := domainservice.NewFavoriteStreamService(
+		favoriteChangeRepository,
+		customerRepository,
+		favoriteChangeHub,
+		cfg.Streams.ReplayLimit,
+	)
+	favoriteStreamHandler := handler.NewFavoriteStreamHandler(favoriteStreamService, cfg.Streams.HeartbeatInterval)
+
+	customerImportRepository := db.NewCustomerImportRepository(database)
+	customerImportService := domainservice.NewCustomerImportService(
+		customerImportRepository,
+		customerAttributeRepository,
+		handler.ValidateCustomerCreate,
+		cfg.Imports.MaxRows,
+		cfg.Imports.BatchSize,
+		cfg.Imports.StaleTimeout,
+	)
+	customerImportHandler := handler.NewCustomerImportHandler(customerImportService)
+
+	customerTagService := domainservice.NewCustomerTagService(db.NewCustomerTagRepository(database), customerRepository)
+	customerTagHandler := handler.NewCustomerTagHandler(customerTagService)
+
+	customerSegmentService := domainservice.NewCustomerSegmentService(
+		db.NewCustomerSegmentRepository(database),
+		customerAttributeRepository,
+		productService,
+	)
+	customerSegmentHandler := handler.NewCustomerSegmentHandler(customerSegmentService)
+
+	customerPrivacyRepository := db.NewCustomerPrivacyRepository(database)
+	customerPrivacyService := domainservice.NewCustomerPrivacyService(customerPrivacyRepository)
+	customerPrivacyHandler := handler.NewCustomerPrivacyHandler(customerPrivacyService)
+
+	customerStateService := domainservice.NewCustomerStateService(
+		db.NewCustomerStateRepository(database),
+		customerRepository,
+		transactor,
+		outboxRepository,
+	)
+	customerStateHandler := handler.NewCustomerStateHandler(customerStateService)
+
+	customerExportRepository := db.NewCustomerExportRepository(database)
+	customerExportService := domainservice.NewCustomerExportService(customerExportRepository, productService)
+	customerExportHandler := handler.NewCustomerExportHandler(customerExportService)
+
+	idempotencyRepository := db.NewIdempotencyRepository(database)
+
+	webhookRepository := db.NewWebhookRepository(database)
+	webhookService := domainservice.NewWebhookService(webhookRepository)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	webhookDispatcher := domainservice.NewWebhookDispatcher(
+		webhookRepository,
+		transactor,
+		infraservice.NewWebhookSender(cfg.Webhooks.Timeout),
+		cfg.Webhooks.BatchSize,
+		cfg.Webhooks.MaxAttempts,
+		cfg.Webhooks.RetryDelay,
+		cfg.Webhooks.MaxRetryDelay,
+		cfg.Webhooks.Retention,
+		cfg.Webhooks.ClaimTimeout,
+	)
+
+	eventSinks, err := newEventSinks(cfg.Outbox)
+	if err != nil {
+		log.Fatal("Failed to create event sinks:", err)
+	}
+	// Subscriptions always receive events, whatever sinks are configured.
+	eventSinks = append(eventSinks, webhookDispatcher)
+	outboxDispatcher := domainservice.NewOutboxDispatcher(
+		outboxRepository,
+		eventSinks,
+		cfg.Outbox.BatchSize,
+		cfg.Outbox.RetryDelay,
+		cfg.Outbox.MaxRetryDelay,
+		cfg.Outbox.Retention,
+		cfg.Outbox.ClaimTimeout,
+	)
+
+	priceAlertService := domainservice.NewPriceAlertService(
+		db.NewProductPriceRepository(database),
+		db.NewPriceAlertRepository(database),
+		customerRepository,
+		productService,
+		transactor,
+		newPriceAlertNotifiers(cfg.PriceAlerts, outboxRepository),
+		cfg.PriceAlerts.BatchSize,
+		cfg.PriceAlerts.MaxAttempts,
+		cfg.PriceAlerts.ClaimTimeout,
+	)
+	priceAlertHandler := handler.NewPriceAlertHandler(priceAlertService)
+
+	favoriteAnalyticsService := domainservice.NewFavoriteAnalyticsService(db.NewFavoriteAnalyticsRepository(database), productService)
+	favoriteAnalyticsHandler := handler.NewFavoriteAnalyticsHandler(favoriteAnalyticsService)
+
+	workers := worker.NewRunner()
+	workers.Add(worker.Job{
+		Name:     "idempotency-cleanup",
+		Interval: cfg.Idempotency.CleanupInterval,
+		Run: func(c context.Context) error {
+			_, err := idempotencyRepository.DeleteExpired(c)
+			return err
+		},
+	})
+	workers.Add(worker.Job{
+		Name:     "customer-purge",
+		Interval: cfg.Customers.PurgeInterval,
+		Run: func(c context.Context) error {
+			purged, err := customerService.PurgeDeleted(c)
+			if purged > 0 {
+				log.Println("Purged deleted customers:", purged)
+			}
+			return err
+		},
+	})
+	workers.Add(worker.Job{
+		Name:     "customer-import",
+		Interval: cfg.Imports.PollInterval,
+		Run: func(c context.Context) error {
+			for {
+				processed, err := customerImportService.ProcessNext(c)
+				if err != nil || !processed {
+					return err
+				}
+			}
+		},
+	})
+
+	workers.Add(worker.Job{
+		Name:     "outbox-dispatch",
+		Interval: cfg.Outbox.PollInterval,
+		Run: func(c context.Context) error {
+			for {
+				dispatched, err := outboxDispatcher.DispatchNext(c)
+				if err != nil || !dispatched {
+					return err
+				}
+			}
+		},
+	})
+	workers.Add(worker.Job{
+		Name:     "outbox-cleanup",
+		Interval: cfg.Outbox.CleanupInterval,
+		Run: func(c context.Context) error {
+			_, err := outboxDispatcher.PurgeDispatched(c)
+			return err
+		},
+	})
+
+	workers.Add(worker.Job{
+		Name:     "webhook-delivery",
+		Interval: cfg.Webhooks.PollInterval,
+		Run: func(c context.Context) error {
+			for {
+				delivered, err := webhookDispatcher.DeliverNext(c)
+				if err != nil || !delivered {
+					return err
+				}
+			}
+		},
+	})
+	workers.Add(worker.Job{
+		Name:     "webhook-cleanup",
+		Interval: cfg.Webhooks.CleanupInterval,
+		Run: func(c context.Context) error {
+			_, err := webhookDispatcher.PurgeFinished(c)
+			return err
+		},
+	})
 
-	ginMode := os.Getenv("GIN_MODE")
-	if ginMode == "release" {
-		gin.SetMode(gin.ReleaseMode)
-	} else {
-		gin.SetMode(gin.DebugMode)
+	workers.Add(worker.Job{
+		Name:     "favorite-tombstone-cleanup",
+		Interval: cfg.Favorites.TombstoneCleanupInterval,
+		Run: func(c context.Context) error {
+			_, err := favoriteRepository.DeleteTombstones(c, cfg.Favorites.TombstoneRetention)
+			return err
+		},
+	})
+	workers.Add(worker.Job{
+		Name:     "favorite-change-cleanup",
+		Interval: cfg.Streams.CleanupInterval,
+		Run: func(c context.Context) error {
+			_, err := favoriteChangeRepository.DeleteOlderThan(c, cfg.Streams.HistoryRetention)
+			return err
+		},
+	})
+
+	workers.Add(worker.Job{
+		Name:     "price-check",
+		Interval: cfg.PriceAlerts.CheckInterval,
+		Run: func(c context.Context) error {
+			alerts, err := priceAlertService.CheckPrices(c)
+			if err != nil {
+				return err
+			}
+			if alerts > 0 {
+				log.Println("Created price alerts:", alerts)
+			}
+			// Alerts whose notification failed are retried on every check.
+			_, err = priceAlertService.NotifyPending(c)
+			return err
+		},
+	})
+	workers.Add(worker.Job{
+		Name:     "analytics-refresh",
+		Interval: cfg.Analytics.RefreshInterval,
+		Run: func(c context.Context) error {
+			_, err := favoriteAnalyticsService.Refresh(c)
+			return err
+		},
+	})
+
+	gin.SetMode(cfg.Server.Mode)
+
+	router := gin.Default()
+	// Without trusted proxies ClientIP is the peer address, so clients cannot
+	// pick their rate limit bucket through X-Forwarded-For.
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal("Failed to set trusted proxies:", err)
 	}
+	srv := server.New(cfg.Server, router)
 
 	router.Use(gin.Recovery())
+	router.Use(middleware.BodyLimitMiddleware(cfg.Server.MaxBodyBytes, map[string]int64{
+		"/api/v1/customers/import": cfg.Imports.MaxUploadBytes,
+	}))
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	router.GET("/health", func(c *gin.Context) {
-		if err := database.Ping(); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection failed"})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{"status": "OK"})
-	})
+	checks := []health.Check{
+		{Name: "database", Run: database.PingContext},
+		{Name: "migrations", Run: func(c context.Context) error { return db.CheckMigrations(c, database) }},
+		{Name: "workers", Run: workers.Check},
+		{Name: "change_listener", Run: favoriteChangeHub.Check},
+	}
+	if cfg.Health.CheckCatalog {
+		checks = append(checks, health.Check{Name: "product_catalog", Run: productService.Ping})
+	}
+	healthHandler := handler.NewHealthHandler(
+		health.NewChecker(cfg.Health.CheckTimeout, cfg.Health.CacheTTL, checks...),
+		srv.Draining,
+	)
+
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+	router.GET("/health", healthHandler.Readyz)
+
+	rateLimitStore := ratelimit.NewMemoryStore()
+
+	auth := router.Group("")
+	if cfg.RateLimit.Enabled {
+		auth.Use(middleware.RateLimitMiddleware(rateLimitStore, "auth", rateLimit(cfg.RateLimit.Auth)))
+	}
+	{
+		auth.POST("/signup", authHandler.SignUp)
+		auth.POST("/signin", authHandler.SignIn)
+	}
 
-	router.POST("/signup", authHandler.SignUp)
-	router.POST("/signin", authHandler.SignIn)
+	shared := router.Group("/shared")
+	if cfg.RateLimit.Enabled {
+		shared.Use(middleware.RateLimitMiddleware(rateLimitStore, "shared", rateLimit(cfg.RateLimit.Shared)))
+	}
+	{
+		shared.GET("/:token", shareLinkHandler.View)
+	}
 
 	v1Api := router.Group("/api/v1")
 	v1Api.Use(middleware.AuthMiddleware(tokenService))
+	if cfg.RateLimit.Enabled {
+		v1Api.Use(middleware.RateLimitMiddleware(rateLimitStore, "api", rateLimit(cfg.RateLimit.API)))
+	}
+	v1Api.Use(middleware.IdempotencyMiddleware(idempotencyRepository, cfg.Idempotency.TTL))
 	{
 		customers := v1Api.Group("/customers")
 		{
 			customers.POST("", customerHandler.Create)
+			customers.POST("/import", middleware.RequireRole(model.RoleAdmin), customerImportHandler.Import)
 			customers.GET("/:customer_id", customerHandler.GetByID)
 			customers.GET("", customerHandler.GetAll)
+			customers.GET("/deleted", middleware.RequireRole(model.RoleAdmin), customerHandler.GetDeleted)
+			customers.GET("/search", customerHandler.Search)
 			customers.PUT("/:customer_id", customerHandler.Update)
+			customers.PATCH("/:customer_id", customerHandler.Patch)
 			customers.DELETE("/:customer_id", customerHandler.Delete)
+			customers.POST("/:customer_id/restore", customerHandler.Restore)
+			customers.GET("/:customer_id/data-export", middleware.RequireRole(model.RoleAdmin), customerPrivacyHandler.DataExport)
+			customers.POST("/:customer_id/erase", middleware.RequireRole(model.RoleAdmin), customerPrivacyHandler.Erase)
+			customers.GET("/:customer_id/transitions", middleware.RequireRole(model.RoleAdmin), customerStateHandler.GetTransitions)
+			customers.POST("/:customer_id/transitions", middleware.RequireRole(model.RoleAdmin), customerStateHandler.Transition)
+			customers.GET("/:customer_id/tags", customerTagHandler.GetByCustomerID)
+			customers.PUT("/:customer_id/tags/:tag", customerTagHandler.Add)
+			customers.DELETE("/:customer_id/tags/:tag", customerTagHandler.Remove)
 
 			favorites := customers.Group("/:customer_id/favorites")
+			if cfg.RateLimit.Enabled {
+				favorites.Use(middleware.RateLimitMiddleware(rateLimitStore, "favorites", rateLimit(cfg.RateLimit.Favorites)))
+			}
 			{
 				favorites.POST("", favoriteHandler.AddFavorite)
 				favorites.GET("", favoriteHandler.GetCustomerFavoriteProducts)
+				favorites.PUT("/order", favoriteHandler.Reorder)
+				favorites.PATCH("/:product_id", favoriteHandler.UpdateFavorite)
 				favorites.DELETE("/:product_id", favoriteHandler.RemoveFavorite)
+				favorites.GET("/changes", favoriteHandler.GetChanges)
+				favorites.GET("/stream", favoriteStreamHandler.Stream)
+			}
+
+			// Custom methods such as favorites:batch follow the resource
+			// after a colon, routed as a parameter.
+			favoriteMethods := customers.Group("")
+			if cfg.RateLimit.Enabled {
+				favoriteMethods.Use(middleware.RateLimitMiddleware(rateLimitStore, "favorites", rateLimit(cfg.RateLimit.Favorites)))
 			}
+			{
+				favoriteMethods.POST("/:customer_id/favorites:method", favoriteHandler.Batch)
+			}
+
+			collections := customers.Group("/:customer_id/collections")
+			if cfg.RateLimit.Enabled {
+				collections.Use(middleware.RateLimitMiddleware(rateLimitStore, "favorites", rateLimit(cfg.RateLimit.Favorites)))
+			}
+			{
+				collections.POST("", favoriteCollectionHandler.Create)
+				collections.GET("", favoriteCollectionHandler.GetAll)
+				collections.PUT("/order", favoriteCollectionHandler.Reorder)
+				collections.GET("/:collection_id", favoriteCollectionHandler.GetByID)
+				collections.PUT("/:collection_id", favoriteCollectionHandler.Update)
+				collections.DELETE("/:collection_id", favoriteCollectionHandler.Delete)
+				collections.GET("/:collection_id/favorites", favoriteCollectionHandler.GetProducts)
+				collections.PUT("/:collection_id/favorites/:product_id", favoriteCollectionHandler.AddFavorite)
+				collections.DELETE("/:collection_id/favorites/:product_id", favoriteCollectionHandler.RemoveFavorite)
+			}
+
+			customers.POST("/:customer_id/share-links", shareLinkHandler.Create)
+			customers.GET("/:customer_id/share-links", shareLinkHandler.GetAll)
+			customers.DELETE("/:customer_id/share-links/:share_link_id", shareLinkHandler.Revoke)
+
+			customers.GET("/:customer_id/price-alerts", priceAlertHandler.GetAlerts)
+			customers.GET("/:customer_id/price-alerts/settings", priceAlertHandler.GetSettings)
+			customers.PUT("/:customer_id/price-alerts/settings", priceAlertHandler.UpdateSettings)
 		}
+
+		v1Api.GET("/products/:product_id/prices", priceAlertHandler.GetPriceHistory)
+
+		v1Api.GET("/tags", customerTagHandler.GetAll)
+
+		segments := v1Api.Group("/segments")
+		{
+			segments.POST("", customerSegmentHandler.Create)
+			segments.GET("", customerSegmentHandler.GetAll)
+			segments.POST("/preview", customerSegmentHandler.Preview)
+			segments.GET("/:segment_id", customerSegmentHandler.GetByID)
+			segments.PUT("/:segment_id", customerSegmentHandler.Update)
+			segments.DELETE("/:segment_id", customerSegmentHandler.Delete)
+			segments.GET("/:segment_id/customers", customerSegmentHandler.Members)
+		}
+
+		customerAttributes := v1Api.Group("/customer-attributes")
+		{
+			customerAttributes.POST("", middleware.RequireRole(model.RoleAdmin), customerAttributeHandler.Create)
+			customerAttributes.GET("", customerAttributeHandler.GetAll)
+			customerAttributes.DELETE("/:name", middleware.RequireRole(model.RoleAdmin), customerAttributeHandler.Delete)
+		}
+
+		v1Api.GET("/imports/:import_id", middleware.RequireRole(model.RoleAdmin), customerImportHandler.GetByID)
+		v1Api.GET("/exports/customers", middleware.RequireRole(model.RoleAdmin), customerExportHandler.ExportCustomers)
+		v1Api.GET("/erasures/verify", middleware.RequireRole(model.RoleAdmin), customerPrivacyHandler.VerifyErasures)
+
+		webhooks := v1Api.Group("/webhooks", middleware.RequireRole(model.RoleAdmin))
+		{
+			webhooks.POST("", webhookHandler.Create)
+			webhooks.GET("", webhookHandler.GetAll)
+			webhooks.GET("/:webhook_id", webhookHandler.GetByID)
+			webhooks.PUT("/:webhook_id", webhookHandler.Update)
+			webhooks.DELETE("/:webhook_id", webhookHandler.Delete)
+			webhooks.GET("/:webhook_id/deliveries", webhookHandler.Deliveries)
+			webhooks.GET("/:webhook_id/deliveries/:delivery_id", webhookHandler.GetDelivery)
+			webhooks.POST("/:webhook_id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+		}
+
+		analytics := v1Api.Group("/analytics/favorites", middleware.RequireRole(model.RoleAdmin))
+		{
+			analytics.GET("/popular", favoriteAnalyticsHandler.GetPopular)
+			analytics.GET("/activity", favoriteAnalyticsHandler.GetActivity)
+			analytics.GET("/summary", favoriteAnalyticsHandler.GetSummary)
+			analytics.GET("/products", favoriteAnalyticsHandler.GetProductCounts)
+		}
+	}
+
+	workers.Start(ctx)
+
+	// Stopping the hub on shutdown ends the open streams, which would
+	// otherwise hold the server until the shutdown timeout.
+	hubDone := make(chan struct{})
+	go func() {
+		defer close(hubDone)
+		if err := favoriteChangeHub.Run(ctx); err != nil {
+			log.Println("Failed to listen for favorite changes:", err)
+		}
+	}()
+
+	runErr := srv.Run(ctx)
+	if runErr != nil {
+		log.Println("Failed to run server:", runErr)
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := workers.Stop(stopCtx); err != nil {
+		log.Println("Failed to stop background workers:", err)
+	}
+	<-hubDone
+
+	if err := database.Close(); err != nil {
+		log.Println("Failed to close database:", err)
+	}
+
+	if runErr != nil {
+		os.Exit(1)
+	}
+	log.Println("Server stopped")
+}
+
+// loadConfig loads and validates the configuration, exiting with a report
+// of every problem found.
+func loadConfig(args []string) *config.Config {
+	loaded, err := config.Load("app", args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
 
-	if err := router.Run(":3002"); err != nil {
-		log.Fatal("Failed to start server:", err)
+	if err := loaded.Config.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
+
+	return loaded.Config
+}
+
+func printConfig(args []string) {
+	loaded, err := config.Load("app config print", args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if err := loaded.Print(os.Stdout); err != nil {
+		log.Fatal("Failed to print config:", err)
+	}
+
+	if err := loaded.Config.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// setUserRole grants a role to an existing user, as signing up only creates
+// regular users. Arguments are the username and the role, followed by the
+// config flags.
+func setUserRole(args []string) {
+	if len(args) < 2 || (args[1] != model.RoleUser && args[1] != model.RoleAdmin) {
+		fmt.Fprintln(os.Stderr, "usage: app users set-role <username> <admin|user> [flags]")
+		os.Exit(2)
+	}
+	username, role := args[0], args[1]
+
+	loaded, err := config.Load("app users set-role", args[2:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	database := db.Connect(loaded.Config.Database)
+	defer database.Close()
+
+	if err := db.Migrate(context.Background(), database); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+
+	found, err := db.NewUserRepository(database).SetRole(username, role)
+	if err != nil {
+		log.Fatal("Failed to set user role:", err)
+	}
+	if !found {
+		fmt.Fprintf(os.Stderr, "user %q not found\n", username)
+		os.Exit(1)
+	}
+
+	fmt.Printf("User %s now has the %s role\n", username, role)
+}
+
+// newCustomerSearchIndex uses trigram search in the database when available,
+// falling back to ranking in memory.
+func newCustomerSearchIndex(c context.Context, cfg config.SearchConfig, database *sql.DB, customerRepository *db.CustomerRepository) domainservice.CustomerSearchIndex {
+	backend := cfg.Backend
+	if backend == "auto" {
+		available, err := db.TrigramSearchAvailable(c, database)
+		if err != nil {
+			log.Println("Error checking trigram search support", err)
+		}
+		backend = "memory"
+		if available {
+			backend = "postgres"
+		}
+	}
+
+	log.Println("Customer search backend:", backend)
+	if backend == "postgres" {
+		return db.NewCustomerSearchRepository(database, cfg.SimilarityThreshold)
+	}
+	findAll := func(c context.Context) ([]model.Customer, error) {
+		return customerRepository.FindAll(c, "")
+	}
+	return search.NewCustomerMemoryIndex(findAll, cfg.SimilarityThreshold)
+}
+
+func newEventSinks(cfg config.OutboxConfig) ([]domainservice.EventSink, error) {
+	var sinks []domainservice.EventSink
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, events.NewLogSink())
+		case "file":
+			sink, err := events.NewFileSink(cfg.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		case "webhook":
+			sinks = append(sinks, events.NewWebhookSink(cfg.WebhookURL, cfg.WebhookTimeout))
+		}
+	}
+	return sinks, nil
+}
+
+func newPriceAlertNotifiers(cfg config.PriceAlertsConfig, outboxRepository *db.OutboxRepository) []domainservice.PriceAlertNotifier {
+	var notifiers []domainservice.PriceAlertNotifier
+	for _, name := range cfg.Notifiers {
+		switch name {
+		case "log":
+			notifiers = append(notifiers, notify.NewLogNotifier())
+		case "event":
+			notifiers = append(notifiers, notify.NewEventNotifier(outboxRepository))
+		}
+	}
+	return notifiers
+}
+
+func rateLimit(cfg config.RateLimitGroupConfig) ratelimit.Limit {
+	return ratelimit.Limit{Requests: cfg.Requests, Window: cfg.Window, Burst: cfg.Burst}
 }
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/domain/service"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type CustomerAttributeHandler struct {
+	service *service.CustomerAttributeService
+}
+
+type CustomerAttributeRequest struct {
+	Name        string `json:"name" validate:"required" example:"loyalty_tier"`
+	Type        string `json:"type" validate:"required" example:"string" enums:"string,number,integer,boolean"`
+	Required    bool   `json:"required" example:"false"`
+	Enum        []any  `json:"enum,omitempty" swaggertype:"array,string" example:"bronze,silver,gold"`
+	Description string `json:"description,omitempty" example:"Loyalty program tier"`
+}
+
+type CustomerAttributeResponse struct {
+	Name        string `json:"name" example:"loyalty_tier"`
+	Type        string `json:"type" example:"string"`
+	Required    bool   `json:"required" example:"false"`
+	Enum        []any  `json:"enum,omitempty" swaggertype:"array,string" example:"bronze,silver,gold"`
+	Description string `json:"description,omitempty" example:"Loyalty program tier"`
+	CreatedAt   string `json:"created_at" example:"2025-06-19T12:00:00Z"`
+}
+
+func NewCustomerAttributeHandler(service *service.CustomerAttributeService) *CustomerAttributeHandler {
+	return &CustomerAttributeHandler{service: service}
+}
+
+// @Summary Register customer attribute
+// @Description Registers an attribute customers can have. Writes to customer attributes are validated against the registered definitions. Requires the admin role.
+// @Tags Customer Attribute
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param attribute body CustomerAttributeRequest true "Attribute definition"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 201 {object} CustomerAttributeResponse "Registered attribute"
+// @Failure 400 {object} ErrorResponse "Invalid attribute definition"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 409 {object} ErrorResponse "Attribute already exists"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customer-attributes [post]
+func (h *CustomerAttributeHandler) Create(c *gin.Context) {
+	var request CustomerAttributeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Error binding request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		log.Println("Error validating request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	definition, err := h.service.Create(c.Request.Context(), model.CustomerAttributeDefinition{
+		Name:        request.Name,
+		Type:        request.Type,
+		Required:    request.Required,
+		Enum:        request.Enum,
+		Description: request.Description,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAttributes) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		if err == domain.ErrAttributeAlreadyExists {
+			c.JSON(http.StatusConflict, gin.H{"message": "Attribute already exists"})
+			return
+		}
+		log.Println("Error creating customer attribute", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create attribute"})
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path+"/"+definition.Name)
+	c.JSON(http.StatusCreated, definition)
+}
+
+// @Summary Get customer attributes
+// @Description Retrieves the registered customer attributes
+// @Tags Customer Attribute
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} CustomerAttributeResponse "List of attributes"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customer-attributes [get]
+func (h *CustomerAttributeHandler) GetAll(c *gin.Context) {
+	definitions, err := h.service.GetAll(c.Request.Context())
+	if err != nil {
+		log.Println("Error fetching customer attributes", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch attributes"})
+		return
+	}
+
+	if definitions == nil {
+		definitions = []model.CustomerAttributeDefinition{}
+	}
+
+	c.JSON(http.StatusOK, definitions)
+}
+
+// @Summary Delete customer attribute
+// @Description Deletes an attribute definition and removes its values from every customer. Requires the admin role.
+// @Tags Customer Attribute
+// @Security BearerAuth
+// @Param name path string true "Attribute name" example="loyalty_tier"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 204 "No content"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} ErrorResponse "Attribute not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customer-attributes/{name} [delete]
+func (h *CustomerAttributeHandler) Delete(c *gin.Context) {
+	err := h.service.Delete(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Attribute not found"})
+			return
+		}
+		log.Println("Error deleting customer attribute", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete attribute"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
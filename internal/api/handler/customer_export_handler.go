@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"app/internal/domain/model"
+	"app/internal/domain/service"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportFlushEvery is the number of records written between flushes of the
+// response.
+const exportFlushEvery = 100
+
+// customerExportFields maps the selectable fields of an export to their
+// values, in their default order.
+var customerExportFields = []customerExportColumn{
+	{"id", func(e model.CustomerExport) any { return e.ID }},
+	{"name", func(e model.CustomerExport) any { return e.Name }},
+	{"email", func(e model.CustomerExport) any { return e.Email }},
+	{"attributes", func(e model.CustomerExport) any { return e.Attributes }},
+	{"state", func(e model.CustomerExport) any { return string(e.State) }},
+	{"version", func(e model.CustomerExport) any { return e.Version }},
+	{"created_at", func(e model.CustomerExport) any { return e.CreatedAt }},
+	{"updated_at", func(e model.CustomerExport) any { return e.UpdatedAt }},
+}
+
+type CustomerExportHandler struct {
+	service *service.CustomerExportService
+}
+
+type CustomerExportResponse struct {
+	ID                 string         `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name               string         `json:"name" example:"Frodo Baggins"`
+	Email              string         `json:"email" example:"frodo.baggins@example.com"`
+	Attributes         map[string]any `json:"attributes"`
+	State              string         `json:"state" example:"active"`
+	Version            int            `json:"version" example:"1"`
+	CreatedAt          string         `json:"created_at" example:"2025-06-19T12:00:00Z"`
+	UpdatedAt          string         `json:"updated_at" example:"2025-06-19T12:00:00Z"`
+	FavoriteProductIDs []int          `json:"favorite_product_ids,omitempty" example:"1,2"`
+	FavoriteProducts   []Product      `json:"favorite_products,omitempty"`
+}
+
+type customerExportColumn struct {
+	name  string
+	value func(model.CustomerExport) any
+}
+
+func NewCustomerExportHandler(service *service.CustomerExportService) *CustomerExportHandler {
+	return &CustomerExportHandler{service: service}
+}
+
+// @Summary Export customers
+// @Description Streams the active customers, oldest first, as NDJSON (one object per line) or CSV. Customers can be limited to a creation date range, fields can be selected, and favorites can be inlined as product IDs or as snapshots of the products from the catalog. Requires the admin role.
+// @Tags Customer
+// @Produce application/x-ndjson,text/csv
+// @Security BearerAuth
+// @Param format query string false "Output format" Enums(ndjson, csv) default(ndjson)
+// @Param fields query string false "Comma separated fields to include: id, name, email, attributes, state, version, created_at, updated_at. All by default" example(id,email)
+// @Param created_from query string false "Only customers created at or after this RFC 3339 time or date" example(2025-01-01)
+// @Param created_to query string false "Only customers created before this RFC 3339 time or date" example(2025-07-01)
+// @Param favorites query string false "Favorites to inline" Enums(none, ids, products) default(none)
+// @Success 200 {array} CustomerExportResponse "Exported customers, one per line"
+// @Failure 400 {object} ErrorResponse "Invalid export parameters"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/exports/customers [get]
+func (h *CustomerExportHandler) ExportCustomers(c *gin.Context) {
+	format := c.DefaultQuery("format", "ndjson")
+	if format != "ndjson" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid format, use ndjson or csv"})
+		return
+	}
+
+	filter := model.CustomerExportFilter{Favorites: c.DefaultQuery("favorites", model.ExportFavoritesNone)}
+	if filter.Favorites != model.ExportFavoritesNone &&
+		filter.Favorites != model.ExportFavoritesIDs &&
+		filter.Favorites != model.ExportFavoritesProducts {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid favorites, use none, ids or products"})
+		return
+	}
+
+	var ok bool
+	if filter.CreatedFrom, ok = parseExportTime(c.Query("created_from")); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid created_from, use an RFC 3339 time or a date"})
+		return
+	}
+	if filter.CreatedBefore, ok = parseExportTime(c.Query("created_to")); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid created_to, use an RFC 3339 time or a date"})
+		return
+	}
+
+	columns, ok := customerExportColumns(c.Query("fields"), filter.Favorites)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid fields"})
+		return
+	}
+
+	// Exports can outlast the server write timeout.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Println("Error extending export write deadline", err)
+	}
+
+	encode := encodeNDJSONRecord
+	contentType := "application/x-ndjson"
+	if format == "csv" {
+		encode = encodeCSVRecord
+		contentType = "text/csv"
+	}
+
+	started, written := false, 0
+	start := func() error {
+		started = true
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", `attachment; filename="customers.`+format+`"`)
+		c.Status(http.StatusOK)
+
+		if format == "csv" {
+			header := make([]string, len(columns))
+			for i, column := range columns {
+				header[i] = column.name
+			}
+			return writeCSVRow(c.Writer, header)
+		}
+		return nil
+	}
+
+	err := h.service.Export(c.Request.Context(), filter, func(export model.CustomerExport) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		record, err := encode(columns, export)
+		if err != nil {
+			return err
+		}
+		if _, err := c.Writer.Write(record); err != nil {
+			return err
+		}
+
+		written++
+		if written%exportFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println("Error exporting customers", err)
+		if !started {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to export customers"})
+		}
+		// Once streaming started the status is sent; the export ends truncated.
+		return
+	}
+
+	if !started {
+		if err := start(); err != nil {
+			log.Println("Error exporting customers", err)
+			return
+		}
+	}
+	c.Writer.Flush()
+}
+
+// customerExportColumns returns the columns named in fields, or every field
+// when it is empty, followed by the favorites column, if any.
+func customerExportColumns(fields string, favorites string) ([]customerExportColumn, bool) {
+	var columns []customerExportColumn
+	if fields == "" {
+		for _, field := range customerExportFields {
+			columns = append(columns, field)
+		}
+	} else {
+		seen := make(map[string]bool)
+		for _, name := range strings.Split(fields, ",") {
+			name = strings.TrimSpace(name)
+			found := false
+			for _, field := range customerExportFields {
+				if field.name == name {
+					found = true
+					if !seen[name] {
+						columns = append(columns, field)
+					}
+					seen[name] = true
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		}
+	}
+
+	switch favorites {
+	case model.ExportFavoritesIDs:
+		columns = append(columns, customerExportColumn{"favorite_product_ids", func(e model.CustomerExport) any { return e.FavoriteProductIDs }})
+	case model.ExportFavoritesProducts:
+		columns = append(columns, customerExportColumn{"favorite_products", func(e model.CustomerExport) any { return e.FavoriteProducts }})
+	}
+
+	return columns, true
+}
+
+// parseExportTime parses an RFC 3339 time or a date, which means midnight
+// UTC. An empty value is no bound.
+func parseExportTime(value string) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		parsed, err = time.Parse(time.DateOnly, value)
+	}
+	if err != nil {
+		return nil, false
+	}
+
+	parsed = parsed.UTC()
+	return &parsed, true
+}
+
+// encodeNDJSONRecord writes the columns as one JSON object per line, keeping
+// the column order.
+func encodeNDJSONRecord(columns []customerExportColumn, export model.CustomerExport) ([]byte, error) {
+	var record bytes.Buffer
+	record.WriteByte('{')
+	for i, column := range columns {
+		if i > 0 {
+			record.WriteByte(',')
+		}
+
+		value := column.value(export)
+		if ids, ok := value.([]int); ok && ids == nil {
+			value = []int{}
+		}
+		if products, ok := value.([]model.Product); ok && products == nil {
+			value = []model.Product{}
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		record.WriteString(strconv.Quote(column.name))
+		record.WriteByte(':')
+		record.Write(encoded)
+	}
+	record.WriteString("}\n")
+	return record.Bytes(), nil
+}
+
+// encodeCSVRecord writes the columns as one CSV row. Favorite product IDs are
+// separated by semicolons and product snapshots are JSON encoded.
+func encodeCSVRecord(columns []customerExportColumn, export model.CustomerExport) ([]byte, error) {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		switch value := column.value(export).(type) {
+		case string:
+			values[i] = value
+		case int:
+			values[i] = strconv.Itoa(value)
+		case time.Time:
+			values[i] = value.Format(time.RFC3339Nano)
+		case []int:
+			ids := make([]string, len(value))
+			for j, id := range value {
+				ids[j] = strconv.Itoa(id)
+			}
+			values[i] = strings.Join(ids, ";")
+		default:
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = string(encoded)
+		}
+	}
+
+	var record bytes.Buffer
+	if err := writeCSVRow(&record, values); err != nil {
+		return nil, err
+	}
+	return record.Bytes(), nil
+}
+
+func writeCSVRow(w io.Writer, values []string) error {
+	writer := csv.NewWriter(w)
+	writer.Write(values)
+	writer.Flush()
+	return writer.Error()
+}
@@ -2,9 +2,17 @@ package handler
 
 import (
 	"app/internal/domain"
+	"app/internal/domain/model"
 	"app/internal/domain/service"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -12,43 +20,78 @@ import (
 )
 
 type CustomerHandler struct {
-	service *service.CustomerService
+	service           *service.CustomerService
+	maxSearchPageSize int
 }
 
 type CustomerCreateRequest struct {
-	Name  string `json:"name" validate:"required,min=3" example:"Frodo Baggins"`
-	Email string `json:"email" validate:"required,email" example:"frodo.baggins@example.com"`
+	Name       string         `json:"name" validate:"required,min=3" example:"Frodo Baggins"`
+	Email      string         `json:"email" validate:"required,email" example:"frodo.baggins@example.com"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+	// State is the initial state, active when omitted.
+	State string `json:"state,omitempty" validate:"omitempty,oneof=pending active" example:"active"`
 }
 
 type CustomerUpdateRequest struct {
 	Name  string `json:"name" validate:"required,min=3" example:"Frodo Baggins"`
 	Email string `json:"email" validate:"required,email" example:"frodo.baggins@example.com"`
+	// Attributes replace all current attributes. When omitted they are kept.
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+type CustomerPatchRequest struct {
+	Name       *string        `json:"name,omitempty" example:"Frodo Baggins"`
+	Email      *string        `json:"email,omitempty" example:"frodo.baggins@example.com"`
+	Attributes map[string]any `json:"attributes,omitempty"`
 }
 
 type CustomerResponse struct {
 	ID    string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
 	Name  string `json:"name" example:"Frodo Baggins"`
 	Email string `json:"email" example:"frodo.baggins@example.com"`
+	// Attributes holds the values of the registered customer attributes.
+	Attributes map[string]any `json:"attributes"`
+	State      string         `json:"state" example:"active" enums:"pending,active,suspended,closed"`
+	Version    int            `json:"version" example:"1"`
+	CreatedAt  string         `json:"created_at" example:"2025-06-19T12:00:00Z"`
+	UpdatedAt  string         `json:"updated_at" example:"2025-06-19T12:00:00Z"`
+	DeletedAt  string         `json:"deleted_at,omitempty" example:"2025-06-19T12:00:00Z"`
 }
 
 type ErrorResponse struct {
 	Message string `json:"message" example:"Error message"`
 }
 
-func NewCustomerHandler(service *service.CustomerService) *CustomerHandler {
-	return &CustomerHandler{service: service}
+type CustomerMatchResponse struct {
+	CustomerResponse
+	Score float64 `json:"score" example:"0.82"`
+}
+
+type CustomerSearchResponse struct {
+	Items    []CustomerMatchResponse `json:"items"`
+	Total    int                     `json:"total" example:"42"`
+	Page     int                     `json:"page" example:"1"`
+	PageSize int                     `json:"page_size" example:"20"`
+}
+
+func NewCustomerHandler(service *service.CustomerService, maxSearchPageSize int) *CustomerHandler {
+	return &CustomerHandler{service: service, maxSearchPageSize: maxSearchPageSize}
 }
 
 // @Summary Create a new customer
-// @Description Creates a new customer with the provided details
+// @Description Creates a new customer with the provided details. New customers are active unless created as pending.
 // @Tags Customer
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param customer body CustomerCreateRequest true "Customer details"
-// @Success 201 "Created customer details"
-// @Failure 400 {object} ErrorResponse "Invalid request data"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 201 {object} CustomerResponse "Created customer details"
+// @Header 201 {string} Location "URL of the created customer"
+// @Header 201 {string} ETag "Version of the created customer"
+// @Failure 400 {object} ErrorResponse "Invalid request data or attributes"
 // @Failure 409 {object} ErrorResponse "Email already exists"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/v1/customers [post]
 func (h *CustomerHandler) Create(c *gin.Context) {
@@ -59,24 +102,38 @@ func (h *CustomerHandler) Create(c *gin.Context) {
 		return
 	}
 
+	customerCreateRequest.Email = strings.TrimSpace(customerCreateRequest.Email)
+
 	if err := validator.New().Struct(customerCreateRequest); err != nil {
 		log.Println("Error validating request data", err)
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
 		return
 	}
 
-	_, err := h.service.Create(c.Request.Context(), customerCreateRequest.Name, customerCreateRequest.Email)
+	customer, err := h.service.Create(
+		c.Request.Context(),
+		customerCreateRequest.Name,
+		customerCreateRequest.Email,
+		customerCreateRequest.Attributes,
+		model.CustomerState(customerCreateRequest.State),
+	)
 	if err != nil {
 		if err == domain.ErrEmailAlreadyExists {
 			c.JSON(http.StatusConflict, gin.H{"message": "Email already exists"})
 			return
 		}
+		if errors.Is(err, domain.ErrInvalidAttributes) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
 		log.Println("Error creating customer", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create customer"})
 		return
 	}
 
-	c.Status(http.StatusCreated)
+	c.Header("Location", c.Request.URL.Path+"/"+customer.ID)
+	c.Header("ETag", customerETag(customer))
+	c.JSON(http.StatusCreated, customer)
 }
 
 // @Summary Get customer by ID
@@ -86,6 +143,7 @@ func (h *CustomerHandler) Create(c *gin.Context) {
 // @Security BearerAuth
 // @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
 // @Success 200 {object} CustomerResponse "Customer details"
+// @Header 200 {string} ETag "Version of the customer"
 // @Failure 400 {object} ErrorResponse "Invalid customer ID format"
 // @Failure 404 {object} ErrorResponse "Customer not found"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -109,20 +167,34 @@ func (h *CustomerHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", customerETag(customer))
 	c.JSON(http.StatusOK, customer)
 }
 
 // @Summary Get all customers
-// @Description Retrieves a list of all customers
+// @Description Retrieves a list of all customers. Customers can be filtered by state and by attribute values with attributes[name]=value parameters, all of which must match.
 // @Tags Customer
 // @Produce json
 // @Security BearerAuth
+// @Param attributes query string false "Attribute filter, given as attributes[name]=value" example(attributes[loyalty_tier]=gold)
+// @Param state query string false "Only customers in this state" Enums(pending, active, suspended, closed)
 // @Success 200 {array} CustomerResponse "List of customers"
+// @Failure 400 {object} ErrorResponse "Invalid attribute filter or state"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/v1/customers [get]
 func (h *CustomerHandler) GetAll(c *gin.Context) {
-	customers, err := h.service.GetAll(c.Request.Context())
+	state := model.CustomerState(c.Query("state"))
+	if state != "" && !state.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid state"})
+		return
+	}
+
+	customers, err := h.service.GetAll(c.Request.Context(), c.QueryMap("attributes"), state)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAttributes) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
 		log.Println("Error fetching customers", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch customers"})
 		return
@@ -131,6 +203,41 @@ func (h *CustomerHandler) GetAll(c *gin.Context) {
 	c.JSON(http.StatusOK, customers)
 }
 
+// @Summary Search customers
+// @Description Finds customers by name or email, ignoring case and accents and tolerating typos. Best matches come first.
+// @Tags Customer
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text, at least 2 characters" example="frodo"
+// @Param page query int false "Page number, starting at 1" example=1
+// @Param page_size query int false "Results per page" example=20
+// @Success 200 {object} CustomerSearchResponse "Matching customers"
+// @Failure 400 {object} ErrorResponse "Invalid search parameters"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/search [get]
+func (h *CustomerHandler) Search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if len([]rune(query)) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Search text must have at least 2 characters"})
+		return
+	}
+
+	pageRequest, ok := parsePageRequest(c, h.maxSearchPageSize)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid pagination parameters"})
+		return
+	}
+
+	result, err := h.service.Search(c.Request.Context(), query, pageRequest.Page, pageRequest.PageSize)
+	if err != nil {
+		log.Println("Error searching customers", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to search customers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // @Summary Update customer
 // @Description Updates an existing customer's details
 // @Tags Customer
@@ -139,10 +246,15 @@ func (h *CustomerHandler) GetAll(c *gin.Context) {
 // @Security BearerAuth
 // @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
 // @Param customer body CustomerUpdateRequest true "Updated customer details"
+// @Param If-Match header string false "ETag the customer must still have"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
 // @Success 204 "No content"
+// @Header 204 {string} ETag "New version of the customer"
 // @Failure 400 {object} ErrorResponse "Invalid request data or customer ID"
 // @Failure 404 {object} ErrorResponse "Customer not found"
 // @Failure 409 {object} ErrorResponse "Email already in use"
+// @Failure 412 {object} ErrorResponse "Customer was modified"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/v1/customers/{customer_id} [put]
 func (h *CustomerHandler) Update(c *gin.Context) {
@@ -152,6 +264,12 @@ func (h *CustomerHandler) Update(c *gin.Context) {
 		return
 	}
 
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid If-Match header"})
+		return
+	}
+
 	var customerUpdateRequest CustomerUpdateRequest
 	if err := c.ShouldBindJSON(&customerUpdateRequest); err != nil {
 		log.Println("Error binding request data", err)
@@ -159,38 +277,163 @@ func (h *CustomerHandler) Update(c *gin.Context) {
 		return
 	}
 
+	customerUpdateRequest.Email = strings.TrimSpace(customerUpdateRequest.Email)
+
 	if err := validator.New().Struct(customerUpdateRequest); err != nil {
 		log.Println("Error validating request data", err)
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
 		return
 	}
 
-	err := h.service.Update(c.Request.Context(), customerID, customerUpdateRequest.Name, customerUpdateRequest.Email)
+	customer, err := h.service.Update(
+		c.Request.Context(),
+		customerID,
+		customerUpdateRequest.Name,
+		customerUpdateRequest.Email,
+		customerUpdateRequest.Attributes,
+		expectedVersion,
+	)
 	if err != nil {
-		if err == domain.ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
-			return
-		}
-		if err == domain.ErrEmailAlreadyExists {
-			c.JSON(http.StatusConflict, gin.H{"message": "Email already exists"})
-			return
-		}
-		log.Println("Error updating customer", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update customer"})
+		h.handleUpdateError(c, err)
 		return
 	}
 
+	c.Header("ETag", customerETag(customer))
 	c.Status(http.StatusNoContent)
 }
 
+// @Summary Partially update customer
+// @Description Applies a JSON Merge Patch (RFC 7396) to the customer's name, email and attributes
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param customer body CustomerPatchRequest true "Fields to change"
+// @Param If-Match header string false "ETag the customer must still have"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {object} CustomerResponse "Updated customer details"
+// @Header 200 {string} ETag "New version of the customer"
+// @Failure 400 {object} ErrorResponse "Invalid request data or customer ID"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 409 {object} ErrorResponse "Email already in use"
+// @Failure 412 {object} ErrorResponse "Customer was modified"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id} [patch]
+func (h *CustomerHandler) Patch(c *gin.Context) {
+	customerID := c.Param("customer_id")
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid If-Match header"})
+		return
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		log.Println("Error reading request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	// The patch is applied to the customer as stored when it is written, so
+	// it never overwrites a concurrent change.
+	updatedCustomer, err := h.service.Modify(c.Request.Context(), customerID, expectedVersion, func(customer *model.Customer) error {
+		return applyCustomerPatch(customer, patch)
+	})
+	if err != nil {
+		h.handleUpdateError(c, err)
+		return
+	}
+
+	c.Header("ETag", customerETag(updatedCustomer))
+	c.JSON(http.StatusOK, updatedCustomer)
+}
+
+// errInvalidCustomerPatch is returned by applyCustomerPatch when the patch
+// does not apply or yields an invalid customer.
+var errInvalidCustomerPatch = errors.New("invalid customer patch")
+
+// applyCustomerPatch applies a JSON merge patch to the customer's name,
+// email and attributes.
+func applyCustomerPatch(customer *model.Customer, patch []byte) error {
+	current, err := json.Marshal(CustomerUpdateRequest{
+		Name:       customer.Name,
+		Email:      customer.Email,
+		Attributes: customer.Attributes,
+	})
+	if err != nil {
+		return err
+	}
+
+	patched, err := applyMergePatch(current, patch)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidCustomerPatch, err)
+	}
+
+	var customerUpdateRequest CustomerUpdateRequest
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&customerUpdateRequest); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidCustomerPatch, err)
+	}
+
+	customerUpdateRequest.Email = strings.TrimSpace(customerUpdateRequest.Email)
+
+	if err := validator.New().Struct(customerUpdateRequest); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidCustomerPatch, err)
+	}
+
+	customer.Name = customerUpdateRequest.Name
+	customer.Email = customerUpdateRequest.Email
+	// Removing every attribute leaves none, rather than keeping them.
+	customer.Attributes = customerUpdateRequest.Attributes
+	if customer.Attributes == nil {
+		customer.Attributes = map[string]any{}
+	}
+	return nil
+}
+
+func (h *CustomerHandler) handleUpdateError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrInvalidAttributes) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if errors.Is(err, errInvalidCustomerPatch) {
+		log.Println("Error applying customer patch", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	switch err {
+	case domain.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+	case domain.ErrEmailAlreadyExists:
+		c.JSON(http.StatusConflict, gin.H{"message": "Email already exists"})
+	case domain.ErrVersionConflict:
+		c.JSON(http.StatusPreconditionFailed, gin.H{"message": "Customer was modified"})
+	default:
+		log.Println("Error updating customer", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update customer"})
+	}
+}
+
 // @Summary Delete customer
-// @Description Deletes a customer by their ID
+// @Description Soft deletes a customer by their ID. The customer can be restored during the grace period, after which it is purged with its favorites.
 // @Tags Customer
 // @Security BearerAuth
 // @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
 // @Success 204 "No content"
 // @Failure 400 {object} ErrorResponse "Invalid customer ID format"
 // @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/v1/customers/{customer_id} [delete]
 func (h *CustomerHandler) Delete(c *gin.Context) {
@@ -213,3 +456,91 @@ func (h *CustomerHandler) Delete(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+func customerETag(customer *model.Customer) string {
+	return `"` + strconv.Itoa(customer.Version) + `"`
+}
+
+// ifMatchVersion returns the customer version required by the If-Match
+// header, or 0 when there is no precondition. It returns false when the
+// header is not a single ETag or "*".
+func ifMatchVersion(c *gin.Context) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// @Summary Restore customer
+// @Description Restores a deleted customer during the grace period
+// @Tags Customer
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {object} CustomerResponse "Restored customer details"
+// @Failure 400 {object} ErrorResponse "Invalid customer ID format"
+// @Failure 404 {object} ErrorResponse "Deleted customer not found"
+// @Failure 409 {object} ErrorResponse "Email already in use"
+// @Failure 410 {object} ErrorResponse "Restore period has expired"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/restore [post]
+func (h *CustomerHandler) Restore(c *gin.Context) {
+	customerID := c.Param("customer_id")
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
+		return
+	}
+
+	customer, err := h.service.Restore(c.Request.Context(), customerID)
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Deleted customer not found"})
+		case domain.ErrEmailAlreadyExists:
+			c.JSON(http.StatusConflict, gin.H{"message": "Email already exists"})
+		case domain.ErrRestoreExpired:
+			c.JSON(http.StatusGone, gin.H{"message": "Restore period has expired"})
+		default:
+			log.Println("Error restoring customer", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to restore customer"})
+		}
+		return
+	}
+
+	c.Header("ETag", customerETag(customer))
+	c.JSON(http.StatusOK, customer)
+}
+
+// @Summary Get deleted customers
+// @Description Retrieves the soft deleted customers that were not purged yet. Requires the admin role.
+// @Tags Customer
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} CustomerResponse "List of deleted customers"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/deleted [get]
+func (h *CustomerHandler) GetDeleted(c *gin.Context) {
+	customers, err := h.service.GetDeleted(c.Request.Context())
+	if err != nil {
+		log.Println("Error fetching deleted customers", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch deleted customers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, customers)
+}
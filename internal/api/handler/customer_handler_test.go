@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"app/internal/domain/model"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestApplyCustomerPatch(t *testing.T) {
+	stored := func() *model.Customer {
+		return &model.Customer{
+			ID:         "550e8400-e29b-41d4-a716-446655440000",
+			Name:       "Frodo Baggins",
+			Email:      "frodo@example.com",
+			Attributes: map[string]any{"plan": "gold", "score": 3.0},
+			Version:    4,
+		}
+	}
+
+	tests := []struct {
+		name           string
+		patch          string
+		wantName       string
+		wantEmail      string
+		wantAttributes map[string]any
+	}{
+		{
+			name:           "name only",
+			patch:          `{"name":"Samwise Gamgee"}`,
+			wantName:       "Samwise Gamgee",
+			wantEmail:      "frodo@example.com",
+			wantAttributes: map[string]any{"plan": "gold", "score": 3.0},
+		},
+		{
+			name:           "trimmed email",
+			patch:          `{"email":"  sam@example.com "}`,
+			wantName:       "Frodo Baggins",
+			wantEmail:      "sam@example.com",
+			wantAttributes: map[string]any{"plan": "gold", "score": 3.0},
+		},
+		{
+			name:           "attributes are merged",
+			patch:          `{"attributes":{"plan":null,"newsletter":true}}`,
+			wantName:       "Frodo Baggins",
+			wantEmail:      "frodo@example.com",
+			wantAttributes: map[string]any{"score": 3.0, "newsletter": true},
+		},
+		{
+			name:           "removing the attributes leaves none",
+			patch:          `{"attributes":null}`,
+			wantName:       "Frodo Baggins",
+			wantEmail:      "frodo@example.com",
+			wantAttributes: map[string]any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			customer := stored()
+			if err := applyCustomerPatch(customer, []byte(tt.patch)); err != nil {
+				t.Fatalf("applyCustomerPatch(%s) returned error: %v", tt.patch, err)
+			}
+
+			if customer.Name != tt.wantName || customer.Email != tt.wantEmail {
+				t.Errorf("name, email = %q, %q, want %q, %q", customer.Name, customer.Email, tt.wantName, tt.wantEmail)
+			}
+			if !reflect.DeepEqual(customer.Attributes, tt.wantAttributes) {
+				t.Errorf("attributes = %v, want %v", customer.Attributes, tt.wantAttributes)
+			}
+			if customer.Version != 4 {
+				t.Errorf("version = %d, want 4", customer.Version)
+			}
+		})
+	}
+}
+
+func TestApplyCustomerPatchInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		{name: "not an object", patch: `["name"]`},
+		{name: "not JSON", patch: `{"name":`},
+		{name: "unknown field", patch: `{"age":3}`},
+		{name: "removed name", patch: `{"name":null}`},
+		{name: "short name", patch: `{"name":"Al"}`},
+		{name: "invalid email", patch: `{"email":"frodo"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			customer := &model.Customer{Name: "Frodo Baggins", Email: "frodo@example.com"}
+			err := applyCustomerPatch(customer, []byte(tt.patch))
+			if !errors.Is(err, errInvalidCustomerPatch) {
+				t.Errorf("applyCustomerPatch(%s) error = %v, want errInvalidCustomerPatch", tt.patch, err)
+			}
+		})
+	}
+}
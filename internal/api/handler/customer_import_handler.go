@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/domain/service"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const maxImportErrorsPageSize = 500
+
+type CustomerImportHandler struct {
+	service *service.CustomerImportService
+}
+
+type CustomerImportResponse struct {
+	ID            string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Status        string `json:"status" example:"running"`
+	Format        string `json:"format" example:"csv"`
+	DryRun        bool   `json:"dry_run" example:"false"`
+	CreatedBy     string `json:"created_by" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TotalRows     int    `json:"total_rows" example:"1000"`
+	ProcessedRows int    `json:"processed_rows" example:"500"`
+	CreatedRows   int    `json:"created_rows" example:"495"`
+	FailedRows    int    `json:"failed_rows" example:"5"`
+	Error         string `json:"error,omitempty" example:"invalid import file: CSV header must include name and email columns"`
+	CreatedAt     string `json:"created_at" example:"2025-06-19T12:00:00Z"`
+	StartedAt     string `json:"started_at,omitempty" example:"2025-06-19T12:00:01Z"`
+	FinishedAt    string `json:"finished_at,omitempty" example:"2025-06-19T12:00:09Z"`
+}
+
+type CustomerImportRowErrorResponse struct {
+	Row     int    `json:"row" example:"12"`
+	Field   string `json:"field,omitempty" example:"email"`
+	Message string `json:"message" example:"email already exists"`
+}
+
+type CustomerImportReportResponse struct {
+	CustomerImportResponse
+	Errors         []CustomerImportRowErrorResponse `json:"errors"`
+	ErrorsTotal    int                              `json:"errors_total" example:"5"`
+	ErrorsPage     int                              `json:"errors_page" example:"1"`
+	ErrorsPageSize int                              `json:"errors_page_size" example:"20"`
+}
+
+type customerImportReport struct {
+	*model.CustomerImport
+	Errors         []model.CustomerImportRowError `json:"errors"`
+	ErrorsTotal    int                            `json:"errors_total"`
+	ErrorsPage     int                            `json:"errors_page"`
+	ErrorsPageSize int                            `json:"errors_page_size"`
+}
+
+func NewCustomerImportHandler(service *service.CustomerImportService) *CustomerImportHandler {
+	return &CustomerImportHandler{service: service}
+}
+
+// ValidateCustomerCreate checks name and email with the rules of
+// CustomerCreateRequest, describing each failing field.
+func ValidateCustomerCreate(name string, email string) []model.CustomerImportRowError {
+	err := validator.New().Struct(CustomerCreateRequest{Name: name, Email: email})
+
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return nil
+	}
+
+	rowErrors := make([]model.CustomerImportRowError, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		field := strings.ToLower(fieldError.Field())
+
+		message := "is invalid"
+		switch fieldError.Tag() {
+		case "required":
+			message = "is required"
+		case "min":
+			message = "must have at least " + fieldError.Param() + " characters"
+		case "email":
+			message = "must be a valid email"
+		}
+
+		rowErrors = append(rowErrors, model.CustomerImportRowError{Field: field, Message: field + " " + message})
+	}
+	return rowErrors
+}
+
+// @Summary Import customers
+// @Description Queues a CSV or NDJSON file of customers to be created in the background. CSV files need a header with name and email columns; NDJSON files have one {"name","email"} object per line. The file is sent as the multipart field "file" or as the raw body. Each row is validated like a single customer creation, and rows that fail are reported without stopping the import. With dry_run nothing is created. Requires the admin role.
+// @Tags Customer
+// @Accept text/csv,application/x-ndjson,multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file false "File to import"
+// @Param format query string false "File format, when not given by the content type or file name" Enums(csv, ndjson)
+// @Param dry_run query bool false "Validate every row without creating customers"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 202 {object} CustomerImportResponse "Queued import"
+// @Header 202 {string} Location "URL of the import status"
+// @Failure 400 {object} ErrorResponse "Invalid import file"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 413 {object} ErrorResponse "Import file too large"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/import [post]
+func (h *CustomerImportHandler) Import(c *gin.Context) {
+	dryRun := false
+	if raw := c.Query("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid dry_run parameter"})
+			return
+		}
+		dryRun = parsed
+	}
+
+	payload, fileName, contentType, err := readImportUpload(c)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "Request body too large"})
+			return
+		}
+		log.Println("Error reading import file", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid import file"})
+		return
+	}
+
+	format := importFormat(c.Query("format"), contentType, fileName)
+	if format == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Unknown import format, use format=csv or format=ndjson"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	customerImport, err := h.service.Start(c.Request.Context(), userID.(string), format, dryRun, payload)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidImport) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		log.Println("Error starting customer import", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to start import"})
+		return
+	}
+
+	c.Header("Location", "/api/v1/imports/"+customerImport.ID)
+	c.JSON(http.StatusAccepted, customerImport)
+}
+
+// @Summary Get import status
+// @Description Retrieves the progress of a customer import with one page of its row errors. Requires the admin role.
+// @Tags Customer
+// @Produce json
+// @Security BearerAuth
+// @Param import_id path string true "Import ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param page query int false "Page of row errors, starting at 1" default(1)
+// @Param page_size query int false "Row errors per page" default(20)
+// @Success 200 {object} CustomerImportReportResponse "Import status"
+// @Failure 400 {object} ErrorResponse "Invalid import ID or pagination"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} ErrorResponse "Import not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/imports/{import_id} [get]
+func (h *CustomerImportHandler) GetByID(c *gin.Context) {
+	importID := c.Param("import_id")
+	if _, err := uuid.Parse(importID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid import ID"})
+		return
+	}
+
+	pageRequest, ok := parsePageRequest(c, maxImportErrorsPageSize)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid pagination parameters"})
+		return
+	}
+
+	customerImport, err := h.service.GetByID(c.Request.Context(), importID)
+	if err != nil {
+		log.Println("Error fetching customer import", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch import"})
+		return
+	}
+
+	if customerImport == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Import not found"})
+		return
+	}
+
+	rowErrors, total, err := h.service.GetErrors(c.Request.Context(), importID, pageRequest.Page, pageRequest.PageSize)
+	if err != nil {
+		log.Println("Error fetching customer import errors", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch import"})
+		return
+	}
+
+	c.JSON(http.StatusOK, customerImportReport{
+		CustomerImport: customerImport,
+		Errors:         rowErrors,
+		ErrorsTotal:    total,
+		ErrorsPage:     pageRequest.Page,
+		ErrorsPageSize: pageRequest.PageSize,
+	})
+}
+
+// readImportUpload returns the uploaded file with its name and content type,
+// from the multipart field "file" or else from the raw body.
+func readImportUpload(c *gin.Context) ([]byte, string, string, error) {
+	contentType, _, _ := mime.ParseMediaType(c.ContentType())
+	if contentType != "multipart/form-data" {
+		payload, err := io.ReadAll(c.Request.Body)
+		return payload, "", contentType, err
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, "", "", err
+	}
+	defer file.Close()
+
+	payload, err := io.ReadAll(file)
+	fileContentType, _, _ := mime.ParseMediaType(fileHeader.Header.Get("Content-Type"))
+	return payload, fileHeader.Filename, fileContentType, err
+}
+
+// importFormat picks the file format from the format parameter, then the
+// content type, then the file extension. It returns "" when none tells.
+func importFormat(format string, contentType string, fileName string) string {
+	switch strings.ToLower(format) {
+	case model.ImportFormatCSV, model.ImportFormatNDJSON:
+		return strings.ToLower(format)
+	case "":
+	default:
+		return ""
+	}
+
+	switch contentType {
+	case "text/csv":
+		return model.ImportFormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return model.ImportFormatNDJSON
+	}
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return model.ImportFormatCSV
+	case ".ndjson", ".jsonl":
+		return model.ImportFormatNDJSON
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/service"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CustomerPrivacyHandler struct {
+	service *service.CustomerPrivacyService
+}
+
+type CustomerFavoriteResponse struct {
+	ProductID int      `json:"product_id" example:"1"`
+	Note      string   `json:"note" example:"Wait for sale, size M"`
+	Tags      []string `json:"tags" example:"gift"`
+	Priority  int      `json:"priority" example:"5"`
+	Position  int      `json:"position" example:"0"`
+	CreatedAt string   `json:"created_at" example:"2025-06-19T12:00:00Z"`
+}
+
+type FavoriteChangeResponse struct {
+	ID        int64  `json:"id" example:"42"`
+	Type      string `json:"type" example:"added"`
+	ProductID int    `json:"product_id" example:"1"`
+	ChangedAt string `json:"changed_at" example:"2025-06-19T12:00:00Z"`
+}
+
+type CustomerCollectionResponse struct {
+	FavoriteCollectionResponse
+	ProductIDs []int `json:"product_ids" example:"1,2"`
+}
+
+type ErasureActionResponse struct {
+	Table  string `json:"table" example:"customers"`
+	Action string `json:"action" example:"anonymized" enums:"anonymized,retained,deleted"`
+	Rows   int64  `json:"rows" example:"1"`
+}
+
+type ErasureReceiptResponse struct {
+	ID           string                  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CustomerID   string                  `json:"customer_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ErasedBy     string                  `json:"erased_by" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ErasedAt     string                  `json:"erased_at" example:"2025-06-19T12:00:00Z"`
+	Actions      []ErasureActionResponse `json:"actions"`
+	PreviousHash string                  `json:"previous_hash" example:"0000000000000000000000000000000000000000000000000000000000000000"`
+	Hash         string                  `json:"hash" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+}
+
+type CustomerDataExportResponse struct {
+	Customer           CustomerResponse                  `json:"customer"`
+	ErasedAt           string                            `json:"erased_at,omitempty" example:"2025-06-19T12:00:00Z"`
+	Favorites          []CustomerFavoriteResponse        `json:"favorites"`
+	RemovedFavorites   []RemovedFavoriteResponse         `json:"removed_favorites"`
+	Collections        []CustomerCollectionResponse      `json:"collections"`
+	ShareLinks         []ShareLinkResponse               `json:"share_links"`
+	PriceAlertSettings *PriceAlertSettingsResponse       `json:"price_alert_settings,omitempty"`
+	PriceAlerts        []PriceAlertResponse              `json:"price_alerts"`
+	Tags               []CustomerTagResponse             `json:"tags"`
+	StateTransitions   []CustomerStateTransitionResponse `json:"state_transitions"`
+	FavoriteChanges    []FavoriteChangeResponse          `json:"favorite_changes"`
+	ErasureReceipts    []ErasureReceiptResponse          `json:"erasure_receipts"`
+}
+
+type ErasureVerificationResponse struct {
+	Valid    bool                    `json:"valid" example:"true"`
+	Checked  int                     `json:"checked" example:"12"`
+	BrokenAt *ErasureReceiptResponse `json:"broken_at,omitempty"`
+}
+
+func NewCustomerPrivacyHandler(service *service.CustomerPrivacyService) *CustomerPrivacyHandler {
+	return &CustomerPrivacyHandler{service: service}
+}
+
+// @Summary Export customer data
+// @Description Returns everything stored about a customer, including deleted and erased customers: profile, favorites, tags, state transitions and erasure receipts. Requires the admin role.
+// @Tags Customer
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Success 200 {object} CustomerDataExportResponse "Customer data"
+// @Failure 400 {object} ErrorResponse "Invalid customer ID format"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/data-export [get]
+func (h *CustomerPrivacyHandler) DataExport(c *gin.Context) {
+	customerID := c.Param("customer_id")
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
+		return
+	}
+
+	export, err := h.service.DataExport(c.Request.Context(), customerID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+			return
+		}
+		log.Println("Error exporting customer data", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to export customer data"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="customer-`+customerID+`.json"`)
+	c.JSON(http.StatusOK, export)
+}
+
+// @Summary Erase customer
+// @Description Anonymizes the personal data of a customer, deleted or not, and returns a receipt of what was done. The customer is kept deleted and anonymized so its favorites still count in statistics. Receipts are chained by their hashes, so changes to them are detected. Requires the admin role.
+// @Tags Customer
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {object} ErasureReceiptResponse "Erasure receipt"
+// @Failure 400 {object} ErrorResponse "Invalid customer ID format"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 409 {object} ErrorResponse "Customer already erased"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/erase [post]
+func (h *CustomerPrivacyHandler) Erase(c *gin.Context) {
+	customerID := c.Param("customer_id")
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
+		return
+	}
+
+	receipt, err := h.service.Erase(c.Request.Context(), customerID, c.GetString("userID"))
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+		case domain.ErrAlreadyErased:
+			c.JSON(http.StatusConflict, gin.H{"message": "Customer already erased"})
+		default:
+			log.Println("Error erasing customer", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to erase customer"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
+
+// @Summary Verify erasure receipts
+// @Description Recomputes the hash chain of the erasure receipts and reports the first receipt that was changed, if any. Requires the admin role.
+// @Tags Customer
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ErasureVerificationResponse "Verification result"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/erasures/verify [get]
+func (h *CustomerPrivacyHandler) VerifyErasures(c *gin.Context) {
+	checked, broken, err := h.service.VerifyErasureReceipts(c.Request.Context())
+	if err != nil {
+		log.Println("Error verifying erasure receipts", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to verify erasure receipts"})
+		return
+	}
+
+	response := gin.H{"valid": broken == nil, "checked": checked}
+	if broken != nil {
+		response["broken_at"] = broken
+	}
+	c.JSON(http.StatusOK, response)
+}
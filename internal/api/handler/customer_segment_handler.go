@@ -0,0 +1,334 @@
+package handler
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/domain/service"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const maxSegmentPageSize = 100
+
+type CustomerSegmentHandler struct {
+	service *service.CustomerSegmentService
+}
+
+type CustomerSegmentRequest struct {
+	Name        string `json:"name" validate:"required,max=100" example:"Electronics fans"`
+	Description string `json:"description,omitempty" example:"Joined this year with many electronics favorites"`
+	Expression  string `json:"expression" validate:"required" example:"favorites[\"electronics\"] > 10 and created_at >= \"2025-01-01\""`
+}
+
+type CustomerSegmentResponse struct {
+	ID          string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name        string `json:"name" example:"Electronics fans"`
+	Description string `json:"description,omitempty" example:"Joined this year with many electronics favorites"`
+	Expression  string `json:"expression" example:"favorites[\"electronics\"] > 10 and created_at >= \"2025-01-01\""`
+	CreatedBy   string `json:"created_by" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CreatedAt   string `json:"created_at" example:"2025-06-19T12:00:00Z"`
+	UpdatedAt   string `json:"updated_at" example:"2025-06-19T12:00:00Z"`
+}
+
+type SegmentPreviewRequest struct {
+	Expression string `json:"expression" validate:"required" example:"tags contains \"vip\""`
+}
+
+type SegmentPreviewResponse struct {
+	Count int `json:"count" example:"42"`
+}
+
+type CustomerPageResponse struct {
+	Items    []CustomerResponse `json:"items"`
+	Total    int                `json:"total" example:"42"`
+	Page     int                `json:"page" example:"1"`
+	PageSize int                `json:"page_size" example:"20"`
+}
+
+func NewCustomerSegmentHandler(service *service.CustomerSegmentService) *CustomerSegmentHandler {
+	return &CustomerSegmentHandler{service: service}
+}
+
+// @Summary Create segment
+// @Description Saves a segment of customers defined by a filter expression. Expressions compare fields with literals and combine comparisons with and, or, not and parentheses. Fields: name and email (=, !=, contains), created_at (ordering with a date), attributes.<name> (=, != and ordering with numbers), tags (contains) and favorites or favorites["<category>"] (ordering with a number).
+// @Tags Customer Segment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param segment body CustomerSegmentRequest true "Segment"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 201 {object} CustomerSegmentResponse "Created segment"
+// @Header 201 {string} Location "URL of the created segment"
+// @Failure 400 {object} ErrorResponse "Invalid request data or expression"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/segments [post]
+func (h *CustomerSegmentHandler) Create(c *gin.Context) {
+	request, ok := bindSegmentRequest(c)
+	if !ok {
+		return
+	}
+
+	customerSegment, err := h.service.Create(c.Request.Context(), model.CustomerSegment{
+		Name:        request.Name,
+		Description: request.Description,
+		Expression:  request.Expression,
+		CreatedBy:   c.GetString("userID"),
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidSegment) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		log.Println("Error creating segment", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create segment"})
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path+"/"+customerSegment.ID)
+	c.JSON(http.StatusCreated, customerSegment)
+}
+
+// @Summary Get segments
+// @Description Retrieves the saved segments
+// @Tags Customer Segment
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} CustomerSegmentResponse "List of segments"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/segments [get]
+func (h *CustomerSegmentHandler) GetAll(c *gin.Context) {
+	segments, err := h.service.GetAll(c.Request.Context())
+	if err != nil {
+		log.Println("Error fetching segments", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch segments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, segments)
+}
+
+// @Summary Get segment by ID
+// @Description Retrieves a saved segment
+// @Tags Customer Segment
+// @Produce json
+// @Security BearerAuth
+// @Param segment_id path string true "Segment ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Success 200 {object} CustomerSegmentResponse "Segment"
+// @Failure 400 {object} ErrorResponse "Invalid segment ID format"
+// @Failure 404 {object} ErrorResponse "Segment not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/segments/{segment_id} [get]
+func (h *CustomerSegmentHandler) GetByID(c *gin.Context) {
+	segmentID, ok := segmentIDParam(c)
+	if !ok {
+		return
+	}
+
+	customerSegment, err := h.service.GetByID(c.Request.Context(), segmentID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Segment not found"})
+			return
+		}
+		log.Println("Error fetching segment", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch segment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, customerSegment)
+}
+
+// @Summary Update segment
+// @Description Replaces the name, description and expression of a segment
+// @Tags Customer Segment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param segment_id path string true "Segment ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param segment body CustomerSegmentRequest true "Segment"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {object} CustomerSegmentResponse "Updated segment"
+// @Failure 400 {object} ErrorResponse "Invalid request data, expression or segment ID"
+// @Failure 404 {object} ErrorResponse "Segment not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/segments/{segment_id} [put]
+func (h *CustomerSegmentHandler) Update(c *gin.Context) {
+	segmentID, ok := segmentIDParam(c)
+	if !ok {
+		return
+	}
+
+	request, ok := bindSegmentRequest(c)
+	if !ok {
+		return
+	}
+
+	customerSegment, err := h.service.Update(c.Request.Context(), model.CustomerSegment{
+		ID:          segmentID,
+		Name:        request.Name,
+		Description: request.Description,
+		Expression:  request.Expression,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidSegment) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Segment not found"})
+			return
+		}
+		log.Println("Error updating segment", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update segment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, customerSegment)
+}
+
+// @Summary Delete segment
+// @Description Deletes a saved segment
+// @Tags Customer Segment
+// @Security BearerAuth
+// @Param segment_id path string true "Segment ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 204 "No content"
+// @Failure 400 {object} ErrorResponse "Invalid segment ID format"
+// @Failure 404 {object} ErrorResponse "Segment not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/segments/{segment_id} [delete]
+func (h *CustomerSegmentHandler) Delete(c *gin.Context) {
+	segmentID, ok := segmentIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), segmentID); err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Segment not found"})
+			return
+		}
+		log.Println("Error deleting segment", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete segment"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get segment customers
+// @Description Evaluates a segment and returns one page of its customers, oldest first
+// @Tags Customer Segment
+// @Produce json
+// @Security BearerAuth
+// @Param segment_id path string true "Segment ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param page query int false "Page number, starting at 1" example=1
+// @Param page_size query int false "Customers per page" example=20
+// @Success 200 {object} CustomerPageResponse "Segment customers"
+// @Failure 400 {object} ErrorResponse "Invalid segment ID, pagination or expression"
+// @Failure 404 {object} ErrorResponse "Segment not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/segments/{segment_id}/customers [get]
+func (h *CustomerSegmentHandler) Members(c *gin.Context) {
+	segmentID, ok := segmentIDParam(c)
+	if !ok {
+		return
+	}
+
+	pageRequest, ok := parsePageRequest(c, maxSegmentPageSize)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid pagination parameters"})
+		return
+	}
+
+	page, err := h.service.Members(c.Request.Context(), segmentID, pageRequest.Page, pageRequest.PageSize)
+	if err != nil {
+		// Saved expressions can become invalid when an attribute is deleted.
+		if errors.Is(err, domain.ErrInvalidSegment) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Segment not found"})
+			return
+		}
+		log.Println("Error evaluating segment", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to evaluate segment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary Preview segment
+// @Description Returns the number of customers matching an expression without saving it
+// @Tags Customer Segment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param preview body SegmentPreviewRequest true "Expression to preview"
+// @Success 200 {object} SegmentPreviewResponse "Number of matching customers"
+// @Failure 400 {object} ErrorResponse "Invalid expression"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/segments/preview [post]
+func (h *CustomerSegmentHandler) Preview(c *gin.Context) {
+	var request SegmentPreviewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Error binding request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		log.Println("Error validating request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	count, err := h.service.Preview(c.Request.Context(), request.Expression)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidSegment) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		log.Println("Error previewing segment", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to preview segment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"count": count})
+}
+
+func bindSegmentRequest(c *gin.Context) (CustomerSegmentRequest, bool) {
+	var request CustomerSegmentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Error binding request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return request, false
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		log.Println("Error validating request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return request, false
+	}
+
+	return request, true
+}
+
+func segmentIDParam(c *gin.Context) (string, bool) {
+	segmentID := c.Param("segment_id")
+	if _, err := uuid.Parse(segmentID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid segment ID"})
+		return "", false
+	}
+	return segmentID, true
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/domain/service"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+type CustomerStateHandler struct {
+	service *service.CustomerStateService
+}
+
+type CustomerTransitionRequest struct {
+	State  string `json:"state" validate:"required,oneof=pending active suspended closed" example:"suspended"`
+	Reason string `json:"reason" validate:"required,max=500" example:"Chargeback under fraud review"`
+}
+
+type CustomerStateTransitionResponse struct {
+	ID         string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CustomerID string `json:"customer_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	From       string `json:"from" example:"active"`
+	To         string `json:"to" example:"suspended"`
+	Reason     string `json:"reason,omitempty" example:"Chargeback under fraud review"`
+	Actor      string `json:"actor" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CreatedAt  string `json:"created_at" example:"2025-06-19T12:00:00Z"`
+}
+
+func NewCustomerStateHandler(service *service.CustomerStateService) *CustomerStateHandler {
+	return &CustomerStateHandler{service: service}
+}
+
+// @Summary Change customer state
+// @Description Moves a customer to another state, recording the reason and the user who made the change. Pending customers can become active or closed, active customers suspended or closed, and suspended customers active or closed. Closed customers cannot change state. Requires the admin role.
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param transition body CustomerTransitionRequest true "New state and reason"
+// @Param If-Match header string false "ETag the customer must still have"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {object} CustomerResponse "Customer in the new state"
+// @Header 200 {string} ETag "New version of the customer"
+// @Failure 400 {object} ErrorResponse "Invalid request data or customer ID"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 409 {object} ErrorResponse "Transition not allowed from the current state"
+// @Failure 412 {object} ErrorResponse "Customer was modified"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/transitions [post]
+func (h *CustomerStateHandler) Transition(c *gin.Context) {
+	customerID := c.Param("customer_id")
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid If-Match header"})
+		return
+	}
+
+	var request CustomerTransitionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Error binding request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		log.Println("Error validating request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	customer, err := h.service.Transition(
+		c.Request.Context(),
+		customerID,
+		model.CustomerState(request.State),
+		request.Reason,
+		c.GetString("userID"),
+		expectedVersion,
+	)
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+		case domain.ErrInvalidTransition:
+			c.JSON(http.StatusConflict, gin.H{"message": "Customer cannot move to " + request.State + " from its current state"})
+		case domain.ErrVersionConflict:
+			c.JSON(http.StatusPreconditionFailed, gin.H{"message": "Customer was modified"})
+		default:
+			log.Println("Error changing customer state", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to change customer state"})
+		}
+		return
+	}
+
+	c.Header("ETag", customerETag(customer))
+	c.JSON(http.StatusOK, customer)
+}
+
+// @Summary Get customer state transitions
+// @Description Retrieves the state changes of a customer, oldest first. Requires the admin role.
+// @Tags Customer
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Success 200 {array} CustomerStateTransitionResponse "List of transitions"
+// @Failure 400 {object} ErrorResponse "Invalid customer ID format"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/transitions [get]
+func (h *CustomerStateHandler) GetTransitions(c *gin.Context) {
+	customerID := c.Param("customer_id")
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
+		return
+	}
+
+	transitions, err := h.service.GetTransitions(c.Request.Context(), customerID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+			return
+		}
+		log.Println("Error fetching customer state transitions", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch transitions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transitions)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/service"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CustomerTagHandler struct {
+	service *service.CustomerTagService
+}
+
+type CustomerTagResponse struct {
+	Tag       string `json:"tag" example:"vip"`
+	CreatedAt string `json:"created_at" example:"2025-06-19T12:00:00Z"`
+}
+
+type TagCountResponse struct {
+	Tag       string `json:"tag" example:"vip"`
+	Customers int    `json:"customers" example:"42"`
+}
+
+func NewCustomerTagHandler(service *service.CustomerTagService) *CustomerTagHandler {
+	return &CustomerTagHandler{service: service}
+}
+
+// @Summary Get customer tags
+// @Description Retrieves the tags of a customer
+// @Tags Customer Tag
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Success 200 {array} CustomerTagResponse "List of tags"
+// @Failure 400 {object} ErrorResponse "Invalid customer ID format"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/tags [get]
+func (h *CustomerTagHandler) GetByCustomerID(c *gin.Context) {
+	customerID := c.Param("customer_id")
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
+		return
+	}
+
+	tags, err := h.service.GetByCustomerID(c.Request.Context(), customerID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+			return
+		}
+		log.Println("Error fetching customer tags", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// @Summary Tag customer
+// @Description Adds a tag to a customer. Tags are compared ignoring case and have up to 50 letters, digits, spaces and the characters _ : . -
+// @Tags Customer Tag
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param tag path string true "Tag" example="vip"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 204 "Tag added"
+// @Failure 400 {object} ErrorResponse "Invalid customer ID or tag"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/tags/{tag} [put]
+func (h *CustomerTagHandler) Add(c *gin.Context) {
+	customerID := c.Param("customer_id")
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
+		return
+	}
+
+	err := h.service.Add(c.Request.Context(), customerID, c.Param("tag"))
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidTag:
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid tag"})
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+		default:
+			log.Println("Error tagging customer", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to add tag"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Untag customer
+// @Description Removes a tag from a customer
+// @Tags Customer Tag
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param tag path string true "Tag" example="vip"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 204 "Tag removed"
+// @Failure 400 {object} ErrorResponse "Invalid customer ID format"
+// @Failure 404 {object} ErrorResponse "Customer or tag not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/tags/{tag} [delete]
+func (h *CustomerTagHandler) Remove(c *gin.Context) {
+	customerID := c.Param("customer_id")
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
+		return
+	}
+
+	err := h.service.Remove(c.Request.Context(), customerID, c.Param("tag"))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer or tag not found"})
+			return
+		}
+		log.Println("Error untagging customer", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to remove tag"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get tags
+// @Description Retrieves every tag in use with its number of customers
+// @Tags Customer Tag
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} TagCountResponse "List of tags"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/tags [get]
+func (h *CustomerTagHandler) GetAll(c *gin.Context) {
+	counts, err := h.service.CountByTag(c.Request.Context())
+	if err != nil {
+		log.Println("Error fetching tags", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, counts)
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"app/internal/domain/service"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAnalyticsDays        = 30
+	maxAnalyticsDays            = 365
+	defaultPopularProductsLimit = 10
+	maxPopularProductsLimit     = 100
+	maxProductFavoritesPageSize = 100
+)
+
+type FavoriteAnalyticsHandler struct {
+	service *service.FavoriteAnalyticsService
+}
+
+type PopularProductResponse struct {
+	ProductID int    `json:"product_id" example:"1"`
+	Title     string `json:"title" example:"Fjallraven - Foldsack No. 1 Backpack"`
+	Added     int    `json:"added" example:"120"`
+	Removed   int    `json:"removed" example:"15"`
+	Favorites int    `json:"favorites" example:"830"`
+}
+
+type PopularProductsResponse struct {
+	Days        int                      `json:"days" example:"30"`
+	Items       []PopularProductResponse `json:"items"`
+	RefreshedAt string                   `json:"refreshed_at" example:"2025-06-19T12:00:00Z"`
+}
+
+type FavoriteDailyActivityResponse struct {
+	Day     string `json:"day" example:"2025-06-19"`
+	Added   int    `json:"added" example:"42"`
+	Removed int    `json:"removed" example:"7"`
+}
+
+type FavoriteActivityResponse struct {
+	Days        int                             `json:"days" example:"30"`
+	Items       []FavoriteDailyActivityResponse `json:"items"`
+	RefreshedAt string                          `json:"refreshed_at" example:"2025-06-19T12:00:00Z"`
+}
+
+type FavoriteSummaryResponse struct {
+	Customers                       int     `json:"customers" example:"1000"`
+	CustomersWithFavorites          int     `json:"customers_with_favorites" example:"640"`
+	Favorites                       int     `json:"favorites" example:"3200"`
+	AveragePerCustomer              float64 `json:"average_per_customer" example:"3.2"`
+	AveragePerCustomerWithFavorites float64 `json:"average_per_customer_with_favorites" example:"5"`
+	RefreshedAt                     string  `json:"refreshed_at" example:"2025-06-19T12:00:00Z"`
+}
+
+type ProductFavoriteCountResponse struct {
+	ProductID int    `json:"product_id" example:"1"`
+	Title     string `json:"title" example:"Fjallraven - Foldsack No. 1 Backpack"`
+	Favorites int    `json:"favorites" example:"830"`
+}
+
+type ProductFavoriteCountPageResponse struct {
+	Items       []ProductFavoriteCountResponse `json:"items"`
+	Total       int                            `json:"total" example:"20"`
+	Page        int                            `json:"page" example:"1"`
+	PageSize    int                            `json:"page_size" example:"20"`
+	RefreshedAt string                         `json:"refreshed_at" example:"2025-06-19T12:00:00Z"`
+}
+
+func NewFavoriteAnalyticsHandler(service *service.FavoriteAnalyticsService) *FavoriteAnalyticsHandler {
+	return &FavoriteAnalyticsHandler{
+		service: service,
+	}
+}
+
+// intQuery reads the query parameter name, returning def when it is missing.
+// It returns false when the value is not an integer between 1 and max.
+func intQuery(c *gin.Context, name string, def int, max int) (int, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return def, true
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 1 || value > max {
+		return 0, false
+	}
+	return value, true
+}
+
+// @Summary Get popular products
+// @Description Ranks the products by favorites added in the last days, today included, with the favorites removed in the same days and their current favorite count. Computed from periodically refreshed views. Requires the admin role.
+// @Tags Analytics
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Days in the window, up to 365" example=30
+// @Param limit query int false "Number of products, up to 100" example=10
+// @Success 200 {object} PopularProductsResponse "Popular products"
+// @Failure 400 {object} ErrorResponse "Invalid days or limit"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/analytics/favorites/popular [get]
+func (h *FavoriteAnalyticsHandler) GetPopular(c *gin.Context) {
+	days, ok := intQuery(c, "days", defaultAnalyticsDays, maxAnalyticsDays)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid days, use 1 to 365"})
+		return
+	}
+
+	limit, ok := intQuery(c, "limit", defaultPopularProductsLimit, maxPopularProductsLimit)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid limit, use 1 to 100"})
+		return
+	}
+
+	popular, err := h.service.GetPopular(c.Request.Context(), days, limit)
+	if err != nil {
+		log.Println("Error fetching popular products", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch popular products"})
+		return
+	}
+
+	c.JSON(http.StatusOK, popular)
+}
+
+// @Summary Get favorite activity
+// @Description Retrieves the favorites added and removed on each of the last days, today included, oldest first. Computed from periodically refreshed views. Requires the admin role.
+// @Tags Analytics
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Days to retrieve, up to 365" example=30
+// @Success 200 {object} FavoriteActivityResponse "Daily favorite activity"
+// @Failure 400 {object} ErrorResponse "Invalid days"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/analytics/favorites/activity [get]
+func (h *FavoriteAnalyticsHandler) GetActivity(c *gin.Context) {
+	days, ok := intQuery(c, "days", defaultAnalyticsDays, maxAnalyticsDays)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid days, use 1 to 365"})
+		return
+	}
+
+	activity, err := h.service.GetActivity(c.Request.Context(), days)
+	if err != nil {
+		log.Println("Error fetching favorite activity", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch favorite activity"})
+		return
+	}
+
+	c.JSON(http.StatusOK, activity)
+}
+
+// @Summary Get favorite summary
+// @Description Counts the customers not deleted and their favorites, with the average favorites per customer and per customer with favorites. Computed from periodically refreshed views. Requires the admin role.
+// @Tags Analytics
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} FavoriteSummaryResponse "Favorite summary"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/analytics/favorites/summary [get]
+func (h *FavoriteAnalyticsHandler) GetSummary(c *gin.Context) {
+	summary, err := h.service.GetSummary(c.Request.Context())
+	if err != nil {
+		log.Println("Error fetching favorite summary", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch favorite summary"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// @Summary Get favorite counts per product
+// @Description Retrieves the number of customers who favorite each product, deleted ones included, the most favorited first, with the title from the catalog. The title is empty for products no longer in the catalog or while it is unavailable. Computed from periodically refreshed views. Requires the admin role.
+// @Tags Analytics
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number, starting at 1" example=1
+// @Param page_size query int false "Products per page" example=20
+// @Success 200 {object} ProductFavoriteCountPageResponse "Favorite counts"
+// @Failure 400 {object} ErrorResponse "Invalid pagination"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/analytics/favorites/products [get]
+func (h *FavoriteAnalyticsHandler) GetProductCounts(c *gin.Context) {
+	pageRequest, ok := parsePageRequest(c, maxProductFavoritesPageSize)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid pagination parameters"})
+		return
+	}
+
+	page, err := h.service.GetProductCounts(c.Request.Context(), pageRequest.Page, pageRequest.PageSize)
+	if err != nil {
+		log.Println("Error fetching favorite counts", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch favorite counts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
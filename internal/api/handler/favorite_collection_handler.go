@@ -0,0 +1,449 @@
+package handler
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/domain/service"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+type FavoriteCollectionHandler struct {
+	service *service.FavoriteCollectionService
+}
+
+type FavoriteCollectionRequest struct {
+	Name        string `json:"name" validate:"required,max=100" example:"Birthday ideas"`
+	Description string `json:"description,omitempty" validate:"max=500" example:"Gifts I would like to get"`
+	Public      bool   `json:"public" example:"false"`
+}
+
+type FavoriteCollectionOrderRequest struct {
+	CollectionIDs []string `json:"collection_ids" validate:"required,dive,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
+
+type FavoriteCollectionResponse struct {
+	ID          string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CustomerID  string `json:"customer_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name        string `json:"name" example:"Birthday ideas"`
+	Description string `json:"description,omitempty" example:"Gifts I would like to get"`
+	Public      bool   `json:"public" example:"false"`
+	Position    int    `json:"position" example:"0"`
+	Favorites   int    `json:"favorites" example:"3"`
+	CreatedAt   string `json:"created_at" example:"2025-06-19T12:00:00Z"`
+	UpdatedAt   string `json:"updated_at" example:"2025-06-19T12:00:00Z"`
+}
+
+func NewFavoriteCollectionHandler(service *service.FavoriteCollectionService) *FavoriteCollectionHandler {
+	return &FavoriteCollectionHandler{service: service}
+}
+
+// @Summary Create collection
+// @Description Creates a named collection of the customer's favorites after the existing ones
+// @Tags Favorite Collection
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param collection body FavoriteCollectionRequest true "Collection"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 201 {object} FavoriteCollectionResponse "Created collection"
+// @Header 201 {string} Location "URL of the created collection"
+// @Failure 400 {object} ErrorResponse "Invalid request data or customer ID"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 409 {object} ErrorResponse "Collection name already in use"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/collections [post]
+func (h *FavoriteCollectionHandler) Create(c *gin.Context) {
+	customerID, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	request, ok := bindFavoriteCollectionRequest(c)
+	if !ok {
+		return
+	}
+
+	collection, err := h.service.Create(c.Request.Context(), model.FavoriteCollection{
+		CustomerID:  customerID,
+		Name:        request.Name,
+		Description: request.Description,
+		Public:      request.Public,
+	})
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+		case domain.ErrCollectionAlreadyExists:
+			c.JSON(http.StatusConflict, gin.H{"message": "Collection name already in use"})
+		default:
+			log.Println("Error creating collection", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create collection"})
+		}
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path+"/"+collection.ID)
+	c.JSON(http.StatusCreated, collection)
+}
+
+// @Summary Get collections
+// @Description Retrieves the customer's collections in their order
+// @Tags Favorite Collection
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Success 200 {array} FavoriteCollectionResponse "List of collections"
+// @Failure 400 {object} ErrorResponse "Invalid customer ID"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/collections [get]
+func (h *FavoriteCollectionHandler) GetAll(c *gin.Context) {
+	customerID, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	collections, err := h.service.GetByCustomerID(c.Request.Context(), customerID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+			return
+		}
+		log.Println("Error fetching collections", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch collections"})
+		return
+	}
+
+	c.JSON(http.StatusOK, collections)
+}
+
+// @Summary Reorder collections
+// @Description Orders the customer's collections as listed. Every collection must be listed once.
+// @Tags Favorite Collection
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param order body FavoriteCollectionOrderRequest true "Collection IDs in the new order"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {array} FavoriteCollectionResponse "Reordered collections"
+// @Failure 400 {object} ErrorResponse "Invalid request data, customer ID or order"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/collections/order [put]
+func (h *FavoriteCollectionHandler) Reorder(c *gin.Context) {
+	customerID, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var request FavoriteCollectionOrderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Error binding request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		log.Println("Error validating request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	collections, err := h.service.Reorder(c.Request.Context(), customerID, request.CollectionIDs)
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+		case domain.ErrInvalidCollectionOrder:
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Order must list every collection once"})
+		default:
+			log.Println("Error reordering collections", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to reorder collections"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, collections)
+}
+
+// @Summary Get collection by ID
+// @Description Retrieves a collection of the customer
+// @Tags Favorite Collection
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param collection_id path string true "Collection ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Success 200 {object} FavoriteCollectionResponse "Collection"
+// @Failure 400 {object} ErrorResponse "Invalid customer or collection ID"
+// @Failure 404 {object} ErrorResponse "Customer or collection not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/collections/{collection_id} [get]
+func (h *FavoriteCollectionHandler) GetByID(c *gin.Context) {
+	customerID, collectionID, ok := collectionParams(c)
+	if !ok {
+		return
+	}
+
+	collection, err := h.service.GetByID(c.Request.Context(), customerID, collectionID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Collection not found"})
+			return
+		}
+		log.Println("Error fetching collection", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch collection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// @Summary Update collection
+// @Description Replaces the name, description and visibility of a collection
+// @Tags Favorite Collection
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param collection_id path string true "Collection ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param collection body FavoriteCollectionRequest true "Collection"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {object} FavoriteCollectionResponse "Updated collection"
+// @Failure 400 {object} ErrorResponse "Invalid request data, customer or collection ID"
+// @Failure 404 {object} ErrorResponse "Customer or collection not found"
+// @Failure 409 {object} ErrorResponse "Collection name already in use"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/collections/{collection_id} [put]
+func (h *FavoriteCollectionHandler) Update(c *gin.Context) {
+	customerID, collectionID, ok := collectionParams(c)
+	if !ok {
+		return
+	}
+
+	request, ok := bindFavoriteCollectionRequest(c)
+	if !ok {
+		return
+	}
+
+	collection, err := h.service.Update(c.Request.Context(), model.FavoriteCollection{
+		ID:          collectionID,
+		CustomerID:  customerID,
+		Name:        request.Name,
+		Description: request.Description,
+		Public:      request.Public,
+	})
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Collection not found"})
+		case domain.ErrCollectionAlreadyExists:
+			c.JSON(http.StatusConflict, gin.H{"message": "Collection name already in use"})
+		default:
+			log.Println("Error updating collection", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update collection"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// @Summary Delete collection
+// @Description Deletes a collection. Its products remain favorites of the customer.
+// @Tags Favorite Collection
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param collection_id path string true "Collection ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 204 "No content"
+// @Failure 400 {object} ErrorResponse "Invalid customer or collection ID"
+// @Failure 404 {object} ErrorResponse "Customer or collection not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/collections/{collection_id} [delete]
+func (h *FavoriteCollectionHandler) Delete(c *gin.Context) {
+	customerID, collectionID, ok := collectionParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), customerID, collectionID); err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Collection not found"})
+			return
+		}
+		log.Println("Error deleting collection", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete collection"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get collection products
+// @Description Retrieves the products of a collection in the order they were added
+// @Tags Favorite Collection
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param collection_id path string true "Collection ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Success 200 {array} Product "List of products"
+// @Failure 400 {object} ErrorResponse "Invalid customer or collection ID"
+// @Failure 404 {object} ErrorResponse "Customer or collection not found"
+// @Failure 500 {object} ErrorResponse "Failed to fetch collection products"
+// @Router /api/v1/customers/{customer_id}/collections/{collection_id}/favorites [get]
+func (h *FavoriteCollectionHandler) GetProducts(c *gin.Context) {
+	customerID, collectionID, ok := collectionParams(c)
+	if !ok {
+		return
+	}
+
+	products, err := h.service.GetProducts(c.Request.Context(), customerID, collectionID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Collection not found"})
+			return
+		}
+		log.Println("Error getting collection products", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch collection products"})
+		return
+	}
+
+	c.JSON(http.StatusOK, products)
+}
+
+// @Summary Add a favorite to a collection
+// @Description Adds one of the customer's favorite products to the collection. Adding a product already in the collection does nothing.
+// @Tags Favorite Collection
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param collection_id path string true "Collection ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param product_id path int true "Product ID" example=123
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 204 "Product added to the collection"
+// @Failure 400 {object} ErrorResponse "Invalid customer, collection or product ID"
+// @Failure 404 {object} ErrorResponse "Customer or collection not found"
+// @Failure 409 {object} ErrorResponse "Product is not a favorite of the customer"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/collections/{collection_id}/favorites/{product_id} [put]
+func (h *FavoriteCollectionHandler) AddFavorite(c *gin.Context) {
+	customerID, collectionID, ok := collectionParams(c)
+	if !ok {
+		return
+	}
+
+	productID, err := strconv.Atoi(c.Param("product_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid product ID"})
+		return
+	}
+
+	if err := h.service.AddFavorite(c.Request.Context(), customerID, collectionID, productID); err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Collection not found"})
+		case domain.ErrFavoriteNotFound:
+			c.JSON(http.StatusConflict, gin.H{"message": "Product is not a favorite of the customer"})
+		default:
+			log.Println("Error adding product to collection", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to add product to collection"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Remove a favorite from a collection
+// @Description Removes the product from the collection. It remains a favorite of the customer.
+// @Tags Favorite Collection
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param collection_id path string true "Collection ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param product_id path int true "Product ID" example=123
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 204 "Product removed from the collection"
+// @Failure 400 {object} ErrorResponse "Invalid customer, collection or product ID"
+// @Failure 404 {object} ErrorResponse "Customer, collection or product not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/collections/{collection_id}/favorites/{product_id} [delete]
+func (h *FavoriteCollectionHandler) RemoveFavorite(c *gin.Context) {
+	customerID, collectionID, ok := collectionParams(c)
+	if !ok {
+		return
+	}
+
+	productID, err := strconv.Atoi(c.Param("product_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid product ID"})
+		return
+	}
+
+	if err := h.service.RemoveFavorite(c.Request.Context(), customerID, collectionID, productID); err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Collection not found"})
+		case domain.ErrFavoriteNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Product not found in collection"})
+		default:
+			log.Println("Error removing product from collection", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to remove product from collection"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func bindFavoriteCollectionRequest(c *gin.Context) (FavoriteCollectionRequest, bool) {
+	var request FavoriteCollectionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Error binding request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return request, false
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		log.Println("Error validating request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return request, false
+	}
+
+	return request, true
+}
+
+func collectionParams(c *gin.Context) (string, string, bool) {
+	customerID, ok := customerIDParam(c)
+	if !ok {
+		return "", "", false
+	}
+
+	collectionID := c.Param("collection_id")
+	if _, err := uuid.Parse(collectionID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid collection ID"})
+		return "", "", false
+	}
+	return customerID, collectionID, true
+}
+
+func customerIDParam(c *gin.Context) (string, bool) {
+	customerID := c.Param("customer_id")
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
+		return "", false
+	}
+	return customerID, true
+}
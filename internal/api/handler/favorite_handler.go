@@ -1,21 +1,107 @@
 package handler
 
 import (
+	"app/internal/domain"
+	"app/internal/domain/model"
 	"app/internal/domain/service"
+	"encoding/base64"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 )
 
 type FavoriteHandler struct {
-	favoriteService *service.FavoriteService
+	favoriteService    *service.FavoriteService
+	batchMaxOperations int
 }
 
 type FavoriteIncludeRequest struct {
-	ProductID int `json:"product_id" validate:"required" example:"123"`
+	ProductID int      `json:"product_id" validate:"required" example:"123"`
+	Note      string   `json:"note,omitempty" validate:"max=1000" example:"Wait for sale, size M"`
+	Tags      []string `json:"tags,omitempty" validate:"max=20" example:"gift"`
+	Priority  int      `json:"priority,omitempty" example:"5"`
+}
+
+type FavoriteUpdateRequest struct {
+	Note     *string   `json:"note,omitempty" validate:"omitempty,max=1000" example:"Wait for sale, size M"`
+	Tags     *[]string `json:"tags,omitempty" validate:"omitempty,max=20" example:"gift"`
+	Priority *int      `json:"priority,omitempty" example:"5"`
+	Position *int      `json:"position,omitempty" validate:"omitempty,min=0" example:"0"`
+}
+
+type FavoriteOrderRequest struct {
+	ProductIDs []int `json:"product_ids" validate:"required" example:"3,1,2"`
+}
+
+type FavoriteOperationRequest struct {
+	Op        string   `json:"op" validate:"required,oneof=add remove" example:"add"`
+	ProductID int      `json:"product_id" validate:"required" example:"123"`
+	Note      string   `json:"note,omitempty" validate:"max=1000" example:"Wait for sale, size M"`
+	Tags      []string `json:"tags,omitempty" validate:"max=20" example:"gift"`
+	Priority  int      `json:"priority,omitempty" example:"5"`
+}
+
+type FavoriteBatchRequest struct {
+	Mode       string                     `json:"mode,omitempty" validate:"omitempty,oneof=all-or-nothing best-effort" example:"all-or-nothing"`
+	Operations []FavoriteOperationRequest `json:"operations" validate:"required,min=1,dive"`
+}
+
+type FavoriteOperationResultResponse struct {
+	Op        string `json:"op" example:"add"`
+	ProductID int    `json:"product_id" example:"123"`
+	Status    string `json:"status" example:"added"`
+	Error     string `json:"error,omitempty" example:"product not found in catalog"`
+}
+
+type FavoriteBatchResponse struct {
+	Mode    string                            `json:"mode" example:"all-or-nothing"`
+	Applied bool                              `json:"applied" example:"true"`
+	Results []FavoriteOperationResultResponse `json:"results"`
+}
+
+type FavoriteResponse struct {
+	ProductID int       `json:"product_id" example:"123"`
+	Note      string    `json:"note" example:"Wait for sale, size M"`
+	Tags      []string  `json:"tags" example:"gift"`
+	Priority  int       `json:"priority" example:"5"`
+	Position  int       `json:"position" example:"0"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FavoriteProductResponse struct {
+	ID       int       `json:"id" example:"1"`
+	Title    string    `json:"title" example:"Fjallraven Backpack"`
+	Category string    `json:"category" example:"men's clothing"`
+	Image    string    `json:"image" example:"https://fakestoreapi.com/img/81fPKd-2AYL._AC_SL1500_.jpg"`
+	Price    float64   `json:"price" example:"109.95"`
+	Note     string    `json:"note" example:"Wait for sale, size M"`
+	Tags     []string  `json:"tags" example:"gift"`
+	Priority int       `json:"priority" example:"5"`
+	Position int       `json:"position" example:"0"`
+	AddedAt  time.Time `json:"added_at"`
+}
+
+type AddedFavoriteResponse struct {
+	ProductID int       `json:"product_id" example:"123"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+type RemovedFavoriteResponse struct {
+	ProductID int       `json:"product_id" example:"123"`
+	RemovedAt time.Time `json:"removed_at"`
+}
+
+type FavoriteChangesResponse struct {
+	Added     []AddedFavoriteResponse   `json:"added"`
+	Removed   []RemovedFavoriteResponse `json:"removed"`
+	SyncToken string                    `json:"sync_token" example:"djE6NDI"`
 }
 
 type Product struct {
@@ -26,27 +112,35 @@ type Product struct {
 	Category    string  `json:"category,omitempty" example:"Electronics"`
 }
 
-func NewFavoriteHandler(favoriteService *service.FavoriteService) *FavoriteHandler {
+func NewFavoriteHandler(favoriteService *service.FavoriteService, batchMaxOperations int) *FavoriteHandler {
 	return &FavoriteHandler{
-		favoriteService: favoriteService,
+		favoriteService:    favoriteService,
+		batchMaxOperations: batchMaxOperations,
 	}
 }
 
 // @Summary Add a product to customer's favorites
-// @Description Adds a product to the specified customer's list of favorite products
+// @Description Adds a product after the last of the specified customer's favorite products, with an optional note, tags and priority. Adding a favorite again leaves its details unchanged.
 // @Tags Favorite
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
 // @Param favorite body FavoriteIncludeRequest true "Product to add to favorites"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
 // @Success 204 "Product added to favorites"
-// @Failure 400 {object} ErrorResponse "Invalid request data"
+// @Failure 400 {object} ErrorResponse "Invalid request data or tags"
 // @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 409 {object} ErrorResponse "Customer is suspended or closed"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
 // @Failure 500 {object} ErrorResponse "Failed to add to favorites"
 // @Router /api/v1/customers/{customer_id}/favorites [post]
 func (h *FavoriteHandler) AddFavorite(c *gin.Context) {
 	customerID := c.Param("customer_id")
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
+		return
+	}
 
 	var req FavoriteIncludeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -61,16 +155,240 @@ func (h *FavoriteHandler) AddFavorite(c *gin.Context) {
 		return
 	}
 
-	err := h.favoriteService.AddFavorite(c, customerID, req.ProductID)
+	err := h.favoriteService.AddFavorite(c, customerID, req.ProductID, model.FavoriteDetails{
+		Note:     req.Note,
+		Tags:     req.Tags,
+		Priority: req.Priority,
+	})
 	if err != nil {
-		log.Println("Error adding favorite", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to add favorite"})
+		if errors.Is(err, domain.ErrInvalidFavorite) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+		case domain.ErrCustomerSuspended:
+			c.JSON(http.StatusConflict, gin.H{"message": "Customer is suspended and cannot add favorites"})
+		case domain.ErrCustomerClosed:
+			c.JSON(http.StatusConflict, gin.H{"message": "Customer is closed and cannot add favorites"})
+		default:
+			log.Println("Error adding favorite", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to add favorite"})
+		}
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// @Summary Add and remove favorites in one request
+// @Description Applies add and remove operations in order in a single transaction, checking the products added against a single read of the catalog. Additions of unknown products or with invalid tags and removals of products that are not favorites fail. In all-or-nothing mode, the default, a failed operation leaves the favorites unchanged, the other operations are skipped and 422 is returned. In best-effort mode the other operations are applied. Adding a favorite again leaves it unchanged.
+// @Tags Favorite
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param batch body FavoriteBatchRequest true "Operations and mode"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {object} FavoriteBatchResponse "Result of each operation"
+// @Failure 400 {object} ErrorResponse "Invalid request data, customer ID or too many operations"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 409 {object} ErrorResponse "Customer is suspended or closed"
+// @Failure 422 {object} FavoriteBatchResponse "All-or-nothing batch not applied, or Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Failed to apply favorites batch"
+// @Router /api/v1/customers/{customer_id}/favorites:batch [post]
+func (h *FavoriteHandler) Batch(c *gin.Context) {
+	// Gin cannot route favorites:batch but as a parameter following
+	// favorites.
+	if c.Param("method") != ":batch" {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Not found"})
+		return
+	}
+
+	customerID, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req FavoriteBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Println("Error binding request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		log.Println("Error validating request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	if len(req.Operations) > h.batchMaxOperations {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "A batch can have at most " + strconv.Itoa(h.batchMaxOperations) + " operations"})
+		return
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = model.FavoriteBatchAllOrNothing
+	}
+
+	operations := make([]model.FavoriteOperation, 0, len(req.Operations))
+	for _, op := range req.Operations {
+		operations = append(operations, model.FavoriteOperation{
+			Op:        op.Op,
+			ProductID: op.ProductID,
+			Details: model.FavoriteDetails{
+				Note:     op.Note,
+				Tags:     op.Tags,
+				Priority: op.Priority,
+			},
+		})
+	}
+
+	result, err := h.favoriteService.Batch(c.Request.Context(), customerID, mode, operations)
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+		case domain.ErrCustomerSuspended:
+			c.JSON(http.StatusConflict, gin.H{"message": "Customer is suspended and cannot add favorites"})
+		case domain.ErrCustomerClosed:
+			c.JSON(http.StatusConflict, gin.H{"message": "Customer is closed and cannot add favorites"})
+		default:
+			log.Println("Error applying favorites batch", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to apply favorites batch"})
+		}
+		return
+	}
+
+	if !result.Applied {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Update a favorite
+// @Description Changes the note, tags, priority or position of a favorite. Fields left out are unchanged. Moving a favorite shifts the ones in between, and positions past the last one move it to the end.
+// @Tags Favorite
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param product_id path int true "Product ID of the favorite" example=123
+// @Param favorite body FavoriteUpdateRequest true "Fields to change"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {object} FavoriteResponse "Updated favorite"
+// @Failure 400 {object} ErrorResponse "Invalid request data, tags, customer or product ID"
+// @Failure 404 {object} ErrorResponse "Customer or product not found in favorites"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Failed to update favorite"
+// @Router /api/v1/customers/{customer_id}/favorites/{product_id} [patch]
+func (h *FavoriteHandler) UpdateFavorite(c *gin.Context) {
+	customerID, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	productID, err := strconv.Atoi(c.Param("product_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid product ID"})
+		return
+	}
+
+	var req FavoriteUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Println("Error binding request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		log.Println("Error validating request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	favorite, err := h.favoriteService.UpdateFavorite(c.Request.Context(), customerID, productID, model.FavoriteUpdate{
+		Note:     req.Note,
+		Tags:     req.Tags,
+		Priority: req.Priority,
+		Position: req.Position,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidFavorite) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+		case domain.ErrFavoriteNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Product not found in favorites"})
+		default:
+			log.Println("Error updating favorite", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update favorite"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, favorite)
+}
+
+// @Summary Reorder favorites
+// @Description Positions all the customer's favorites at once in the order of the product IDs, which must list every favorite once
+// @Tags Favorite
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param order body FavoriteOrderRequest true "Product IDs in the new order"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {array} FavoriteProductResponse "Reordered favorites"
+// @Failure 400 {object} ErrorResponse "Invalid request data, customer ID or order"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Failed to reorder favorites"
+// @Router /api/v1/customers/{customer_id}/favorites/order [put]
+func (h *FavoriteHandler) Reorder(c *gin.Context) {
+	customerID, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req FavoriteOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Println("Error binding request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		log.Println("Error validating request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	favorites, err := h.favoriteService.Reorder(c.Request.Context(), customerID, req.ProductIDs)
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+		case domain.ErrInvalidFavoriteOrder:
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Order must list every favorite once"})
+		default:
+			log.Println("Error reordering favorites", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to reorder favorites"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, favorites)
+}
+
 // @Summary Remove a product from favorites
 // @Description Removes a product from the customer's list of favorite products
 // @Tags Favorite
@@ -78,12 +396,20 @@ func (h *FavoriteHandler) AddFavorite(c *gin.Context) {
 // @Security BearerAuth
 // @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
 // @Param product_id path int true "Product ID to remove from favorites" example=123
+// @Param Idempotency-Key header string false "Key to safely retry the request"
 // @Success 204 "Product removed from favorites"
-// @Failure 400 {object} ErrorResponse "Invalid product ID"
-// @Failure 404 {object} ErrorResponse "Product not found in favorites"
+// @Failure 400 {object} ErrorResponse "Invalid customer or product ID"
+// @Failure 404 {object} ErrorResponse "Customer or product not found in favorites"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
 // @Failure 500 {object} ErrorResponse "Failed to remove from favorites"
 // @Router /api/v1/customers/{customer_id}/favorites/{product_id} [delete]
 func (h *FavoriteHandler) RemoveFavorite(c *gin.Context) {
+	customerID := c.Param("customer_id")
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
+		return
+	}
+
 	productID := c.Param("product_id")
 
 	if productID == "" {
@@ -98,10 +424,17 @@ func (h *FavoriteHandler) RemoveFavorite(c *gin.Context) {
 		return
 	}
 
-	err = h.favoriteService.RemoveFavorite(c, intProductID)
+	err = h.favoriteService.RemoveFavorite(c, customerID, intProductID)
 	if err != nil {
-		log.Println("Error removing favorite", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to remove favorite"})
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+		case domain.ErrFavoriteNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Product not found in favorites"})
+		default:
+			log.Println("Error removing favorite", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to remove favorite"})
+		}
 		return
 	}
 
@@ -109,25 +442,30 @@ func (h *FavoriteHandler) RemoveFavorite(c *gin.Context) {
 }
 
 // @Summary Get customer's favorite products
-// @Description Retrieves a list of all favorite products for the specified customer
+// @Description Retrieves the favorite products of the specified customer by position, with their notes, tags and priorities
 // @Tags Favorite
 // @Produce json
 // @Security BearerAuth
 // @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
-// @Success 200 {array} Product "List of favorite products"
+// @Param tag query string false "Only favorites with this tag" example="gift"
+// @Success 200 {array} FavoriteProductResponse "List of favorite products"
 // @Failure 400 {object} ErrorResponse "Invalid customer ID"
 // @Failure 404 {object} ErrorResponse "Customer not found"
 // @Failure 500 {object} ErrorResponse "Failed to fetch favorite products"
 // @Router /api/v1/customers/{customer_id}/favorites [get]
 func (h *FavoriteHandler) GetCustomerFavoriteProducts(c *gin.Context) {
 	customerID := c.Param("customer_id")
-	if customerID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Customer ID is required"})
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
 		return
 	}
 
-	products, err := h.favoriteService.GetCustomerFavoriteProducts(c.Request.Context(), customerID)
+	products, err := h.favoriteService.GetCustomerFavoriteProducts(c.Request.Context(), customerID, c.Query("tag"))
 	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+			return
+		}
 		log.Println("Error getting favorite products", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch favorite products"})
 		return
@@ -135,3 +473,91 @@ func (h *FavoriteHandler) GetCustomerFavoriteProducts(c *gin.Context) {
 
 	c.JSON(http.StatusOK, products)
 }
+
+// @Summary Get changes to customer's favorites
+// @Description Returns the products added to and removed from the customer's favorites since the sync token, and the token to use on the next sync. Favorites whose details or position changed are returned as added, with their current state. Without a token every favorite is returned as added. Removed products may include products added and removed after the token. Tokens older than the tombstone retention are rejected with 410, and the client must sync again without a token.
+// @Tags Favorite
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param since query string false "Sync token returned by the previous sync"
+// @Success 200 {object} FavoriteChangesResponse "Changes since the sync token"
+// @Failure 400 {object} ErrorResponse "Invalid customer ID or sync token"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 410 {object} ErrorResponse "Sync token has expired"
+// @Failure 500 {object} ErrorResponse "Failed to fetch favorite changes"
+// @Router /api/v1/customers/{customer_id}/favorites/changes [get]
+func (h *FavoriteHandler) GetChanges(c *gin.Context) {
+	customerID := c.Param("customer_id")
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
+		return
+	}
+
+	var sinceID *int64
+	if token := c.Query("since"); token != "" {
+		id, ok := decodeSyncToken(token)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid sync token"})
+			return
+		}
+		sinceID = &id
+	}
+
+	changes, err := h.favoriteService.GetChanges(c, customerID, sinceID)
+	if err != nil {
+		handleGetChangesError(c, err)
+		return
+	}
+
+	response := FavoriteChangesResponse{
+		Added:     make([]AddedFavoriteResponse, 0, len(changes.Added)),
+		Removed:   make([]RemovedFavoriteResponse, 0, len(changes.Removed)),
+		SyncToken: encodeSyncToken(changes.SyncID),
+	}
+	for _, favorite := range changes.Added {
+		response.Added = append(response.Added, AddedFavoriteResponse{ProductID: favorite.ProductID, AddedAt: favorite.CreatedAt})
+	}
+	for _, favorite := range changes.Removed {
+		response.Removed = append(response.Removed, RemovedFavoriteResponse{ProductID: favorite.ProductID, RemovedAt: favorite.RemovedAt})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func handleGetChangesError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+	case domain.ErrSyncExpired:
+		c.JSON(http.StatusGone, gin.H{"message": "Sync token has expired, sync again without it"})
+	default:
+		log.Println("Error getting favorite changes", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch favorite changes"})
+	}
+}
+
+// syncTokenPrefix versions the sync token format.
+const syncTokenPrefix = "v1:"
+
+func encodeSyncToken(syncID int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(syncTokenPrefix + strconv.FormatInt(syncID, 10)))
+}
+
+func decodeSyncToken(token string) (int64, bool) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, false
+	}
+
+	value, ok := strings.CutPrefix(string(decoded), syncTokenPrefix)
+	if !ok {
+		return 0, false
+	}
+
+	syncID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || syncID < 0 {
+		return 0, false
+	}
+	return syncID, true
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"app/internal/domain"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSyncToken(t *testing.T) {
+	for _, syncID := range []int64{0, 1, 42, 1 << 62} {
+		got, ok := decodeSyncToken(encodeSyncToken(syncID))
+		if !ok || got != syncID {
+			t.Errorf("decodeSyncToken(encodeSyncToken(%d)) = %d, %v, want %d, true", syncID, got, ok, syncID)
+		}
+	}
+
+	encode := func(value string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(value))
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "not base64", token: "v1:42"},
+		{name: "padded base64", token: base64.URLEncoding.EncodeToString([]byte("v1:4"))},
+		{name: "missing version", token: encode("42")},
+		{name: "unknown version", token: encode("v2:42")},
+		{name: "not a number", token: encode("v1:abc")},
+		{name: "negative", token: encode("v1:-1")},
+		{name: "empty ID", token: encode("v1:")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if syncID, ok := decodeSyncToken(tt.token); ok {
+				t.Errorf("decodeSyncToken(%q) = %d, true, want it rejected", tt.token, syncID)
+			}
+		})
+	}
+}
+
+func TestHandleGetChangesError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "customer not found", err: domain.ErrNotFound, wantStatus: http.StatusNotFound},
+		{name: "token behind the sync horizon", err: domain.ErrSyncExpired, wantStatus: http.StatusGone},
+		{name: "unexpected error", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			handleGetChangesError(c, tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
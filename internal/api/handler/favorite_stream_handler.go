@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/service"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type FavoriteStreamHandler struct {
+	favoriteStreamService *service.FavoriteStreamService
+	heartbeatInterval     time.Duration
+}
+
+// FavoriteChangeEventData is the data of added and removed stream events.
+type FavoriteChangeEventData struct {
+	ProductID int       `json:"product_id" example:"123"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+func NewFavoriteStreamHandler(favoriteStreamService *service.FavoriteStreamService, heartbeatInterval time.Duration) *FavoriteStreamHandler {
+	return &FavoriteStreamHandler{
+		favoriteStreamService: favoriteStreamService,
+		heartbeatInterval:     heartbeatInterval,
+	}
+}
+
+// @Summary Stream customer's favorite changes
+// @Description Streams the favorites added to and removed from the customer as Server-Sent Events. Each change is sent as an "added" or "removed" event whose ID can be sent back in the Last-Event-ID header, or the last_event_id parameter, to resume after a reconnection. When the changes after that ID are no longer kept, a "reset" event is sent first and the client should reload the favorites. Comments are sent as heartbeats while idle.
+// @Tags Favorite
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Param last_event_id query string false "ID of the last event received, for clients unable to set headers"
+// @Success 200 {object} FavoriteChangeEventData "Stream of favorite changes"
+// @Failure 400 {object} ErrorResponse "Invalid customer ID or Last-Event-ID"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 500 {object} ErrorResponse "Failed to stream favorites"
+// @Router /api/v1/customers/{customer_id}/favorites/stream [get]
+func (h *FavoriteStreamHandler) Stream(c *gin.Context) {
+	customerID := c.Param("customer_id")
+	if _, err := uuid.Parse(customerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid customer ID"})
+		return
+	}
+
+	lastEventID, ok := parseLastEventID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid Last-Event-ID"})
+		return
+	}
+
+	stream, err := h.favoriteStreamService.Open(c, customerID, lastEventID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+			return
+		}
+		log.Println("Error opening favorite stream", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to stream favorites"})
+		return
+	}
+	defer stream.Close()
+
+	// Streams outlast the server write timeout.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Println("Error extending stream write deadline", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if stream.Reset() {
+		if err := writeServerSentEvent(c, stream.Cursor(), "reset", struct{}{}); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		for {
+			changes, err := stream.Next(c)
+			if err != nil {
+				// The response has started, so the stream just ends and the
+				// client resumes from the last event it received.
+				log.Println("Error reading favorite changes", err)
+				return
+			}
+			if len(changes) == 0 {
+				break
+			}
+
+			for _, change := range changes {
+				data := FavoriteChangeEventData{ProductID: change.ProductID, ChangedAt: change.ChangedAt}
+				if err := writeServerSentEvent(c, change.ID, change.Type, data); err != nil {
+					return
+				}
+			}
+			c.Writer.Flush()
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case _, ok := <-stream.Wake():
+			if !ok {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// parseLastEventID reads the Last-Event-ID header, falling back to the
+// last_event_id parameter. It returns nil when neither is set.
+func parseLastEventID(c *gin.Context) (*int64, bool) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return nil, true
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return nil, false
+	}
+	return &id, true
+}
+
+func writeServerSentEvent(c *gin.Context, id int64, event string, data any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", id, event, encoded)
+	return err
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"app/internal/infra/health"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	checker  *health.Checker
+	draining func() bool
+}
+
+type CheckResponse struct {
+	Name      string  `json:"name" example:"database"`
+	Status    string  `json:"status" example:"ok"`
+	LatencyMs float64 `json:"latency_ms" example:"1.25"`
+	Error     string  `json:"error,omitempty" example:"context deadline exceeded"`
+	CheckedAt string  `json:"checked_at,omitempty" example:"2025-06-19T12:00:00Z"`
+}
+
+type ReadinessResponse struct {
+	Status string          `json:"status" example:"ok"`
+	Checks []CheckResponse `json:"checks,omitempty"`
+	Cached *bool           `json:"cached,omitempty"`
+}
+
+func NewHealthHandler(checker *health.Checker, draining func() bool) *HealthHandler {
+	return &HealthHandler{
+		checker:  checker,
+		draining: draining,
+	}
+}
+
+// @Summary Liveness probe
+// @Description Reports whether the process is running. It does not check any dependency.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} ReadinessResponse "Process is alive"
+// @Router /livez [get]
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, ReadinessResponse{Status: health.StatusOK})
+}
+
+// @Summary Readiness probe
+// @Description Runs the dependency checks (database, product catalog, migrations and background workers) and reports the status and latency of each. Results are cached briefly.
+// @Tags Health
+// @Produce json
+// @Param verbose query bool false "Include errors, timestamps and cache information"
+// @Success 200 {object} ReadinessResponse "Ready to serve traffic"
+// @Failure 503 {object} ReadinessResponse "Not ready or shutting down"
+// @Router /readyz [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	if h.draining() {
+		c.JSON(http.StatusServiceUnavailable, ReadinessResponse{Status: "draining"})
+		return
+	}
+
+	report := h.checker.Run()
+	_, verbose := c.GetQuery("verbose")
+
+	response := ReadinessResponse{Status: report.Status}
+	for _, result := range report.Checks {
+		check := CheckResponse{
+			Name:      result.Name,
+			Status:    result.Status,
+			LatencyMs: float64(result.Latency.Microseconds()) / 1000,
+		}
+		if verbose {
+			check.Error = result.Error
+			check.CheckedAt = result.CheckedAt.UTC().Format(time.RFC3339Nano)
+		}
+		response.Checks = append(response.Checks, check)
+	}
+	if verbose {
+		response.Cached = &report.Cached
+	}
+
+	if !report.Healthy() {
+		c.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var errInvalidMergePatch = errors.New("merge patch must be a JSON object")
+
+// applyMergePatch applies an RFC 7396 JSON Merge Patch to the JSON object
+// target: null removes a member, objects are merged recursively and any other
+// value replaces the member.
+func applyMergePatch(target []byte, patch []byte) ([]byte, error) {
+	var targetDoc map[string]any
+	if err := json.Unmarshal(target, &targetDoc); err != nil {
+		return nil, err
+	}
+
+	var patchDoc map[string]any
+	if err := json.Unmarshal(patch, &patchDoc); err != nil || patchDoc == nil {
+		return nil, errInvalidMergePatch
+	}
+
+	return json.Marshal(mergeObjects(targetDoc, patchDoc))
+}
+
+func mergeObjects(target map[string]any, patch map[string]any) map[string]any {
+	if target == nil {
+		target = make(map[string]any)
+	}
+
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+
+		patchObject, ok := value.(map[string]any)
+		if !ok {
+			target[key] = value
+			continue
+		}
+
+		targetObject, _ := target[key].(map[string]any)
+		target[key] = mergeObjects(targetObject, patchObject)
+	}
+
+	return target
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultPageSize = 20
+
+type PageRequest struct {
+	Page     int
+	PageSize int
+}
+
+// parsePageRequest reads the page and page_size query parameters. It returns
+// false when either is not a positive integer or page_size exceeds maxSize.
+func parsePageRequest(c *gin.Context, maxSize int) (PageRequest, bool) {
+	request := PageRequest{Page: 1, PageSize: defaultPageSize}
+	if request.PageSize > maxSize {
+		request.PageSize = maxSize
+	}
+
+	if raw := c.Query("page"); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			return request, false
+		}
+		request.Page = page
+	}
+
+	if raw := c.Query("page_size"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 1 || size > maxSize {
+			return request, false
+		}
+		request.PageSize = size
+	}
+
+	return request, true
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/domain/service"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type PriceAlertHandler struct {
+	service *service.PriceAlertService
+}
+
+type PriceAlertSettingsRequest struct {
+	Enabled       bool    `json:"enabled" example:"true"`
+	ThresholdType string  `json:"threshold_type" validate:"required,oneof=percent absolute" example:"percent"`
+	Threshold     float64 `json:"threshold" validate:"required,gt=0" example:"15"`
+}
+
+type PriceAlertSettingsResponse struct {
+	CustomerID    string  `json:"customer_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Enabled       bool    `json:"enabled" example:"true"`
+	ThresholdType string  `json:"threshold_type" example:"percent"`
+	Threshold     float64 `json:"threshold" example:"15"`
+	UpdatedAt     string  `json:"updated_at" example:"2025-06-19T12:00:00Z"`
+}
+
+type PriceAlertResponse struct {
+	ID             int64   `json:"id" example:"42"`
+	CustomerID     string  `json:"customer_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ProductID      int     `json:"product_id" example:"1"`
+	ReferencePrice float64 `json:"reference_price" example:"109.95"`
+	Price          float64 `json:"price" example:"89.95"`
+	CreatedAt      string  `json:"created_at" example:"2025-06-19T12:00:00Z"`
+	NotifiedAt     string  `json:"notified_at,omitempty" example:"2025-06-19T12:00:01Z"`
+}
+
+type ProductPriceResponse struct {
+	ProductID  int     `json:"product_id" example:"1"`
+	Price      float64 `json:"price" example:"109.95"`
+	RecordedAt string  `json:"recorded_at" example:"2025-06-19T12:00:00Z"`
+}
+
+func NewPriceAlertHandler(service *service.PriceAlertService) *PriceAlertHandler {
+	return &PriceAlertHandler{
+		service: service,
+	}
+}
+
+// @Summary Get price alert settings
+// @Description Retrieves when the customer is alerted of price drops on its favorites. Customers who never set them up get disabled settings.
+// @Tags Price Alert
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Success 200 {object} PriceAlertSettingsResponse "Price alert settings"
+// @Failure 400 {object} ErrorResponse "Invalid customer ID"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/price-alerts/settings [get]
+func (h *PriceAlertHandler) GetSettings(c *gin.Context) {
+	customerID, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.service.GetSettings(c.Request.Context(), customerID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+			return
+		}
+		log.Println("Error fetching price alert settings", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch price alert settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// @Summary Update price alert settings
+// @Description Sets when the customer is alerted of price drops on its favorites: when the price falls by the threshold, in percent or in currency, from the highest price since the product was favorited or last alerted
+// @Tags Price Alert
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param settings body PriceAlertSettingsRequest true "Price alert settings"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {object} PriceAlertSettingsResponse "Updated price alert settings"
+// @Failure 400 {object} ErrorResponse "Invalid request data, threshold or customer ID"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/price-alerts/settings [put]
+func (h *PriceAlertHandler) UpdateSettings(c *gin.Context) {
+	customerID, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var request PriceAlertSettingsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Error binding request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		log.Println("Error validating request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(c.Request.Context(), model.PriceAlertSettings{
+		CustomerID:    customerID,
+		Enabled:       request.Enabled,
+		ThresholdType: request.ThresholdType,
+		Threshold:     request.Threshold,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPriceAlert) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+			return
+		}
+		log.Println("Error updating price alert settings", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update price alert settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// @Summary Get price alerts
+// @Description Retrieves the price drops the customer was alerted of, newest first
+// @Tags Price Alert
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Success 200 {array} PriceAlertResponse "List of price alerts"
+// @Failure 400 {object} ErrorResponse "Invalid customer ID"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/price-alerts [get]
+func (h *PriceAlertHandler) GetAlerts(c *gin.Context) {
+	customerID, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	alerts, err := h.service.GetAlerts(c.Request.Context(), customerID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+			return
+		}
+		log.Println("Error fetching price alerts", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch price alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+// @Summary Get price history
+// @Description Retrieves the prices of the product read from the catalog, oldest first. A price is recorded only when it changes, and holds until the next one.
+// @Tags Price Alert
+// @Produce json
+// @Security BearerAuth
+// @Param product_id path int true "Product ID" example=1
+// @Success 200 {array} ProductPriceResponse "Price history"
+// @Failure 400 {object} ErrorResponse "Invalid product ID"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/products/{product_id}/prices [get]
+func (h *PriceAlertHandler) GetPriceHistory(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("product_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid product ID"})
+		return
+	}
+
+	prices, err := h.service.GetPriceHistory(c.Request.Context(), productID)
+	if err != nil {
+		log.Println("Error fetching price history", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch price history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prices)
+}
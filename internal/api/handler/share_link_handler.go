@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/domain/service"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+type ShareLinkHandler struct {
+	service     *service.ShareLinkService
+	cacheMaxAge time.Duration
+}
+
+type ShareLinkCreateRequest struct {
+	CollectionID string     `json:"collection_id,omitempty" validate:"omitempty,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty" example:"2025-12-31T23:59:59Z"`
+}
+
+type ShareLinkResponse struct {
+	ID           string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CustomerID   string `json:"customer_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CollectionID string `json:"collection_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Token        string `json:"token,omitempty" example:"q3J5o2cQm0QH4m4n9m2u7w2b1b8JtYx1v0Z8m3m5c2E"`
+	CreatedBy    string `json:"created_by" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ExpiresAt    string `json:"expires_at,omitempty" example:"2025-12-31T23:59:59Z"`
+	RevokedAt    string `json:"revoked_at,omitempty" example:"2025-06-20T12:00:00Z"`
+	Views        int64  `json:"views" example:"12"`
+	LastViewedAt string `json:"last_viewed_at,omitempty" example:"2025-06-19T18:00:00Z"`
+	CreatedAt    string `json:"created_at" example:"2025-06-19T12:00:00Z"`
+}
+
+type SharedProductResponse struct {
+	ID       int     `json:"id" example:"1"`
+	Title    string  `json:"title" example:"Fjallraven Backpack"`
+	Category string  `json:"category" example:"men's clothing"`
+	Image    string  `json:"image" example:"https://fakestoreapi.com/img/81fPKd-2AYL._AC_SL1500_.jpg"`
+	Price    float64 `json:"price" example:"109.95"`
+}
+
+type SharedFavoritesResponse struct {
+	Name     string                  `json:"name,omitempty" example:"Birthday ideas"`
+	Products []SharedProductResponse `json:"products"`
+}
+
+func NewShareLinkHandler(service *service.ShareLinkService, cacheMaxAge time.Duration) *ShareLinkHandler {
+	return &ShareLinkHandler{
+		service:     service,
+		cacheMaxAge: cacheMaxAge,
+	}
+}
+
+// @Summary Create share link
+// @Description Generates an unguessable token giving access to the customer's favorites, or to one of its public collections, at /shared/{token} without credentials. The token is only returned here.
+// @Tags Share Link
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param link body ShareLinkCreateRequest true "What to share and until when"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 201 {object} ShareLinkResponse "Created share link, with its token"
+// @Header 201 {string} Location "URL of the created share link"
+// @Failure 400 {object} ErrorResponse "Invalid request data or customer ID"
+// @Failure 404 {object} ErrorResponse "Customer or collection not found"
+// @Failure 409 {object} ErrorResponse "Collection is private"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/share-links [post]
+func (h *ShareLinkHandler) Create(c *gin.Context) {
+	customerID, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var request ShareLinkCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Error binding request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		log.Println("Error validating request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return
+	}
+
+	link, err := h.service.Create(c.Request.Context(), model.ShareLink{
+		CustomerID:   customerID,
+		CollectionID: request.CollectionID,
+		CreatedBy:    c.GetString("userID"),
+		ExpiresAt:    request.ExpiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidShareLink) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer or collection not found"})
+		case domain.ErrCollectionPrivate:
+			c.JSON(http.StatusConflict, gin.H{"message": "Collection is private, make it public to share it"})
+		default:
+			log.Println("Error creating share link", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create share link"})
+		}
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path+"/"+link.ID)
+	c.JSON(http.StatusCreated, link)
+}
+
+// @Summary Get share links
+// @Description Retrieves the customer's share links with their view counts, revoked and expired ones included. Tokens are not returned.
+// @Tags Share Link
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Success 200 {array} ShareLinkResponse "List of share links"
+// @Failure 400 {object} ErrorResponse "Invalid customer ID"
+// @Failure 404 {object} ErrorResponse "Customer not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/share-links [get]
+func (h *ShareLinkHandler) GetAll(c *gin.Context) {
+	customerID, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	links, err := h.service.GetByCustomerID(c.Request.Context(), customerID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Customer not found"})
+			return
+		}
+		log.Println("Error fetching share links", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch share links"})
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// @Summary Revoke share link
+// @Description Disables a share link for good. Revoking a revoked link does nothing.
+// @Tags Share Link
+// @Security BearerAuth
+// @Param customer_id path string true "Customer ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param share_link_id path string true "Share link ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 204 "No content"
+// @Failure 400 {object} ErrorResponse "Invalid customer or share link ID"
+// @Failure 404 {object} ErrorResponse "Customer or share link not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/customers/{customer_id}/share-links/{share_link_id} [delete]
+func (h *ShareLinkHandler) Revoke(c *gin.Context) {
+	customerID, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	shareLinkID := c.Param("share_link_id")
+	if _, err := uuid.Parse(shareLinkID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid share link ID"})
+		return
+	}
+
+	if err := h.service.Revoke(c.Request.Context(), customerID, shareLinkID); err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Share link not found"})
+			return
+		}
+		log.Println("Error revoking share link", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to revoke share link"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary View shared favorites
+// @Description Returns the products shared by a link, with their prices and images, without credentials. Responses can be cached for a short time and carry an ETag for conditional requests.
+// @Tags Share Link
+// @Produce json
+// @Param token path string true "Share token"
+// @Param If-None-Match header string false "ETag of a cached response"
+// @Success 200 {object} SharedFavoritesResponse "Shared products"
+// @Success 304 "Not modified"
+// @Header 200 {string} ETag "Version of the shared products"
+// @Failure 404 {object} ErrorResponse "Link not found, revoked or expired"
+// @Failure 429 {object} ErrorResponse "Too many requests"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /shared/{token} [get]
+func (h *ShareLinkHandler) View(c *gin.Context) {
+	shared, link, err := h.service.View(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.Header("Cache-Control", "no-store")
+			c.JSON(http.StatusNotFound, gin.H{"message": "Shared favorites not found"})
+			return
+		}
+		log.Println("Error viewing shared favorites", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch shared favorites"})
+		return
+	}
+
+	body, err := json.Marshal(shared)
+	if err != nil {
+		log.Println("Error encoding shared favorites", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch shared favorites"})
+		return
+	}
+
+	// Caches must not outlive the link.
+	maxAge := h.cacheMaxAge
+	if link.ExpiresAt != nil {
+		maxAge = min(maxAge, time.Until(*link.ExpiresAt))
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(max(maxAge, 0).Seconds())))
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
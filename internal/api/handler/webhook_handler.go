@@ -0,0 +1,397 @@
+package handler
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/domain/service"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const maxWebhookDeliveriesPageSize = 100
+
+type WebhookHandler struct {
+	service *service.WebhookService
+}
+
+type WebhookSubscriptionRequest struct {
+	URL        string   `json:"url" validate:"required" example:"https://partner.example.com/hooks/favorites"`
+	EventTypes []string `json:"event_types" validate:"required,min=1" example:"favorite.added,favorite.removed"`
+	// Secret signs the deliveries. One is generated when omitted on creation
+	// and the current one is kept when omitted on update.
+	Secret string `json:"secret,omitempty" validate:"omitempty,min=16,max=200" example:"a-long-random-shared-secret"`
+}
+
+type WebhookSubscriptionResponse struct {
+	ID         string   `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	URL        string   `json:"url" example:"https://partner.example.com/hooks/favorites"`
+	EventTypes []string `json:"event_types" example:"favorite.added,favorite.removed"`
+	// Secret is only returned when the subscription is created.
+	Secret    string `json:"secret,omitempty" example:"whsec_9f86d081884c7d659a2feaa0c55ad015"`
+	CreatedBy string `json:"created_by" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CreatedAt string `json:"created_at" example:"2025-06-19T12:00:00Z"`
+	UpdatedAt string `json:"updated_at" example:"2025-06-19T12:00:00Z"`
+}
+
+type WebhookDeliveryAttemptResponse struct {
+	Attempt    int    `json:"attempt" example:"1"`
+	StatusCode int    `json:"status_code,omitempty" example:"503"`
+	Error      string `json:"error,omitempty" example:"receiver returned status 503"`
+	DurationMs int    `json:"duration_ms" example:"120"`
+	CreatedAt  string `json:"created_at" example:"2025-06-19T12:00:00Z"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string                           `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	SubscriptionID string                           `json:"subscription_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	EventID        string                           `json:"event_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	EventType      string                           `json:"event_type" example:"favorite.added"`
+	Status         string                           `json:"status" example:"pending" enums:"pending,succeeded,dead"`
+	Attempts       int                              `json:"attempts" example:"2"`
+	NextAttemptAt  string                           `json:"next_attempt_at,omitempty" example:"2025-06-19T12:01:00Z"`
+	LastStatusCode int                              `json:"last_status_code,omitempty" example:"503"`
+	LastError      string                           `json:"last_error,omitempty" example:"receiver returned status 503"`
+	CreatedAt      string                           `json:"created_at" example:"2025-06-19T12:00:00Z"`
+	FinishedAt     string                           `json:"finished_at,omitempty" example:"2025-06-19T12:00:00Z"`
+	Payload        map[string]any                   `json:"payload,omitempty"`
+	AttemptLog     []WebhookDeliveryAttemptResponse `json:"attempt_log,omitempty"`
+}
+
+type WebhookDeliveryPageResponse struct {
+	Items    []WebhookDeliveryResponse `json:"items"`
+	Total    int                       `json:"total" example:"42"`
+	Page     int                       `json:"page" example:"1"`
+	PageSize int                       `json:"page_size" example:"20"`
+}
+
+func NewWebhookHandler(service *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// @Summary Create webhook subscription
+// @Description Subscribes a URL to domain events. Each delivery is a POST of the event as JSON with the headers X-Webhook-ID, X-Webhook-Timestamp, X-Event-ID, X-Event-Type and X-Webhook-Signature, which is sha256= followed by the hex HMAC-SHA256, keyed by the secret, of the timestamp, a dot and the body. Requires the admin role.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param subscription body WebhookSubscriptionRequest true "Subscription"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 201 {object} WebhookSubscriptionResponse "Created subscription, with its secret"
+// @Header 201 {string} Location "URL of the created subscription"
+// @Failure 400 {object} ErrorResponse "Invalid request data"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	request, ok := bindWebhookRequest(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.service.Create(c.Request.Context(), model.WebhookSubscription{
+		URL:        request.URL,
+		EventTypes: request.EventTypes,
+		Secret:     request.Secret,
+		CreatedBy:  c.GetString("userID"),
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidWebhook) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		log.Println("Error creating webhook subscription", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create webhook subscription"})
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path+"/"+subscription.ID)
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// @Summary Get webhook subscriptions
+// @Description Retrieves the webhook subscriptions, without their secrets. Requires the admin role.
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} WebhookSubscriptionResponse "List of subscriptions"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks [get]
+func (h *WebhookHandler) GetAll(c *gin.Context) {
+	subscriptions, err := h.service.GetAll(c.Request.Context())
+	if err != nil {
+		log.Println("Error fetching webhook subscriptions", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch webhook subscriptions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// @Summary Get webhook subscription by ID
+// @Description Retrieves a webhook subscription, without its secret. Requires the admin role.
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param webhook_id path string true "Subscription ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Success 200 {object} WebhookSubscriptionResponse "Subscription"
+// @Failure 400 {object} ErrorResponse "Invalid subscription ID format"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} ErrorResponse "Subscription not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks/{webhook_id} [get]
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	webhookID, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.service.GetByID(c.Request.Context(), webhookID)
+	if err != nil {
+		h.handleError(c, err, "Error fetching webhook subscription", "Failed to fetch webhook subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// @Summary Update webhook subscription
+// @Description Replaces the URL and event types of a subscription, and its secret when given. Requires the admin role.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param webhook_id path string true "Subscription ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param subscription body WebhookSubscriptionRequest true "Subscription"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {object} WebhookSubscriptionResponse "Updated subscription"
+// @Failure 400 {object} ErrorResponse "Invalid request data or subscription ID"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} ErrorResponse "Subscription not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks/{webhook_id} [put]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	webhookID, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	request, ok := bindWebhookRequest(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.service.Update(c.Request.Context(), model.WebhookSubscription{
+		ID:         webhookID,
+		URL:        request.URL,
+		EventTypes: request.EventTypes,
+		Secret:     request.Secret,
+	})
+	if err != nil {
+		h.handleError(c, err, "Error updating webhook subscription", "Failed to update webhook subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// @Summary Delete webhook subscription
+// @Description Deletes a subscription along with its deliveries. Requires the admin role.
+// @Tags Webhook
+// @Security BearerAuth
+// @Param webhook_id path string true "Subscription ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 204 "No content"
+// @Failure 400 {object} ErrorResponse "Invalid subscription ID format"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} ErrorResponse "Subscription not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks/{webhook_id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	webhookID, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), webhookID); err != nil {
+		h.handleError(c, err, "Error deleting webhook subscription", "Failed to delete webhook subscription")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get webhook deliveries
+// @Description Retrieves the delivery history of a subscription, newest first. Requires the admin role.
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param webhook_id path string true "Subscription ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param status query string false "Only deliveries with this status" Enums(pending, succeeded, dead)
+// @Param page query int false "Page number, starting at 1" example=1
+// @Param page_size query int false "Deliveries per page" example=20
+// @Success 200 {object} WebhookDeliveryPageResponse "Deliveries"
+// @Failure 400 {object} ErrorResponse "Invalid subscription ID, status or pagination"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} ErrorResponse "Subscription not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks/{webhook_id}/deliveries [get]
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	webhookID, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", model.WebhookDeliveryPending, model.WebhookDeliverySucceeded, model.WebhookDeliveryDead:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid status, use pending, succeeded or dead"})
+		return
+	}
+
+	pageRequest, ok := parsePageRequest(c, maxWebhookDeliveriesPageSize)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid pagination parameters"})
+		return
+	}
+
+	page, err := h.service.Deliveries(c.Request.Context(), webhookID, status, pageRequest.Page, pageRequest.PageSize)
+	if err != nil {
+		h.handleError(c, err, "Error fetching webhook deliveries", "Failed to fetch webhook deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary Get webhook delivery
+// @Description Retrieves a delivery with the event sent and the log of its attempts. Requires the admin role.
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param webhook_id path string true "Subscription ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param delivery_id path string true "Delivery ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Success 200 {object} WebhookDeliveryResponse "Delivery"
+// @Failure 400 {object} ErrorResponse "Invalid subscription or delivery ID"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} ErrorResponse "Delivery not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks/{webhook_id}/deliveries/{delivery_id} [get]
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	webhookID, deliveryID, ok := webhookDeliveryParams(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.service.GetDelivery(c.Request.Context(), webhookID, deliveryID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Delivery not found"})
+			return
+		}
+		log.Println("Error fetching webhook delivery", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch webhook delivery"})
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// @Summary Redeliver webhook
+// @Description Schedules a delivery to be sent again right away, whatever its status. A dead-lettered delivery gets a single new attempt. Requires the admin role.
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param webhook_id path string true "Subscription ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param delivery_id path string true "Delivery ID" example="550e8400-e29b-41d4-a716-446655440000"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 202 {object} WebhookDeliveryResponse "Scheduled delivery"
+// @Failure 400 {object} ErrorResponse "Invalid subscription or delivery ID"
+// @Failure 403 {object} ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} ErrorResponse "Delivery not found"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	webhookID, deliveryID, ok := webhookDeliveryParams(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.service.Redeliver(c.Request.Context(), webhookID, deliveryID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Delivery not found"})
+			return
+		}
+		log.Println("Error scheduling webhook redelivery", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to redeliver webhook"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+func (h *WebhookHandler) handleError(c *gin.Context, err error, logMessage string, message string) {
+	if errors.Is(err, domain.ErrInvalidWebhook) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if err == domain.ErrNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Subscription not found"})
+		return
+	}
+
+	log.Println(logMessage, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"message": message})
+}
+
+func bindWebhookRequest(c *gin.Context) (WebhookSubscriptionRequest, bool) {
+	var request WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Error binding request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return request, false
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		log.Println("Error validating request data", err)
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+		return request, false
+	}
+
+	return request, true
+}
+
+func webhookIDParam(c *gin.Context) (string, bool) {
+	webhookID := c.Param("webhook_id")
+	if _, err := uuid.Parse(webhookID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid subscription ID"})
+		return "", false
+	}
+	return webhookID, true
+}
+
+func webhookDeliveryParams(c *gin.Context) (string, string, bool) {
+	webhookID, ok := webhookIDParam(c)
+	if !ok {
+		return "", "", false
+	}
+
+	deliveryID := c.Param("delivery_id")
+	if _, err := uuid.Parse(deliveryID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid delivery ID"})
+		return "", "", false
+	}
+	return webhookID, deliveryID, true
+}
@@ -19,7 +19,7 @@ func AuthMiddleware(tokenService service.TokenService) gin.HandlerFunc {
 			return
 		}
 
-		userID, err := tokenService.Validate(token)
+		claims, err := tokenService.Validate(token)
 		if err != nil {
 			log.Println("Error validating token", err)
 			c.AbortWithStatusJSON(
@@ -29,7 +29,24 @@ func AuthMiddleware(tokenService service.TokenService) gin.HandlerFunc {
 			return
 		}
 
-		c.Set("userID", userID)
+		c.Set("userID", claims.UserID)
+		c.Set("role", claims.Role)
+		c.Next()
+	}
+}
+
+// RequireRole rejects requests whose token does not carry the given role. It
+// must run after AuthMiddleware.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != role {
+			c.AbortWithStatusJSON(
+				http.StatusForbidden,
+				gin.H{"message": "Insufficient permissions"},
+			)
+			return
+		}
+
 		c.Next()
 	}
 }
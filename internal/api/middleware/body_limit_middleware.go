@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimitMiddleware rejects request bodies larger than maxBytes. Routes in
+// overrides, keyed by their full path, use their own limit instead.
+func BodyLimitMiddleware(maxBytes int64, overrides map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		maxBytes := maxBytes
+		if limit, ok := overrides[c.FullPath()]; ok {
+			maxBytes = limit
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(
+				http.StatusRequestEntityTooLarge,
+				gin.H{"message": "Request body too large"},
+			)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
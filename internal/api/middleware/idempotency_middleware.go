@@ -0,0 +1,215 @@
+package middleware
+
+import (
+	"app/internal/domain/model"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// replayedHeaders are the response headers stored alongside the body and
+// sent again when a request is replayed.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// IdempotencyStore keeps the reserved idempotency keys and their responses.
+// It is implemented by db.IdempotencyRepository.
+type IdempotencyStore interface {
+	Create(c context.Context, key model.IdempotencyKey) (bool, error)
+	Find(c context.Context, scope string, key string) (*model.IdempotencyKey, error)
+	Complete(c context.Context, key model.IdempotencyKey) error
+	Delete(c context.Context, scope string, key string) error
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware makes mutating requests carrying an Idempotency-Key
+// header safe to retry: the first response is stored for ttl and replayed for
+// retries with the same key and body. Transient failures, such as 429 and 5xx
+// responses, a panic or a failure to store the response, release the key so
+// the request can be retried. Keys
+// are scoped per authenticated user, so it must run after AuthMiddleware.
+func IdempotencyMiddleware(repo IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader("Idempotency-Key")
+		if idempotencyKey == "" || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Invalid Idempotency-Key header"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"message": "Request body too large"})
+				return
+			}
+			log.Println("Error reading request body", err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Invalid request data"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key := model.IdempotencyKey{
+			Scope:       idempotencyScope(c),
+			Key:         idempotencyKey,
+			Fingerprint: requestFingerprint(c.Request, body),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+
+		created, err := repo.Create(c.Request.Context(), key)
+		if err != nil {
+			log.Println("Error creating idempotency key", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "Failed to process request"})
+			return
+		}
+
+		if !created {
+			replayIdempotentResponse(c, repo, key)
+			return
+		}
+
+		// The response is already sent; storing it must not depend on the
+		// client still being connected.
+		ctx := context.WithoutCancel(c.Request.Context())
+		release := func() {
+			if err := repo.Delete(ctx, key.Scope, key.Key); err != nil {
+				log.Println("Error releasing idempotency key", err)
+			}
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				release()
+				panic(r)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		status := recorder.Status()
+		if isTransientStatus(status) {
+			release()
+			return
+		}
+
+		key.ResponseStatus = status
+		key.ResponseBody = recorder.body.Bytes()
+		key.ResponseHeaders = make(map[string]string)
+		for _, header := range replayedHeaders {
+			if value := recorder.Header().Get(header); value != "" {
+				key.ResponseHeaders[header] = value
+			}
+		}
+
+		// A key left reserved without a response would answer every retry
+		// with 409 until it expires, so it is released instead.
+		if err := repo.Complete(ctx, key); err != nil {
+			log.Println("Error storing idempotent response", err)
+			release()
+		}
+	}
+}
+
+func replayIdempotentResponse(c *gin.Context, repo IdempotencyStore, key model.IdempotencyKey) {
+	stored, err := repo.Find(c.Request.Context(), key.Scope, key.Key)
+	if err != nil {
+		log.Println("Error fetching idempotency key", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "Failed to process request"})
+		return
+	}
+
+	if stored == nil {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "Request with this Idempotency-Key is being processed"})
+		return
+	}
+
+	if stored.Fingerprint != key.Fingerprint {
+		c.AbortWithStatusJSON(
+			http.StatusUnprocessableEntity,
+			gin.H{"message": "Idempotency-Key was already used with a different request"},
+		)
+		return
+	}
+
+	if !stored.Completed() {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "Request with this Idempotency-Key is being processed"})
+		return
+	}
+
+	for header, value := range stored.ResponseHeaders {
+		c.Header(header, value)
+	}
+	c.Header("Idempotent-Replayed", "true")
+
+	c.Status(stored.ResponseStatus)
+	if len(stored.ResponseBody) > 0 {
+		c.Writer.Write(stored.ResponseBody)
+	}
+	c.Abort()
+}
+
+func idempotencyScope(c *gin.Context) string {
+	if userID := c.GetString("userID"); userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(r.URL.RequestURI()))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// isTransientStatus tells whether a response may differ when the request is
+// retried, in which case it is not replayed.
+func isTransientStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	}
+	return status >= http.StatusInternalServerError
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
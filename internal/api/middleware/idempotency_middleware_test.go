@@ -0,0 +1,300 @@
+package middleware
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryIdempotencyStore keeps idempotency keys in memory, failing Complete
+// when completeErr is set.
+type memoryIdempotencyStore struct {
+	mu          sync.Mutex
+	keys        map[string]model.IdempotencyKey
+	completeErr error
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{keys: make(map[string]model.IdempotencyKey)}
+}
+
+func (s *memoryIdempotencyStore) Create(c context.Context, key model.IdempotencyKey) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[key.Scope+"|"+key.Key]; ok {
+		return false, nil
+	}
+	s.keys[key.Scope+"|"+key.Key] = key
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) Find(c context.Context, scope string, key string) (*model.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.keys[scope+"|"+key]
+	if !ok {
+		return nil, nil
+	}
+	return &stored, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(c context.Context, key model.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.completeErr != nil {
+		return s.completeErr
+	}
+	s.keys[key.Scope+"|"+key.Key] = key
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Delete(c context.Context, scope string, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, scope+"|"+key)
+	return nil
+}
+
+// newIdempotentRouter serves POST /customers through the middleware, calling
+// handler for every request that reaches it.
+func newIdempotentRouter(store IdempotencyStore, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(IdempotencyMiddleware(store, time.Hour))
+	router.POST("/customers", handler)
+	return router
+}
+
+func idempotentRequest(router *gin.Engine, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(body))
+	req.RemoteAddr = "203.0.113.7:4000"
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddlewareReleasesKeyWhenResponseIsNotStored(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	store.completeErr = errors.New("connection refused")
+
+	calls := 0
+	router := newIdempotentRouter(store, func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+
+	if w := idempotentRequest(router, "key-1", `{"name":"a"}`); w.Code != http.StatusCreated {
+		t.Fatalf("first request status = %d, want %d", w.Code, http.StatusCreated)
+	}
+
+	store.completeErr = nil
+	if w := idempotentRequest(router, "key-1", `{"name":"a"}`); w.Code != http.StatusCreated {
+		t.Errorf("retry status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2", calls)
+	}
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	fingerprint := func(method string, target string, body string) string {
+		return requestFingerprint(httptest.NewRequest(method, target, nil), []byte(body))
+	}
+
+	base := fingerprint(http.MethodPost, "/customers", `{"name":"a"}`)
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		wantSame bool
+	}{
+		{name: "same request", method: http.MethodPost, target: "/customers", body: `{"name":"a"}`, wantSame: true},
+		{name: "other body", method: http.MethodPost, target: "/customers", body: `{"name":"b"}`},
+		{name: "other method", method: http.MethodPut, target: "/customers", body: `{"name":"a"}`},
+		{name: "other path", method: http.MethodPost, target: "/customers/1", body: `{"name":"a"}`},
+		{name: "other query", method: http.MethodPost, target: "/customers?dry_run=true", body: `{"name":"a"}`},
+		{name: "path and body boundary", method: http.MethodPost, target: "/customers{", body: `"name":"a"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fingerprint(tt.method, tt.target, tt.body)
+			if (got == base) != tt.wantSame {
+				t.Errorf("fingerprint equal to the original = %v, want %v", got == base, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	type request struct {
+		key          string
+		body         string
+		wantStatus   int
+		wantReplayed bool
+	}
+
+	tests := []struct {
+		name      string
+		status    int
+		requests  []request
+		wantCalls int
+	}{
+		{
+			name:   "retry is replayed",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: "key-1", body: `{"name":"a"}`, wantStatus: http.StatusCreated},
+				{key: "key-1", body: `{"name":"a"}`, wantStatus: http.StatusCreated, wantReplayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "key reused with another body",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: "key-1", body: `{"name":"a"}`, wantStatus: http.StatusCreated},
+				{key: "key-1", body: `{"name":"b"}`, wantStatus: http.StatusUnprocessableEntity},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "different keys",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: "key-1", body: `{"name":"a"}`, wantStatus: http.StatusCreated},
+				{key: "key-2", body: `{"name":"a"}`, wantStatus: http.StatusCreated},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:   "client errors are replayed",
+			status: http.StatusBadRequest,
+			requests: []request{
+				{key: "key-1", body: `{}`, wantStatus: http.StatusBadRequest},
+				{key: "key-1", body: `{}`, wantStatus: http.StatusBadRequest, wantReplayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "transient errors release the key",
+			status: http.StatusServiceUnavailable,
+			requests: []request{
+				{key: "key-1", body: `{}`, wantStatus: http.StatusServiceUnavailable},
+				{key: "key-1", body: `{}`, wantStatus: http.StatusServiceUnavailable},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:   "too long key",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: strings.Repeat("k", maxIdempotencyKeyLength+1), body: `{}`, wantStatus: http.StatusBadRequest},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			router := newIdempotentRouter(newMemoryIdempotencyStore(), func(c *gin.Context) {
+				calls++
+				c.JSON(tt.status, gin.H{"call": calls})
+			})
+
+			var first string
+			for i, req := range tt.requests {
+				w := idempotentRequest(router, req.key, req.body)
+				if w.Code != req.wantStatus {
+					t.Errorf("request %d status = %d, want %d", i+1, w.Code, req.wantStatus)
+				}
+				if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != req.wantReplayed {
+					t.Errorf("request %d replayed = %v, want %v", i+1, replayed, req.wantReplayed)
+				}
+				if i == 0 {
+					first = w.Body.String()
+				} else if req.wantReplayed && w.Body.String() != first {
+					t.Errorf("request %d body = %s, want the original %s", i+1, w.Body.String(), first)
+				}
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareRejectsConcurrentRetries(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	router := newIdempotentRouter(store, func(c *gin.Context) {
+		close(started)
+		<-finish
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+
+	done := make(chan int)
+	go func() {
+		done <- idempotentRequest(router, "key-1", `{"name":"a"}`).Code
+	}()
+	<-started
+
+	if w := idempotentRequest(router, "key-1", `{"name":"a"}`); w.Code != http.StatusConflict {
+		t.Errorf("retry while processing status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	if w := idempotentRequest(router, "key-1", `{"name":"b"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reuse while processing status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+
+	close(finish)
+	if code := <-done; code != http.StatusCreated {
+		t.Errorf("original request status = %d, want %d", code, http.StatusCreated)
+	}
+
+	w := idempotentRequest(router, "key-1", `{"name":"a"}`)
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry after completion status = %d, replayed = %q, want a replayed %d",
+			w.Code, w.Header().Get("Idempotent-Replayed"), http.StatusCreated)
+	}
+}
+
+func TestIdempotencyMiddlewareReleasesKeyOnPanic(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	panics := true
+	router := newIdempotentRouter(store, func(c *gin.Context) {
+		if panics {
+			panic("boom")
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+	router.Use(gin.Recovery())
+
+	func() {
+		defer func() { recover() }()
+		idempotentRequest(router, "key-1", `{}`)
+	}()
+
+	panics = false
+	if w := idempotentRequest(router, "key-1", `{}`); w.Code != http.StatusCreated {
+		t.Errorf("retry after panic status = %d, want %d", w.Code, http.StatusCreated)
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"app/internal/infra/ratelimit"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitMiddleware limits requests per client within the given scope.
+// Clients are identified by authenticated user ID, then IP, so it must run
+// after AuthMiddleware on authenticated groups. Unauthenticated credentials,
+// such as an X-API-Key header, are ignored: clients could send a new one on
+// every request to get a fresh bucket.
+func RateLimitMiddleware(store ratelimit.Store, scope string, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := scope + ":" + rateLimitClientKey(c)
+
+		result, err := store.Allow(c.Request.Context(), key, limit)
+		if err != nil {
+			log.Println("Error checking rate limit", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", durationToSeconds(result.ResetIn))
+
+		if !result.Allowed {
+			c.Header("Retry-After", durationToSeconds(result.RetryIn))
+			c.AbortWithStatusJSON(
+				http.StatusTooManyRequests,
+				gin.H{"message": "Too many requests"},
+			)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func rateLimitClientKey(c *gin.Context) string {
+	if userID := c.GetString("userID"); userID != "" {
+		return "user:" + userID
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+func durationToSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"app/internal/infra/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newRateLimitedRouter(t *testing.T, trustedProxies []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatal(err)
+	}
+
+	limit := ratelimit.Limit{Requests: 1, Window: time.Hour, Burst: 1}
+	router.GET("/signin", RateLimitMiddleware(ratelimit.NewMemoryStore(), "auth", limit), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func rateLimitedRequest(router *gin.Engine, remoteAddr string, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodGet, "/signin", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRateLimitMiddlewareIgnoresForwardedForFromUntrustedPeers(t *testing.T) {
+	router := newRateLimitedRouter(t, nil)
+
+	if code := rateLimitedRequest(router, "203.0.113.7:4000", "198.51.100.1"); code != http.StatusOK {
+		t.Fatalf("first request status = %d, want %d", code, http.StatusOK)
+	}
+	if code := rateLimitedRequest(router, "203.0.113.7:4001", "198.51.100.2"); code != http.StatusTooManyRequests {
+		t.Errorf("request with a new X-Forwarded-For status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+}
+
+func TestRateLimitMiddlewareUsesForwardedForFromTrustedProxies(t *testing.T) {
+	router := newRateLimitedRouter(t, []string{"10.0.0.0/8"})
+
+	if code := rateLimitedRequest(router, "10.0.0.5:4000", "198.51.100.1"); code != http.StatusOK {
+		t.Fatalf("first client status = %d, want %d", code, http.StatusOK)
+	}
+	if code := rateLimitedRequest(router, "10.0.0.5:4001", "198.51.100.2"); code != http.StatusOK {
+		t.Errorf("second client status = %d, want %d", code, http.StatusOK)
+	}
+	if code := rateLimitedRequest(router, "10.0.0.5:4002", "198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Errorf("first client again status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+}
+
+func TestRateLimitMiddlewareKeysAuthenticatedUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", c.GetHeader("X-Test-User"))
+	})
+	limit := ratelimit.Limit{Requests: 1, Window: time.Hour, Burst: 1}
+	router.GET("/customers", RateLimitMiddleware(ratelimit.NewMemoryStore(), "api", limit), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(user string) int {
+		req := httptest.NewRequest(http.MethodGet, "/customers", nil)
+		req.RemoteAddr = "203.0.113.7:4000"
+		req.Header.Set("X-Test-User", user)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := request("user-1"); code != http.StatusOK {
+		t.Fatalf("user-1 status = %d, want %d", code, http.StatusOK)
+	}
+	if code := request("user-2"); code != http.StatusOK {
+		t.Errorf("user-2 from the same IP status = %d, want %d", code, http.StatusOK)
+	}
+	if code := request("user-1"); code != http.StatusTooManyRequests {
+		t.Errorf("user-1 again status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+}
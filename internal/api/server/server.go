@@ -0,0 +1,75 @@
+package server
+
+import (
+	"app/internal/config"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+type Server struct {
+	httpServer      *http.Server
+	shutdownDelay   time.Duration
+	shutdownTimeout time.Duration
+	draining        atomic.Bool
+}
+
+func New(cfg config.ServerConfig, handler http.Handler) *Server {
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           handler,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		},
+		shutdownDelay:   cfg.ShutdownDelay,
+		shutdownTimeout: cfg.ShutdownTimeout,
+	}
+}
+
+// Draining reports whether the server is shutting down. Readiness checks use
+// it to take the instance out of rotation before connections are closed.
+func (s *Server) Draining() bool {
+	return s.draining.Load()
+}
+
+// Run serves until c is done, then drains in-flight requests within the
+// configured shutdown timeout.
+func (s *Server) Run(c context.Context) error {
+	errs := make(chan error, 1)
+	go func() {
+		log.Println("Listening on", s.httpServer.Addr)
+		errs <- s.httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-c.Done():
+	}
+
+	s.draining.Store(true)
+	log.Println("Shutting down, draining in-flight requests")
+
+	// Give load balancers time to observe the failing readiness check
+	// before the listener is closed.
+	time.Sleep(s.shutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return err
+	}
+
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
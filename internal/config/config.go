@@ -0,0 +1,300 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Config holds every setting of the application. Each leaf field can be set,
+// from lowest to highest precedence, by its default, the config file (using
+// the dotted key path), its environment variable or its command-line flag
+// (named after the dotted key path).
+type Config struct {
+	Server      ServerConfig      `config:"server"`
+	Database    DatabaseConfig    `config:"database"`
+	Auth        AuthConfig        `config:"auth"`
+	Catalog     CatalogConfig     `config:"catalog"`
+	RateLimit   RateLimitConfig   `config:"rate_limit"`
+	Idempotency IdempotencyConfig `config:"idempotency"`
+	Health      HealthConfig      `config:"health"`
+	Customers   CustomersConfig   `config:"customers"`
+	Search      SearchConfig      `config:"search"`
+	Imports     ImportsConfig     `config:"imports"`
+	Outbox      OutboxConfig      `config:"outbox"`
+	Webhooks    WebhooksConfig    `config:"webhooks"`
+	Streams     StreamsConfig     `config:"streams"`
+	Favorites   FavoritesConfig   `config:"favorites"`
+	Sharing     SharingConfig     `config:"sharing"`
+	PriceAlerts PriceAlertsConfig `config:"price_alerts"`
+	Analytics   AnalyticsConfig   `config:"analytics"`
+}
+
+type ServerConfig struct {
+	Addr              string        `config:"addr" env:"SERVER_ADDR" desc:"Address the HTTP server listens on"`
+	Mode              string        `config:"mode" env:"GIN_MODE" desc:"Gin mode: debug, release or test"`
+	ReadTimeout       time.Duration `config:"read_timeout" env:"SERVER_READ_TIMEOUT" desc:"Maximum duration to read a whole request"`
+	ReadHeaderTimeout time.Duration `config:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" desc:"Maximum duration to read request headers"`
+	WriteTimeout      time.Duration `config:"write_timeout" env:"SERVER_WRITE_TIMEOUT" desc:"Maximum duration to write a response"`
+	IdleTimeout       time.Duration `config:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" desc:"Maximum time to keep idle keep-alive connections"`
+	MaxHeaderBytes    int           `config:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES" desc:"Maximum size of request headers"`
+	MaxBodyBytes      int64         `config:"max_body_bytes" env:"SERVER_MAX_BODY_BYTES" desc:"Maximum size of request bodies"`
+	ShutdownDelay     time.Duration `config:"shutdown_delay" env:"SERVER_SHUTDOWN_DELAY" desc:"Time readiness fails before the listener closes on shutdown"`
+	ShutdownTimeout   time.Duration `config:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" desc:"Deadline to drain in-flight requests on shutdown"`
+	TrustedProxies    []string      `config:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES" desc:"Comma-separated IPs or CIDRs of proxies whose X-Forwarded-For is trusted for client IPs; none by default"`
+}
+
+type DatabaseConfig struct {
+	Host            string        `config:"host" env:"POSTGRES_HOST" desc:"PostgreSQL host"`
+	Port            int           `config:"port" env:"POSTGRES_PORT" desc:"PostgreSQL port"`
+	User            string        `config:"user" env:"POSTGRES_USER" desc:"PostgreSQL user"`
+	Password        string        `config:"password" env:"POSTGRES_PASSWORD" secret:"true" desc:"PostgreSQL password"`
+	Name            string        `config:"name" env:"POSTGRES_DB" desc:"PostgreSQL database name"`
+	SSLMode         string        `config:"sslmode" env:"POSTGRES_SSLMODE" desc:"PostgreSQL sslmode"`
+	MaxOpenConns    int           `config:"max_open_conns" env:"POSTGRES_MAX_OPEN_CONNS" desc:"Maximum open connections in the pool"`
+	MaxIdleConns    int           `config:"max_idle_conns" env:"POSTGRES_MAX_IDLE_CONNS" desc:"Maximum idle connections in the pool"`
+	ConnMaxLifetime time.Duration `config:"conn_max_lifetime" env:"POSTGRES_CONN_MAX_LIFETIME" desc:"Maximum lifetime of a pooled connection"`
+}
+
+type AuthConfig struct {
+	JWTSecret string        `config:"jwt_secret" env:"JWT_SECRET" secret:"true" desc:"Secret used to sign JWT tokens"`
+	TokenTTL  time.Duration `config:"token_ttl" env:"JWT_TOKEN_TTL" desc:"Lifetime of issued JWT tokens"`
+}
+
+type CatalogConfig struct {
+	URL     string        `config:"url" env:"PRODUCT_CATALOG_URL" desc:"URL of the product catalog"`
+	Timeout time.Duration `config:"timeout" env:"PRODUCT_CATALOG_TIMEOUT" desc:"Timeout of product catalog requests"`
+}
+
+type RateLimitConfig struct {
+	Enabled   bool                 `config:"enabled" env:"RATE_LIMIT_ENABLED" desc:"Enable request rate limiting"`
+	Auth      RateLimitGroupConfig `config:"auth" env:"RATE_LIMIT_AUTH"`
+	API       RateLimitGroupConfig `config:"api" env:"RATE_LIMIT_API"`
+	Favorites RateLimitGroupConfig `config:"favorites" env:"RATE_LIMIT_FAVORITES"`
+	Shared    RateLimitGroupConfig `config:"shared" env:"RATE_LIMIT_SHARED"`
+}
+
+type RateLimitGroupConfig struct {
+	Requests int           `config:"requests" env:"REQUESTS" desc:"Requests allowed per window"`
+	Window   time.Duration `config:"window" env:"WINDOW" desc:"Window in which requests are refilled"`
+	Burst    int           `config:"burst" env:"BURST" desc:"Maximum requests allowed at once"`
+}
+
+type IdempotencyConfig struct {
+	TTL             time.Duration `config:"ttl" env:"IDEMPOTENCY_KEY_TTL" desc:"How long idempotent responses are kept for replay"`
+	CleanupInterval time.Duration `config:"cleanup_interval" env:"IDEMPOTENCY_CLEANUP_INTERVAL" desc:"Interval between removals of expired idempotency keys"`
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration `config:"check_timeout" env:"HEALTH_CHECK_TIMEOUT" desc:"Timeout of each readiness check"`
+	CacheTTL     time.Duration `config:"cache_ttl" env:"HEALTH_CACHE_TTL" desc:"How long readiness results are reused"`
+	CheckCatalog bool          `config:"check_catalog" env:"HEALTH_CHECK_CATALOG" desc:"Include the product catalog in readiness checks"`
+}
+
+type CustomersConfig struct {
+	DeletionGracePeriod time.Duration `config:"deletion_grace_period" env:"CUSTOMER_DELETION_GRACE_PERIOD" desc:"How long deleted customers can be restored before being purged"`
+	PurgeInterval       time.Duration `config:"purge_interval" env:"CUSTOMER_PURGE_INTERVAL" desc:"Interval between purges of expired deleted customers"`
+}
+
+type SearchConfig struct {
+	Backend             string  `config:"backend" env:"SEARCH_BACKEND" desc:"Customer search backend: auto, postgres or memory"`
+	SimilarityThreshold float64 `config:"similarity_threshold" env:"SEARCH_SIMILARITY_THRESHOLD" desc:"Minimum word similarity, from 0 to 1, of a search match"`
+	MaxPageSize         int     `config:"max_page_size" env:"SEARCH_MAX_PAGE_SIZE" desc:"Maximum page size of search results"`
+}
+
+type ImportsConfig struct {
+	MaxUploadBytes int64         `config:"max_upload_bytes" env:"IMPORT_MAX_UPLOAD_BYTES" desc:"Maximum size of a customer import file"`
+	MaxRows        int           `config:"max_rows" env:"IMPORT_MAX_ROWS" desc:"Maximum number of rows of a customer import"`
+	BatchSize      int           `config:"batch_size" env:"IMPORT_BATCH_SIZE" desc:"Rows applied per import transaction"`
+	PollInterval   time.Duration `config:"poll_interval" env:"IMPORT_POLL_INTERVAL" desc:"Interval between checks for queued imports"`
+	StaleTimeout   time.Duration `config:"stale_timeout" env:"IMPORT_STALE_TIMEOUT" desc:"How long a running import may make no progress before it is resumed elsewhere"`
+}
+
+type OutboxConfig struct {
+	Sinks           []string      `config:"sinks" env:"OUTBOX_SINKS" desc:"Comma-separated sinks receiving domain events: log, file and webhook"`
+	FilePath        string        `config:"file_path" env:"OUTBOX_FILE_PATH" desc:"File the file sink appends events to"`
+	WebhookURL      string        `config:"webhook_url" env:"OUTBOX_WEBHOOK_URL" desc:"URL the webhook sink posts events to"`
+	WebhookTimeout  time.Duration `config:"webhook_timeout" env:"OUTBOX_WEBHOOK_TIMEOUT" desc:"Timeout of webhook sink requests"`
+	PollInterval    time.Duration `config:"poll_interval" env:"OUTBOX_POLL_INTERVAL" desc:"Interval between checks for undispatched events"`
+	BatchSize       int           `config:"batch_size" env:"OUTBOX_BATCH_SIZE" desc:"Events dispatched per transaction"`
+	RetryDelay      time.Duration `config:"retry_delay" env:"OUTBOX_RETRY_DELAY" desc:"Delay before retrying a failed event, doubled on every attempt"`
+	MaxRetryDelay   time.Duration `config:"max_retry_delay" env:"OUTBOX_MAX_RETRY_DELAY" desc:"Longest delay between attempts of a failed event"`
+	Retention       time.Duration `config:"retention" env:"OUTBOX_RETENTION" desc:"How long dispatched events are kept"`
+	CleanupInterval time.Duration `config:"cleanup_interval" env:"OUTBOX_CLEANUP_INTERVAL" desc:"Interval between removals of expired dispatched events"`
+	ClaimTimeout    time.Duration `config:"claim_timeout" env:"OUTBOX_CLAIM_TIMEOUT" desc:"How long events being published are skipped by other replicas before they are published again"`
+}
+
+type WebhooksConfig struct {
+	Timeout         time.Duration `config:"timeout" env:"WEBHOOK_TIMEOUT" desc:"Timeout of webhook deliveries"`
+	PollInterval    time.Duration `config:"poll_interval" env:"WEBHOOK_POLL_INTERVAL" desc:"Interval between checks for due webhook deliveries"`
+	BatchSize       int           `config:"batch_size" env:"WEBHOOK_BATCH_SIZE" desc:"Webhook deliveries sent per transaction"`
+	MaxAttempts     int           `config:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" desc:"Attempts of a webhook delivery before it is dead-lettered"`
+	RetryDelay      time.Duration `config:"retry_delay" env:"WEBHOOK_RETRY_DELAY" desc:"Delay before retrying a failed webhook delivery, doubled on every attempt"`
+	MaxRetryDelay   time.Duration `config:"max_retry_delay" env:"WEBHOOK_MAX_RETRY_DELAY" desc:"Longest delay between attempts of a webhook delivery"`
+	Retention       time.Duration `config:"retention" env:"WEBHOOK_RETENTION" desc:"How long finished webhook deliveries are kept"`
+	CleanupInterval time.Duration `config:"cleanup_interval" env:"WEBHOOK_CLEANUP_INTERVAL" desc:"Interval between removals of expired webhook deliveries"`
+	ClaimTimeout    time.Duration `config:"claim_timeout" env:"WEBHOOK_CLAIM_TIMEOUT" desc:"How long webhook deliveries being sent are skipped by other replicas before they are sent again"`
+}
+
+type StreamsConfig struct {
+	HeartbeatInterval time.Duration `config:"heartbeat_interval" env:"STREAM_HEARTBEAT_INTERVAL" desc:"Interval between keep-alive comments on idle event streams"`
+	ReplayLimit       int           `config:"replay_limit" env:"STREAM_REPLAY_LIMIT" desc:"Changes read per query when an event stream catches up"`
+	HistoryRetention  time.Duration `config:"history_retention" env:"STREAM_HISTORY_RETENTION" desc:"How long favorite changes are kept for streams resuming with Last-Event-ID"`
+	CleanupInterval   time.Duration `config:"cleanup_interval" env:"STREAM_CLEANUP_INTERVAL" desc:"Interval between removals of expired favorite changes"`
+	ReconnectDelay    time.Duration `config:"reconnect_delay" env:"STREAM_RECONNECT_DELAY" desc:"Delay before the change listener reconnects to the database, doubled up to a minute"`
+}
+
+type FavoritesConfig struct {
+	TombstoneRetention       time.Duration `config:"tombstone_retention" env:"FAVORITE_TOMBSTONE_RETENTION" desc:"How long removed favorites are kept for clients syncing changes; older sync tokens expire"`
+	TombstoneCleanupInterval time.Duration `config:"tombstone_cleanup_interval" env:"FAVORITE_TOMBSTONE_CLEANUP_INTERVAL" desc:"Interval between removals of expired favorite tombstones"`
+	BatchMaxOperations       int           `config:"batch_max_operations" env:"FAVORITE_BATCH_MAX_OPERATIONS" desc:"Maximum number of operations in a favorites batch"`
+}
+
+type SharingConfig struct {
+	CacheMaxAge time.Duration `config:"cache_max_age" env:"SHARING_CACHE_MAX_AGE" desc:"How long shared favorites may be cached, delaying revocations by as much"`
+}
+
+type PriceAlertsConfig struct {
+	CheckInterval time.Duration `config:"check_interval" env:"PRICE_ALERT_CHECK_INTERVAL" desc:"Interval between reads of the catalog recording prices and alerting price drops"`
+	Notifiers     []string      `config:"notifiers" env:"PRICE_ALERT_NOTIFIERS" desc:"Comma-separated notifiers of price alerts: log and event"`
+	BatchSize     int           `config:"batch_size" env:"PRICE_ALERT_BATCH_SIZE" desc:"Price alerts notified per transaction"`
+	MaxAttempts   int           `config:"max_attempts" env:"PRICE_ALERT_MAX_ATTEMPTS" desc:"Failed notifications after which a price alert is given up"`
+	ClaimTimeout  time.Duration `config:"claim_timeout" env:"PRICE_ALERT_CLAIM_TIMEOUT" desc:"How long price alerts being notified are skipped by other replicas before they are notified again"`
+}
+
+type AnalyticsConfig struct {
+	RefreshInterval time.Duration `config:"refresh_interval" env:"ANALYTICS_REFRESH_INTERVAL" desc:"Interval between refreshes of the favorite analytics views"`
+}
+
+// Default returns the configuration used when nothing overrides it.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Addr:              ":3002",
+			Mode:              "debug",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			MaxBodyBytes:      1 << 20,
+			ShutdownDelay:     0,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "postgres",
+			Name:            "app",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 30 * time.Minute,
+		},
+		Auth: AuthConfig{
+			TokenTTL: time.Hour,
+		},
+		Catalog: CatalogConfig{
+			URL:     "https://fakestoreapi.com/products",
+			Timeout: 10 * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:   true,
+			Auth:      RateLimitGroupConfig{Requests: 10, Window: time.Minute, Burst: 5},
+			API:       RateLimitGroupConfig{Requests: 300, Window: time.Minute, Burst: 60},
+			Favorites: RateLimitGroupConfig{Requests: 60, Window: time.Minute, Burst: 20},
+			Shared:    RateLimitGroupConfig{Requests: 30, Window: time.Minute, Burst: 10},
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:             24 * time.Hour,
+			CleanupInterval: time.Hour,
+		},
+		Health: HealthConfig{
+			CheckTimeout: 2 * time.Second,
+			CacheTTL:     5 * time.Second,
+			CheckCatalog: true,
+		},
+		Customers: CustomersConfig{
+			DeletionGracePeriod: 30 * 24 * time.Hour,
+			PurgeInterval:       time.Hour,
+		},
+		Search: SearchConfig{
+			Backend:             "auto",
+			SimilarityThreshold: 0.3,
+			MaxPageSize:         100,
+		},
+		Imports: ImportsConfig{
+			MaxUploadBytes: 20 << 20,
+			MaxRows:        100_000,
+			BatchSize:      500,
+			PollInterval:   2 * time.Second,
+			StaleTimeout:   10 * time.Minute,
+		},
+		Outbox: OutboxConfig{
+			Sinks:           []string{"log"},
+			FilePath:        "events.ndjson",
+			WebhookTimeout:  10 * time.Second,
+			PollInterval:    time.Second,
+			BatchSize:       100,
+			RetryDelay:      time.Second,
+			MaxRetryDelay:   time.Hour,
+			Retention:       7 * 24 * time.Hour,
+			CleanupInterval: time.Hour,
+			ClaimTimeout:    30 * time.Minute,
+		},
+		Webhooks: WebhooksConfig{
+			Timeout:         10 * time.Second,
+			PollInterval:    time.Second,
+			BatchSize:       20,
+			MaxAttempts:     8,
+			RetryDelay:      30 * time.Second,
+			MaxRetryDelay:   6 * time.Hour,
+			Retention:       30 * 24 * time.Hour,
+			CleanupInterval: time.Hour,
+			ClaimTimeout:    10 * time.Minute,
+		},
+		Streams: StreamsConfig{
+			HeartbeatInterval: 15 * time.Second,
+			ReplayLimit:       100,
+			HistoryRetention:  time.Hour,
+			CleanupInterval:   5 * time.Minute,
+			ReconnectDelay:    time.Second,
+		},
+		Favorites: FavoritesConfig{
+			TombstoneRetention:       30 * 24 * time.Hour,
+			TombstoneCleanupInterval: time.Hour,
+			BatchMaxOperations:       100,
+		},
+		Sharing: SharingConfig{
+			CacheMaxAge: time.Minute,
+		},
+		PriceAlerts: PriceAlertsConfig{
+			CheckInterval: time.Hour,
+			Notifiers:     []string{"event"},
+			BatchSize:     100,
+			MaxAttempts:   5,
+			ClaimTimeout:  5 * time.Minute,
+		},
+		Analytics: AnalyticsConfig{
+			RefreshInterval: 15 * time.Minute,
+		},
+	}
+}
+
+func (c DatabaseConfig) DSN() string {
+	return fmt.Sprintf(
+		"port=%d user=%s password=%s dbname=%s host=%s sslmode=%s",
+		c.Port,
+		quoteDSNValue(c.User),
+		quoteDSNValue(c.Password),
+		quoteDSNValue(c.Name),
+		quoteDSNValue(c.Host),
+		quoteDSNValue(c.SSLMode),
+	)
+}
+
+func quoteDSNValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
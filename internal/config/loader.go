@@ -0,0 +1,271 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Setting is a single leaf of the configuration.
+type Setting struct {
+	Key         string
+	Env         string
+	Description string
+	Secret      bool
+	Source      string
+	value       reflect.Value
+}
+
+func (s *Setting) Value() string {
+	return formatValue(s.value)
+}
+
+func (s *Setting) set(raw string, source string) error {
+	if err := parseValue(s.value, raw); err != nil {
+		return fmt.Errorf("%s: invalid value %q: %w", s.Key, raw, err)
+	}
+	s.Source = source
+	return nil
+}
+
+// Loaded is a configuration together with where each setting came from.
+type Loaded struct {
+	Config   *Config
+	File     string
+	Settings []*Setting
+}
+
+// Load builds the configuration from the defaults, the file given by the
+// -config flag or CONFIG_FILE, the environment and args. The returned error
+// is flag.ErrHelp when usage was requested.
+func Load(name string, args []string) (*Loaded, error) {
+	cfg := Default()
+	settings := collectSettings(reflect.ValueOf(&cfg).Elem(), "", "")
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "Path to a YAML or TOML config file (env CONFIG_FILE)")
+
+	flagValues := make(map[string]*string, len(settings))
+	for _, setting := range settings {
+		usage := fmt.Sprintf("%s (env %s, default %q)", setting.Description, setting.Env, setting.Value())
+		flagValues[setting.Key] = fs.String(setting.Key, "", usage)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*Setting, len(settings))
+	for _, setting := range settings {
+		byKey[setting.Key] = setting
+	}
+
+	var problems []string
+
+	if *configFile != "" {
+		values, err := readFile(*configFile)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			setting, ok := byKey[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: unknown key in %s", key, *configFile))
+				continue
+			}
+			if err := setting.set(values[key], SourceFile); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+
+	for _, setting := range settings {
+		if raw, ok := os.LookupEnv(setting.Env); ok {
+			if err := setting.set(raw, SourceEnv); err != nil {
+				problems = append(problems, err.Error()+" (from "+setting.Env+")")
+			}
+		}
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		setting, ok := byKey[f.Name]
+		if !ok {
+			return
+		}
+		if err := setting.set(*flagValues[f.Name], SourceFlag); err != nil {
+			problems = append(problems, err.Error()+" (from -"+f.Name+")")
+		}
+	})
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return &Loaded{Config: &cfg, File: *configFile, Settings: settings}, nil
+}
+
+func collectSettings(v reflect.Value, keyPrefix string, envPrefix string) []*Setting {
+	var settings []*Setting
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key := field.Tag.Get("config")
+		if keyPrefix != "" {
+			key = keyPrefix + "." + key
+		}
+
+		env := field.Tag.Get("env")
+		if envPrefix != "" && env != "" {
+			env = envPrefix + "_" + env
+		}
+
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Duration(0)) {
+			settings = append(settings, collectSettings(v.Field(i), key, env)...)
+			continue
+		}
+
+		settings = append(settings, &Setting{
+			Key:         key,
+			Env:         env,
+			Description: field.Tag.Get("desc"),
+			Secret:      field.Tag.Get("secret") == "true",
+			Source:      SourceDefault,
+			value:       v.Field(i),
+		})
+	}
+
+	return settings
+}
+
+// readFile reads a YAML or TOML file, chosen by extension, into a map of
+// dotted keys to raw values.
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var document map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &document)
+	case ".toml":
+		err = toml.Unmarshal(content, &document)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten(document, "", values)
+	return values, nil
+}
+
+func flatten(document map[string]any, prefix string, values map[string]string) {
+	for key, value := range document {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch value := value.(type) {
+		case map[string]any:
+			flatten(value, key, values)
+		case []any:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+}
+
+func parseValue(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func formatValue(v reflect.Value) string {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+	if v.Kind() == reflect.Slice {
+		return strings.Join(v.Interface().([]string), ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
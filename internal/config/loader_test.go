@@ -0,0 +1,166 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func settingSource(loaded *Loaded, key string) string {
+	for _, setting := range loaded.Settings {
+		if setting.Key == key {
+			return setting.Source
+		}
+	}
+	return ""
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+server:
+  addr: ":4000"
+  mode: release
+rate_limit:
+  api:
+    requests: 100
+    window: 30s
+outbox:
+  sinks: [log, file]
+`)
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("GIN_MODE", "test")
+	t.Setenv("RATE_LIMIT_API_REQUESTS", "200")
+	t.Setenv("SERVER_TRUSTED_PROXIES", "10.0.0.0/8, 192.168.0.1")
+
+	loaded, err := Load("app", []string{"-config", path, "-rate_limit.api.requests", "400"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := loaded.Config
+
+	tests := []struct {
+		key        string
+		got        any
+		want       any
+		wantSource string
+	}{
+		{key: "server.read_timeout", got: cfg.Server.ReadTimeout, want: 15 * time.Second, wantSource: SourceDefault},
+		{key: "server.addr", got: cfg.Server.Addr, want: ":4000", wantSource: SourceFile},
+		{key: "rate_limit.api.window", got: cfg.RateLimit.API.Window, want: 30 * time.Second, wantSource: SourceFile},
+		{key: "outbox.sinks", got: strings.Join(cfg.Outbox.Sinks, ","), want: "log,file", wantSource: SourceFile},
+		{key: "server.mode", got: cfg.Server.Mode, want: "test", wantSource: SourceEnv},
+		{key: "server.trusted_proxies", got: strings.Join(cfg.Server.TrustedProxies, ","), want: "10.0.0.0/8,192.168.0.1", wantSource: SourceEnv},
+		{key: "rate_limit.api.requests", got: cfg.RateLimit.API.Requests, want: 400, wantSource: SourceFlag},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.key, tt.got, tt.want)
+		}
+		if source := settingSource(loaded, tt.key); source != tt.wantSource {
+			t.Errorf("%s source = %q, want %q", tt.key, source, tt.wantSource)
+		}
+	}
+
+	if proxies := Default().Server.TrustedProxies; proxies != nil {
+		t.Errorf("default server.trusted_proxies = %v, want nil", proxies)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := writeConfigFile(t, "config.toml", `
+[webhooks]
+batch_size = 5
+claim_timeout = "2m"
+`)
+	t.Setenv("CONFIG_FILE", path)
+
+	loaded, err := Load("app", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Config.Webhooks.BatchSize != 5 || loaded.Config.Webhooks.ClaimTimeout != 2*time.Minute {
+		t.Errorf("webhooks = %+v, want batch_size 5 and claim_timeout 2m", loaded.Config.Webhooks)
+	}
+	if loaded.File != path {
+		t.Errorf("file = %q, want %q", loaded.File, path)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		args    []string
+		wantErr []string
+	}{
+		{
+			name:    "unknown file key",
+			file:    "server:\n  port: 3002\n",
+			wantErr: []string{"server.port: unknown key"},
+		},
+		{
+			name:    "invalid file value",
+			file:    "server:\n  read_timeout: soon\n",
+			wantErr: []string{`server.read_timeout: invalid value "soon"`},
+		},
+		{
+			name:    "invalid environment value",
+			env:     map[string]string{"POSTGRES_PORT": "five"},
+			wantErr: []string{`database.port: invalid value "five"`, "(from POSTGRES_PORT)"},
+		},
+		{
+			name:    "invalid flag value",
+			args:    []string{"-rate_limit.enabled", "maybe"},
+			wantErr: []string{`rate_limit.enabled: invalid value "maybe"`, "(from -rate_limit.enabled)"},
+		},
+		{
+			name: "every problem at once",
+			file: "server:\n  port: 3002\n",
+			env:  map[string]string{"POSTGRES_PORT": "five"},
+			args: []string{"-rate_limit.enabled", "maybe"},
+			wantErr: []string{
+				"server.port: unknown key",
+				`database.port: invalid value "five"`,
+				`rate_limit.enabled: invalid value "maybe"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", "")
+			if tt.file != "" {
+				t.Setenv("CONFIG_FILE", writeConfigFile(t, "config.yaml", tt.file))
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			_, err := Load("app", tt.args)
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Load error = %v, want a *ValidationError", err)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+const redacted = "[REDACTED]"
+
+// Print writes every setting with its effective value and source. Secrets
+// that are set are redacted.
+func (l *Loaded) Print(w io.Writer) error {
+	if l.File != "" {
+		fmt.Fprintf(w, "# config file: %s\n", l.File)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE\tENV")
+	for _, setting := range l.Settings {
+		value := setting.Value()
+		if setting.Secret && value != "" {
+			value = redacted
+		}
+		fmt.Fprintf(tw, "%s\t%q\t%s\t%s\n", setting.Key, value, setting.Source, setting.Env)
+	}
+
+	return tw.Flush()
+}
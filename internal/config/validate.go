@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// insecureSecrets are values that must never be used outside development.
+var insecureSecrets = []string{"default_jwt_secret", "secret", "changeme"}
+
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, problem := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(problem)
+	}
+	return b.String()
+}
+
+// Validate checks every setting and reports all problems at once.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.check("server.addr", c.Server.Addr != "", "is required")
+	v.check("server.mode", c.Server.Mode == "debug" || c.Server.Mode == "release" || c.Server.Mode == "test",
+		"must be debug, release or test")
+	v.positive("server.read_timeout", c.Server.ReadTimeout)
+	v.positive("server.read_header_timeout", c.Server.ReadHeaderTimeout)
+	v.positive("server.write_timeout", c.Server.WriteTimeout)
+	v.positive("server.idle_timeout", c.Server.IdleTimeout)
+	v.check("server.max_header_bytes", c.Server.MaxHeaderBytes > 0, "must be positive")
+	v.check("server.max_body_bytes", c.Server.MaxBodyBytes > 0, "must be positive")
+	v.check("server.shutdown_delay", c.Server.ShutdownDelay >= 0, "must not be negative")
+	v.positive("server.shutdown_timeout", c.Server.ShutdownTimeout)
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		v.check("server.trusted_proxies", cidrErr == nil || net.ParseIP(proxy) != nil,
+			fmt.Sprintf("%q is not an IP address or CIDR", proxy))
+	}
+
+	v.check("database.host", c.Database.Host != "", "is required")
+	v.check("database.port", c.Database.Port > 0 && c.Database.Port <= 65535, "must be between 1 and 65535")
+	v.check("database.user", c.Database.User != "", "is required")
+	v.check("database.password", c.Database.Password != "", "is required (POSTGRES_PASSWORD)")
+	v.check("database.name", c.Database.Name != "", "is required")
+	v.check("database.max_open_conns", c.Database.MaxOpenConns > 0, "must be positive")
+	v.check("database.max_idle_conns", c.Database.MaxIdleConns >= 0 && c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"must be between 0 and database.max_open_conns")
+	v.positive("database.conn_max_lifetime", c.Database.ConnMaxLifetime)
+
+	v.check("auth.jwt_secret", c.Auth.JWTSecret != "", "is required (JWT_SECRET)")
+	for _, insecure := range insecureSecrets {
+		v.check("auth.jwt_secret", c.Auth.JWTSecret != insecure, "must not be a well-known default")
+	}
+	v.positive("auth.token_ttl", c.Auth.TokenTTL)
+
+	catalogURL, err := url.Parse(c.Catalog.URL)
+	v.check("catalog.url", err == nil && (catalogURL.Scheme == "http" || catalogURL.Scheme == "https") && catalogURL.Host != "",
+		"must be an absolute http(s) URL")
+	v.positive("catalog.timeout", c.Catalog.Timeout)
+
+	if c.RateLimit.Enabled {
+		v.rateLimitGroup("rate_limit.auth", c.RateLimit.Auth)
+		v.rateLimitGroup("rate_limit.api", c.RateLimit.API)
+		v.rateLimitGroup("rate_limit.favorites", c.RateLimit.Favorites)
+		v.rateLimitGroup("rate_limit.shared", c.RateLimit.Shared)
+	}
+
+	v.positive("idempotency.ttl", c.Idempotency.TTL)
+	v.positive("idempotency.cleanup_interval", c.Idempotency.CleanupInterval)
+
+	v.positive("health.check_timeout", c.Health.CheckTimeout)
+	v.check("health.cache_ttl", c.Health.CacheTTL >= 0, "must not be negative")
+
+	v.positive("customers.deletion_grace_period", c.Customers.DeletionGracePeriod)
+	v.positive("customers.purge_interval", c.Customers.PurgeInterval)
+
+	v.check("search.backend", c.Search.Backend == "auto" || c.Search.Backend == "postgres" || c.Search.Backend == "memory",
+		"must be auto, postgres or memory")
+	v.check("search.similarity_threshold", c.Search.SimilarityThreshold > 0 && c.Search.SimilarityThreshold <= 1,
+		"must be greater than 0 and at most 1")
+	v.check("search.max_page_size", c.Search.MaxPageSize > 0, "must be positive")
+
+	v.check("imports.max_upload_bytes", c.Imports.MaxUploadBytes > 0, "must be positive")
+	v.check("imports.max_rows", c.Imports.MaxRows > 0, "must be positive")
+	v.check("imports.batch_size", c.Imports.BatchSize > 0, "must be positive")
+	v.positive("imports.poll_interval", c.Imports.PollInterval)
+	v.positive("imports.stale_timeout", c.Imports.StaleTimeout)
+
+	for _, sink := range c.Outbox.Sinks {
+		switch sink {
+		case "log":
+		case "file":
+			v.check("outbox.file_path", c.Outbox.FilePath != "", "is required by the file sink")
+		case "webhook":
+			webhookURL, err := url.Parse(c.Outbox.WebhookURL)
+			v.check("outbox.webhook_url", err == nil && (webhookURL.Scheme == "http" || webhookURL.Scheme == "https") && webhookURL.Host != "",
+				"must be an absolute http(s) URL for the webhook sink")
+			v.positive("outbox.webhook_timeout", c.Outbox.WebhookTimeout)
+			v.check("outbox.claim_timeout", c.Outbox.ClaimTimeout > c.Outbox.WebhookTimeout*time.Duration(c.Outbox.BatchSize),
+				"must be longer than outbox.webhook_timeout times outbox.batch_size for the webhook sink")
+		default:
+			v.check("outbox.sinks", false, fmt.Sprintf("unknown sink %q, use log, file or webhook", sink))
+		}
+	}
+	v.positive("outbox.poll_interval", c.Outbox.PollInterval)
+	v.check("outbox.batch_size", c.Outbox.BatchSize > 0, "must be positive")
+	v.positive("outbox.retry_delay", c.Outbox.RetryDelay)
+	v.check("outbox.max_retry_delay", c.Outbox.MaxRetryDelay >= c.Outbox.RetryDelay, "must not be shorter than outbox.retry_delay")
+	v.positive("outbox.retention", c.Outbox.Retention)
+	v.positive("outbox.cleanup_interval", c.Outbox.CleanupInterval)
+	v.positive("outbox.claim_timeout", c.Outbox.ClaimTimeout)
+
+	v.positive("webhooks.timeout", c.Webhooks.Timeout)
+	v.positive("webhooks.poll_interval", c.Webhooks.PollInterval)
+	v.check("webhooks.batch_size", c.Webhooks.BatchSize > 0, "must be positive")
+	v.check("webhooks.max_attempts", c.Webhooks.MaxAttempts > 0, "must be positive")
+	v.positive("webhooks.retry_delay", c.Webhooks.RetryDelay)
+	v.check("webhooks.max_retry_delay", c.Webhooks.MaxRetryDelay >= c.Webhooks.RetryDelay, "must not be shorter than webhooks.retry_delay")
+	v.positive("webhooks.retention", c.Webhooks.Retention)
+	v.positive("webhooks.cleanup_interval", c.Webhooks.CleanupInterval)
+	v.check("webhooks.claim_timeout", c.Webhooks.ClaimTimeout > c.Webhooks.Timeout*time.Duration(c.Webhooks.BatchSize),
+		"must be longer than webhooks.timeout times webhooks.batch_size")
+
+	v.positive("streams.heartbeat_interval", c.Streams.HeartbeatInterval)
+	v.check("streams.replay_limit", c.Streams.ReplayLimit > 0, "must be positive")
+	v.positive("streams.history_retention", c.Streams.HistoryRetention)
+	v.positive("streams.cleanup_interval", c.Streams.CleanupInterval)
+	v.check("streams.reconnect_delay", c.Streams.ReconnectDelay > 0 && c.Streams.ReconnectDelay <= time.Minute, "must be positive and at most 1m")
+
+	v.positive("favorites.tombstone_retention", c.Favorites.TombstoneRetention)
+	v.positive("favorites.tombstone_cleanup_interval", c.Favorites.TombstoneCleanupInterval)
+	v.check("favorites.batch_max_operations", c.Favorites.BatchMaxOperations > 0, "must be positive")
+
+	v.check("sharing.cache_max_age", c.Sharing.CacheMaxAge >= 0, "must not be negative")
+
+	v.positive("price_alerts.check_interval", c.PriceAlerts.CheckInterval)
+	for _, notifier := range c.PriceAlerts.Notifiers {
+		v.check("price_alerts.notifiers", notifier == "log" || notifier == "event",
+			fmt.Sprintf("unknown notifier %q, use log or event", notifier))
+	}
+	v.check("price_alerts.batch_size", c.PriceAlerts.BatchSize > 0, "must be positive")
+	v.check("price_alerts.max_attempts", c.PriceAlerts.MaxAttempts > 0, "must be positive")
+	v.positive("price_alerts.claim_timeout", c.PriceAlerts.ClaimTimeout)
+
+	v.positive("analytics.refresh_interval", c.Analytics.RefreshInterval)
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) check(key string, ok bool, message string) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Sprintf("%s: %s", key, message))
+	}
+}
+
+func (v *validator) positive(key string, d time.Duration) {
+	v.check(key, d > 0, "must be a positive duration")
+}
+
+func (v *validator) rateLimitGroup(key string, group RateLimitGroupConfig) {
+	v.check(key+".requests", group.Requests > 0, "must be positive")
+	v.positive(key+".window", group.Window)
+	v.check(key+".burst", group.Burst >= 0, "must not be negative")
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validConfig() Config {
+	cfg := Default()
+	cfg.Auth.JWTSecret = "a-long-and-random-secret"
+	cfg.Database.Password = "postgres"
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(cfg *Config)
+		want   []string
+	}{
+		{name: "defaults with secrets", change: func(cfg *Config) {}},
+		{
+			name:   "missing secrets",
+			change: func(cfg *Config) { cfg.Auth.JWTSecret, cfg.Database.Password = "", "" },
+			want:   []string{"auth.jwt_secret: is required", "database.password: is required"},
+		},
+		{
+			name:   "well-known secret",
+			change: func(cfg *Config) { cfg.Auth.JWTSecret = "changeme" },
+			want:   []string{"auth.jwt_secret: must not be a well-known default"},
+		},
+		{
+			name:   "unknown mode",
+			change: func(cfg *Config) { cfg.Server.Mode = "production" },
+			want:   []string{"server.mode: must be debug, release or test"},
+		},
+		{
+			name:   "trusted proxies",
+			change: func(cfg *Config) { cfg.Server.TrustedProxies = []string{"10.0.0.1", "10.0.0.0/8", "::1", "fd00::/8"} },
+		},
+		{
+			name:   "invalid trusted proxy",
+			change: func(cfg *Config) { cfg.Server.TrustedProxies = []string{"10.0.0.0/8", "proxy.internal"} },
+			want:   []string{`server.trusted_proxies: "proxy.internal" is not an IP address or CIDR`},
+		},
+		{
+			name:   "relative catalog URL",
+			change: func(cfg *Config) { cfg.Catalog.URL = "/products" },
+			want:   []string{"catalog.url: must be an absolute http(s) URL"},
+		},
+		{
+			name:   "idle connections above open connections",
+			change: func(cfg *Config) { cfg.Database.MaxIdleConns = cfg.Database.MaxOpenConns + 1 },
+			want:   []string{"database.max_idle_conns: must be between 0 and database.max_open_conns"},
+		},
+		{
+			name:   "rate limit group",
+			change: func(cfg *Config) { cfg.RateLimit.API = RateLimitGroupConfig{Requests: 0, Window: 0, Burst: -1} },
+			want: []string{
+				"rate_limit.api.requests: must be positive",
+				"rate_limit.api.window: must be a positive duration",
+				"rate_limit.api.burst: must not be negative",
+			},
+		},
+		{
+			name: "rate limit groups ignored when disabled",
+			change: func(cfg *Config) {
+				cfg.RateLimit.Enabled = false
+				cfg.RateLimit.API = RateLimitGroupConfig{}
+			},
+		},
+		{
+			name:   "unknown outbox sink",
+			change: func(cfg *Config) { cfg.Outbox.Sinks = []string{"log", "kafka"} },
+			want:   []string{`outbox.sinks: unknown sink "kafka", use log, file or webhook`},
+		},
+		{
+			name:   "webhook sink without URL",
+			change: func(cfg *Config) { cfg.Outbox.Sinks = []string{"webhook"} },
+			want:   []string{"outbox.webhook_url: must be an absolute http(s) URL for the webhook sink"},
+		},
+		{
+			name: "outbox claim shorter than a batch of webhook sink requests",
+			change: func(cfg *Config) {
+				cfg.Outbox.Sinks = []string{"webhook"}
+				cfg.Outbox.WebhookURL = "https://events.example.com"
+				cfg.Outbox.ClaimTimeout = cfg.Outbox.WebhookTimeout * time.Duration(cfg.Outbox.BatchSize)
+			},
+			want: []string{"outbox.claim_timeout: must be longer than outbox.webhook_timeout times outbox.batch_size"},
+		},
+		{
+			name:   "webhook claim shorter than a batch of deliveries",
+			change: func(cfg *Config) { cfg.Webhooks.BatchSize = 100 },
+			want:   []string{"webhooks.claim_timeout: must be longer than webhooks.timeout times webhooks.batch_size"},
+		},
+		{
+			name:   "retry delay above its maximum",
+			change: func(cfg *Config) { cfg.Webhooks.RetryDelay = 2 * cfg.Webhooks.MaxRetryDelay },
+			want:   []string{"webhooks.max_retry_delay: must not be shorter than webhooks.retry_delay"},
+		},
+		{
+			name:   "reconnect delay above a minute",
+			change: func(cfg *Config) { cfg.Streams.ReconnectDelay = 2 * time.Minute },
+			want:   []string{"streams.reconnect_delay: must be positive and at most 1m"},
+		},
+		{
+			name:   "unknown price alert notifier",
+			change: func(cfg *Config) { cfg.PriceAlerts.Notifiers = []string{"sms"} },
+			want:   []string{`price_alerts.notifiers: unknown notifier "sms", use log or event`},
+		},
+		{
+			name:   "negative cache durations",
+			change: func(cfg *Config) { cfg.Health.CacheTTL, cfg.Sharing.CacheMaxAge = -time.Second, -time.Second },
+			want:   []string{"health.cache_ttl: must not be negative", "sharing.cache_max_age: must not be negative"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.change(&cfg)
+
+			err := cfg.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate returned error: %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate error = %v, want a *ValidationError", err)
+			}
+			if len(validationErr.Problems) != len(tt.want) {
+				t.Errorf("problems = %q, want %d problems", validationErr.Problems, len(tt.want))
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not report %q", err, want)
+				}
+			}
+		})
+	}
+}
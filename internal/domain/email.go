@@ -0,0 +1,16 @@
+package domain
+
+import "strings"
+
+// NormalizeEmail trims the email and lowercases its domain. The local part
+// keeps its case, but uniqueness is checked ignoring case.
+func NormalizeEmail(email string) string {
+	email = strings.TrimSpace(email)
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+
+	return email[:at] + "@" + strings.ToLower(email[at+1:])
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"app/internal/domain/model"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// GenesisErasureHash is the previous hash of the first erasure receipt.
+var GenesisErasureHash = strings.Repeat("0", 64)
+
+// ErasureReceiptHash returns the hash sealing receipt, computed over all of its
+// fields but Hash. Changing any receipt changes its hash and breaks the link
+// from the next one.
+func ErasureReceiptHash(receipt model.CustomerErasureReceipt) string {
+	actions, _ := json.Marshal(receipt.Actions)
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		receipt.PreviousHash,
+		receipt.ID,
+		receipt.CustomerID,
+		receipt.ErasedBy,
+		receipt.ErasedAt.UTC().Format(time.RFC3339Nano),
+		string(actions),
+	}, "\n")))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -6,4 +6,28 @@ var (
 	ErrUserAlreadyExists  = errors.New("username already exists")
 	ErrNotFound           = errors.New("resource not found")
 	ErrEmailAlreadyExists = errors.New("email already exists")
+	ErrVersionConflict    = errors.New("resource was modified concurrently")
+	ErrRestoreExpired     = errors.New("restore period has expired")
+	ErrInvalidImport      = errors.New("invalid import file")
+	ErrAlreadyErased      = errors.New("customer was already erased")
+
+	ErrInvalidTransition = errors.New("state transition is not allowed")
+	ErrCustomerSuspended = errors.New("customer is suspended")
+	ErrCustomerClosed    = errors.New("customer is closed")
+
+	ErrFavoriteNotFound        = errors.New("product is not a favorite")
+	ErrSyncExpired             = errors.New("sync token has expired")
+	ErrCollectionAlreadyExists = errors.New("collection already exists")
+	ErrInvalidCollectionOrder  = errors.New("order must list every collection once")
+	ErrCollectionPrivate       = errors.New("collection is private")
+	ErrInvalidFavorite         = errors.New("invalid favorite")
+	ErrInvalidFavoriteOrder    = errors.New("order must list every favorite once")
+
+	ErrInvalidAttributes      = errors.New("invalid customer attributes")
+	ErrAttributeAlreadyExists = errors.New("attribute already exists")
+	ErrInvalidTag             = errors.New("invalid tag")
+	ErrInvalidSegment         = errors.New("invalid segment expression")
+	ErrInvalidWebhook         = errors.New("invalid webhook subscription")
+	ErrInvalidShareLink       = errors.New("invalid share link")
+	ErrInvalidPriceAlert      = errors.New("invalid price alert settings")
 )
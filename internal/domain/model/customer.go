@@ -3,8 +3,15 @@ package model
 import "time"
 
 type Customer struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	// Attributes holds the values of the attributes registered in
+	// CustomerAttributeDefinition, by name.
+	Attributes map[string]any `json:"attributes"`
+	State      CustomerState  `json:"state"`
+	Version    int            `json:"version"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  *time.Time     `json:"deleted_at,omitempty"`
 }
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumber  = "number"
+	AttributeTypeInteger = "integer"
+	AttributeTypeBoolean = "boolean"
+)
+
+type CustomerAttributeDefinition struct {
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Required    bool      `json:"required"`
+	Enum        []any     `json:"enum,omitempty"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
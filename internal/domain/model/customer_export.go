@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+const (
+	ExportFavoritesNone     = "none"
+	ExportFavoritesIDs      = "ids"
+	ExportFavoritesProducts = "products"
+)
+
+// CustomerExportFilter selects the customers of an export. Customers created
+// at or after CreatedFrom and before CreatedBefore are included; nil bounds
+// are open.
+type CustomerExportFilter struct {
+	CreatedFrom   *time.Time
+	CreatedBefore *time.Time
+	Favorites     string
+}
+
+type CustomerExport struct {
+	Customer
+	FavoriteProductIDs []int     `json:"favorite_product_ids,omitempty"`
+	FavoriteProducts   []Product `json:"favorite_products,omitempty"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+type CustomerImport struct {
+	ID            string     `json:"id"`
+	Status        string     `json:"status"`
+	Format        string     `json:"format"`
+	DryRun        bool       `json:"dry_run"`
+	Payload       []byte     `json:"-"`
+	CreatedBy     string     `json:"created_by"`
+	TotalRows     int        `json:"total_rows"`
+	ProcessedRows int        `json:"processed_rows"`
+	CreatedRows   int        `json:"created_rows"`
+	FailedRows    int        `json:"failed_rows"`
+	Error         string     `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
+
+type CustomerImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// CustomerImportRow is one parsed record of an import file. Row numbers
+// start at 1 with the first record after any header.
+type CustomerImportRow struct {
+	Row   int
+	Name  string
+	Email string
+}
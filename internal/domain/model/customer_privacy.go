@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+const (
+	ErasureActionAnonymized = "anonymized"
+	ErasureActionRetained   = "retained"
+	ErasureActionDeleted    = "deleted"
+)
+
+type CustomerFavorite struct {
+	ProductID int `json:"product_id"`
+	FavoriteDetails
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CustomerCollection struct {
+	FavoriteCollection
+	ProductIDs []int `json:"product_ids"`
+}
+
+// CustomerDataExport is everything stored about a customer.
+type CustomerDataExport struct {
+	Customer           Customer                  `json:"customer"`
+	ErasedAt           *time.Time                `json:"erased_at,omitempty"`
+	Favorites          []CustomerFavorite        `json:"favorites"`
+	RemovedFavorites   []RemovedFavorite         `json:"removed_favorites"`
+	Collections        []CustomerCollection      `json:"collections"`
+	ShareLinks         []ShareLink               `json:"share_links"`
+	PriceAlertSettings *PriceAlertSettings       `json:"price_alert_settings,omitempty"`
+	PriceAlerts        []PriceAlert              `json:"price_alerts"`
+	Tags               []CustomerTag             `json:"tags"`
+	StateTransitions   []CustomerStateTransition `json:"state_transitions"`
+	FavoriteChanges    []FavoriteChange          `json:"favorite_changes"`
+	ErasureReceipts    []CustomerErasureReceipt  `json:"erasure_receipts"`
+}
+
+// ErasureAction tells what an erasure did to the rows of one table.
+type ErasureAction struct {
+	Table  string `json:"table"`
+	Action string `json:"action"`
+	Rows   int64  `json:"rows"`
+}
+
+// CustomerErasureReceipt records an erasure. Hash covers every other field,
+// including the hash of the previous receipt.
+type CustomerErasureReceipt struct {
+	ID           string          `json:"id"`
+	CustomerID   string          `json:"customer_id"`
+	ErasedBy     string          `json:"erased_by"`
+	ErasedAt     time.Time       `json:"erased_at"`
+	Actions      []ErasureAction `json:"actions"`
+	PreviousHash string          `json:"previous_hash"`
+	Hash         string          `json:"hash"`
+}
@@ -0,0 +1,13 @@
+package model
+
+type CustomerMatch struct {
+	Customer
+	Score float64 `json:"score"`
+}
+
+type CustomerSearchPage struct {
+	Items    []CustomerMatch `json:"items"`
+	Total    int             `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
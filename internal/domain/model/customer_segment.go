@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+type CustomerTag struct {
+	Tag       string    `json:"tag"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TagCount struct {
+	Tag       string `json:"tag"`
+	Customers int    `json:"customers"`
+}
+
+type CustomerSegment struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Expression  string    `json:"expression"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CustomerPage struct {
+	Items    []Customer `json:"items"`
+	Total    int        `json:"total"`
+	Page     int        `json:"page"`
+	PageSize int        `json:"page_size"`
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+type CustomerState string
+
+const (
+	CustomerStatePending   CustomerState = "pending"
+	CustomerStateActive    CustomerState = "active"
+	CustomerStateSuspended CustomerState = "suspended"
+	CustomerStateClosed    CustomerState = "closed"
+)
+
+// customerStateTransitions lists the states each state can move to. Closed
+// customers stay closed.
+var customerStateTransitions = map[CustomerState][]CustomerState{
+	CustomerStatePending:   {CustomerStateActive, CustomerStateClosed},
+	CustomerStateActive:    {CustomerStateSuspended, CustomerStateClosed},
+	CustomerStateSuspended: {CustomerStateActive, CustomerStateClosed},
+}
+
+func (s CustomerState) Valid() bool {
+	switch s {
+	case CustomerStatePending, CustomerStateActive, CustomerStateSuspended, CustomerStateClosed:
+		return true
+	}
+	return false
+}
+
+func (s CustomerState) CanTransitionTo(to CustomerState) bool {
+	for _, allowed := range customerStateTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CustomerStateTransition records a change of state, who made it and why.
+type CustomerStateTransition struct {
+	ID         string        `json:"id"`
+	CustomerID string        `json:"customer_id"`
+	From       CustomerState `json:"from"`
+	To         CustomerState `json:"to"`
+	Reason     string        `json:"reason,omitempty"`
+	Actor      string        `json:"actor"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventFavoriteAdded        = "favorite.added"
+	EventFavoriteRemoved      = "favorite.removed"
+	EventFavoritePriceDropped = "favorite.price_dropped"
+	EventCustomerCreated      = "customer.created"
+	EventCustomerUpdated      = "customer.updated"
+	EventCustomerDeleted      = "customer.deleted"
+	EventCustomerRestored     = "customer.restored"
+	EventCustomerStateChanged = "customer.state_changed"
+	EventCustomerErased       = "customer.erased"
+)
+
+// DomainEvent tells other systems about a change. AggregateID is the ID of
+// the customer the event is about.
+type DomainEvent struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID string          `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+}
+
+type FavoriteEventPayload struct {
+	CustomerID string `json:"customer_id"`
+	ProductID  int    `json:"product_id"`
+}
+
+type CustomerRefEventPayload struct {
+	ID string `json:"id"`
+}
+
+// NewDomainEvent builds an event of eventType with payload encoded as JSON.
+func NewDomainEvent(eventType string, aggregateID string, payload any) (DomainEvent, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return DomainEvent{}, err
+	}
+
+	return DomainEvent{
+		ID:          uuid.New().String(),
+		Type:        eventType,
+		AggregateID: aggregateID,
+		Payload:     encoded,
+		OccurredAt:  time.Now().UTC().Truncate(time.Microsecond),
+	}, nil
+}
+
+// OutboxEvent is a stored event waiting to be dispatched.
+type OutboxEvent struct {
+	DomainEvent
+	Attempts int
+}
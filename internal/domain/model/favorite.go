@@ -1,8 +1,35 @@
 package model
 
+import "time"
+
 type Favorite struct {
 	ID         string `json:"id"`
 	CustomerID string `json:"customer_id"`
 	ProductID  string `json:"product_id"`
 	CreatedAt  string `json:"created_at"`
 }
+
+// FavoriteDetails are what the customer tells about a favorite: why it was
+// saved, its tags and how much it is wanted.
+type FavoriteDetails struct {
+	Note     string   `json:"note"`
+	Tags     []string `json:"tags"`
+	Priority int      `json:"priority"`
+}
+
+// FavoriteUpdate changes the fields that are not nil. Position moves the
+// favorite, shifting the ones in between.
+type FavoriteUpdate struct {
+	Note     *string
+	Tags     *[]string
+	Priority *int
+	Position *int
+}
+
+// FavoriteProduct is a favorite with its product as it is in the catalog.
+type FavoriteProduct struct {
+	Product
+	FavoriteDetails
+	Position int       `json:"position"`
+	AddedAt  time.Time `json:"added_at"`
+}
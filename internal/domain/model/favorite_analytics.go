@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// Favorite analytics are read from views refreshed periodically; RefreshedAt
+// tells how recent they are.
+
+// PopularProduct ranks a product by the favorites added in a window of days.
+type PopularProduct struct {
+	ProductID int    `json:"product_id"`
+	Title     string `json:"title"`
+	Added     int    `json:"added"`
+	Removed   int    `json:"removed"`
+	Favorites int    `json:"favorites"`
+}
+
+type PopularProducts struct {
+	Days        int              `json:"days"`
+	Items       []PopularProduct `json:"items"`
+	RefreshedAt time.Time        `json:"refreshed_at"`
+}
+
+type FavoriteDailyActivity struct {
+	Day     string `json:"day"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+}
+
+type FavoriteActivity struct {
+	Days        int                     `json:"days"`
+	Items       []FavoriteDailyActivity `json:"items"`
+	RefreshedAt time.Time               `json:"refreshed_at"`
+}
+
+// FavoriteSummary counts the customers not deleted and their favorites.
+type FavoriteSummary struct {
+	Customers                       int       `json:"customers"`
+	CustomersWithFavorites          int       `json:"customers_with_favorites"`
+	Favorites                       int       `json:"favorites"`
+	AveragePerCustomer              float64   `json:"average_per_customer"`
+	AveragePerCustomerWithFavorites float64   `json:"average_per_customer_with_favorites"`
+	RefreshedAt                     time.Time `json:"refreshed_at"`
+}
+
+// ProductFavoriteCount is the number of customers who favorite the product,
+// deleted ones included. Title is empty for products no longer in the
+// catalog.
+type ProductFavoriteCount struct {
+	ProductID int    `json:"product_id"`
+	Title     string `json:"title"`
+	Favorites int    `json:"favorites"`
+}
+
+type ProductFavoriteCountPage struct {
+	Items       []ProductFavoriteCount `json:"items"`
+	Total       int                    `json:"total"`
+	Page        int                    `json:"page"`
+	PageSize    int                    `json:"page_size"`
+	RefreshedAt time.Time              `json:"refreshed_at"`
+}
@@ -0,0 +1,47 @@
+package model
+
+// Batch modes tell what happens to a batch when an operation fails.
+const (
+	// FavoriteBatchAllOrNothing applies no operation when one fails.
+	FavoriteBatchAllOrNothing = "all-or-nothing"
+	// FavoriteBatchBestEffort applies every operation that does not fail.
+	FavoriteBatchBestEffort = "best-effort"
+)
+
+const (
+	FavoriteOperationAdd    = "add"
+	FavoriteOperationRemove = "remove"
+)
+
+// Statuses of the operations of a batch.
+const (
+	FavoriteOperationAdded     = "added"
+	FavoriteOperationRemoved   = "removed"
+	FavoriteOperationUnchanged = "unchanged"
+	FavoriteOperationFailed    = "failed"
+	FavoriteOperationSkipped   = "skipped"
+)
+
+// FavoriteOperation adds the product to or removes it from the favorites.
+// Details are only used by additions.
+type FavoriteOperation struct {
+	Op        string
+	ProductID int
+	Details   FavoriteDetails
+}
+
+// FavoriteOperationResult tells what an operation of a batch did. Operations
+// of an all-or-nothing batch that was not applied are skipped, unless they
+// failed.
+type FavoriteOperationResult struct {
+	Op        string `json:"op"`
+	ProductID int    `json:"product_id"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+type FavoriteBatchResult struct {
+	Mode    string                    `json:"mode"`
+	Applied bool                      `json:"applied"`
+	Results []FavoriteOperationResult `json:"results"`
+}
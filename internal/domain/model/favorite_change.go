@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+const (
+	FavoriteChangeAdded   = "added"
+	FavoriteChangeRemoved = "removed"
+)
+
+// FavoriteChange is a favorite added to or removed from a customer. IDs grow
+// in the order the changes of a customer were committed.
+type FavoriteChange struct {
+	ID         int64     `json:"id"`
+	CustomerID string    `json:"customer_id"`
+	Type       string    `json:"type"`
+	ProductID  int       `json:"product_id"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+type RemovedFavorite struct {
+	ProductID int       `json:"product_id"`
+	RemovedAt time.Time `json:"removed_at"`
+}
+
+// FavoriteChanges are the favorites added or changed and the ones removed
+// after a sync ID. SyncID covers them and is where the next sync starts.
+type FavoriteChanges struct {
+	Added   []CustomerFavorite
+	Removed []RemovedFavorite
+	SyncID  int64
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// FavoriteCollection is a named list of favorites of a customer. Collections
+// are ordered by Position.
+type FavoriteCollection struct {
+	ID          string    `json:"id"`
+	CustomerID  string    `json:"customer_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Public      bool      `json:"public"`
+	Position    int       `json:"position"`
+	Favorites   int       `json:"favorites"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type IdempotencyKey struct {
+	Scope           string            `json:"scope"`
+	Key             string            `json:"key"`
+	Fingerprint     string            `json:"fingerprint"`
+	ResponseStatus  int               `json:"response_status"`
+	ResponseHeaders map[string]string `json:"response_headers"`
+	ResponseBody    []byte            `json:"response_body"`
+	CreatedAt       time.Time         `json:"created_at"`
+	ExpiresAt       time.Time         `json:"expires_at"`
+}
+
+// Completed reports whether the original request finished and its response
+// was stored for replay.
+func (k *IdempotencyKey) Completed() bool {
+	return k.ResponseStatus != 0
+}
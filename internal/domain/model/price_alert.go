@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+const (
+	PriceAlertPercent  = "percent"
+	PriceAlertAbsolute = "absolute"
+)
+
+// PriceAlertSettings tell when a customer is alerted of price drops on its
+// favorites: when the price falls by Threshold percent, or by Threshold
+// itself, from the highest price since the product was favorited or last
+// alerted.
+type PriceAlertSettings struct {
+	CustomerID    string    `json:"customer_id"`
+	Enabled       bool      `json:"enabled"`
+	ThresholdType string    `json:"threshold_type"`
+	Threshold     float64   `json:"threshold"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ProductPrice is the price of a product from RecordedAt until the next
+// price recorded.
+type ProductPrice struct {
+	ProductID  int       `json:"product_id"`
+	Price      float64   `json:"price"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// PriceAlert tells a customer that a favorite dropped from ReferencePrice to
+// Price. NotifiedAt is nil until every notifier accepted it.
+type PriceAlert struct {
+	ID             int64      `json:"id"`
+	CustomerID     string     `json:"customer_id"`
+	ProductID      int        `json:"product_id"`
+	ReferencePrice float64    `json:"reference_price"`
+	Price          float64    `json:"price"`
+	CreatedAt      time.Time  `json:"created_at"`
+	NotifiedAt     *time.Time `json:"notified_at,omitempty"`
+	Attempts       int        `json:"-"`
+	// NotifiedBy lists the notifiers that accepted the alert.
+	NotifiedBy []string `json:"-"`
+}
+
+type PriceDroppedEventPayload struct {
+	CustomerID     string  `json:"customer_id"`
+	ProductID      int     `json:"product_id"`
+	ReferencePrice float64 `json:"reference_price"`
+	Price          float64 `json:"price"`
+}
@@ -1,11 +1,12 @@
 package model
 
 type Product struct {
-	ID     int     `json:"id"`
-	Title  string  `json:"title"`
-	Image  string  `json:"image"`
-	Price  float64 `json:"price"`
-	Rating Rating  `json:"rating"`
+	ID       int     `json:"id"`
+	Title    string  `json:"title"`
+	Category string  `json:"category"`
+	Image    string  `json:"image"`
+	Price    float64 `json:"price"`
+	Rating   Rating  `json:"rating"`
 }
 
 type Rating struct {
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// ShareLink gives access to the favorites of a customer, or to one of its
+// collections when CollectionID is set, to anyone holding the token. The
+// token is only known when the link is created.
+type ShareLink struct {
+	ID           string     `json:"id"`
+	CustomerID   string     `json:"customer_id"`
+	CollectionID string     `json:"collection_id,omitempty"`
+	Token        string     `json:"token,omitempty"`
+	CreatedBy    string     `json:"created_by"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	Views        int64      `json:"views"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// SharedFavorites is what a share link shows. Name is the name of the shared
+// collection, empty when sharing every favorite.
+type SharedFavorites struct {
+	Name     string    `json:"name,omitempty"`
+	Products []Product `json:"products"`
+}
@@ -2,9 +2,15 @@ package model
 
 import "time"
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	ID        string    `json:"id"`
 	Username  string    `json:"username"`
 	Password  string    `json:"password"`
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 }
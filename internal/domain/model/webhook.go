@@ -0,0 +1,81 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookEventTypes are the event types subscriptions can receive.
+var WebhookEventTypes = []string{
+	EventFavoriteAdded,
+	EventFavoriteRemoved,
+	EventFavoritePriceDropped,
+	EventCustomerCreated,
+	EventCustomerUpdated,
+	EventCustomerDeleted,
+	EventCustomerRestored,
+	EventCustomerStateChanged,
+	EventCustomerErased,
+}
+
+// WebhookSubscription sends the events of EventTypes to URL. Secret signs
+// the deliveries and is only returned when the subscription is created.
+type WebhookSubscription struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event sent to one subscription, with its retries.
+type WebhookDelivery struct {
+	ID             string                   `json:"id"`
+	SubscriptionID string                   `json:"subscription_id"`
+	EventID        string                   `json:"event_id"`
+	EventType      string                   `json:"event_type"`
+	Status         string                   `json:"status"`
+	Attempts       int                      `json:"attempts"`
+	NextAttemptAt  *time.Time               `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int                     `json:"last_status_code,omitempty"`
+	LastError      string                   `json:"last_error,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	FinishedAt     *time.Time               `json:"finished_at,omitempty"`
+	Payload        json.RawMessage          `json:"payload,omitempty"`
+	AttemptLog     []WebhookDeliveryAttempt `json:"attempt_log,omitempty"`
+}
+
+type WebhookDeliveryAttempt struct {
+	Attempt    int       `json:"attempt"`
+	StatusCode *int      `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int       `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDeliveryPage struct {
+	Items    []WebhookDelivery `json:"items"`
+	Total    int               `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// DueWebhookDelivery is a delivery ready to be sent, with what is needed to
+// send it.
+type DueWebhookDelivery struct {
+	ID       string
+	URL      string
+	Secret   string
+	EventID  string
+	Type     string
+	Payload  []byte
+	Attempts int
+}
@@ -0,0 +1,179 @@
+// Package segment parses the filter expressions that define customer
+// segments, such as
+//
+//	favorites["electronics"] > 10 and created_at >= "2025-01-01"
+//
+// Expressions combine comparisons with and, or, not and parentheses. The
+// fields are:
+//
+//   - name and email: =, != and contains with a string.
+//   - created_at: comparisons with an RFC 3339 time or a date string.
+//   - attributes.<name>: = and != with any value, ordering with a number.
+//   - tags: contains with a string.
+//   - favorites, or favorites["<category>"] for one product category:
+//     comparisons with a whole number.
+package segment
+
+import (
+	"app/internal/domain"
+	"fmt"
+	"math"
+	"time"
+)
+
+// MaxLength is the longest expression accepted.
+const MaxLength = 2000
+
+// MaxDepth is the deepest nesting of parentheses and not accepted.
+const MaxDepth = 20
+
+const (
+	FieldName       = "name"
+	FieldEmail      = "email"
+	FieldCreatedAt  = "created_at"
+	FieldAttributes = "attributes"
+	FieldTags       = "tags"
+	FieldFavorites  = "favorites"
+)
+
+const (
+	OpEqual        = "="
+	OpNotEqual     = "!="
+	OpLess         = "<"
+	OpLessEqual    = "<="
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+	OpContains     = "contains"
+)
+
+type Expr interface {
+	isExpr()
+}
+
+type And struct {
+	Left, Right Expr
+}
+
+type Or struct {
+	Left, Right Expr
+}
+
+type Not struct {
+	Expr Expr
+}
+
+// Comparison compares a field with a literal. Value is a string, float64,
+// bool or, for created_at, time.Time.
+type Comparison struct {
+	Field Field
+	Op    string
+	Value any
+}
+
+// Field is a customer field. Key is the attribute name for attributes and
+// the product category, if any, for favorites.
+type Field struct {
+	Name string
+	Key  string
+}
+
+func (And) isExpr()        {}
+func (Or) isExpr()         {}
+func (Not) isExpr()        {}
+func (Comparison) isExpr() {}
+
+// Parse parses and checks an expression. Errors wrap
+// domain.ErrInvalidSegment.
+func Parse(input string) (Expr, error) {
+	if len(input) > MaxLength {
+		return nil, invalid("expression is longer than %d characters", MaxLength)
+	}
+
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.at(tokenEOF) {
+		return nil, invalid("unexpected %s", p.peek())
+	}
+
+	return expr, nil
+}
+
+// Walk calls fn for every comparison of expr.
+func Walk(expr Expr, fn func(Comparison)) {
+	switch e := expr.(type) {
+	case And:
+		Walk(e.Left, fn)
+		Walk(e.Right, fn)
+	case Or:
+		Walk(e.Left, fn)
+		Walk(e.Right, fn)
+	case Not:
+		Walk(e.Expr, fn)
+	case Comparison:
+		fn(e)
+	}
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", domain.ErrInvalidSegment, fmt.Sprintf(format, args...))
+}
+
+// check validates the operator and value of a comparison for its field,
+// converting created_at values to time.Time.
+func check(comparison Comparison) (Comparison, error) {
+	field, op := comparison.Field, comparison.Op
+	ordering := op == OpLess || op == OpLessEqual || op == OpGreater || op == OpGreaterEqual
+
+	switch field.Name {
+	case FieldName, FieldEmail:
+		if _, ok := comparison.Value.(string); !ok || ordering {
+			return comparison, invalid("%s supports =, != and contains with a string", field.Name)
+		}
+
+	case FieldCreatedAt:
+		value, ok := comparison.Value.(string)
+		if !ok || op == OpContains {
+			return comparison, invalid("created_at must be compared with a date string")
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			parsed, err = time.Parse(time.DateOnly, value)
+		}
+		if err != nil {
+			return comparison, invalid("created_at must be compared with an RFC 3339 time or a date")
+		}
+		comparison.Value = parsed.UTC()
+
+	case FieldAttributes:
+		if op == OpContains {
+			return comparison, invalid("attributes support =, != and ordering with numbers")
+		}
+		if _, ok := comparison.Value.(float64); ordering && !ok {
+			return comparison, invalid("attributes.%s can only be ordered against a number", field.Key)
+		}
+
+	case FieldTags:
+		if _, ok := comparison.Value.(string); !ok || op != OpContains {
+			return comparison, invalid("tags supports contains with a string")
+		}
+
+	case FieldFavorites:
+		value, ok := comparison.Value.(float64)
+		if !ok || op == OpContains || value != math.Trunc(value) {
+			return comparison, invalid("favorites must be compared with a whole number")
+		}
+
+	default:
+		return comparison, invalid("unknown field %s", field.Name)
+	}
+
+	return comparison, nil
+}
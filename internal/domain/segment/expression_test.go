@@ -0,0 +1,225 @@
+package segment
+
+import (
+	"app/internal/domain"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func nameIs(value string) Comparison {
+	return Comparison{Field: Field{Name: FieldName}, Op: OpEqual, Value: value}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Expr
+	}{
+		{
+			name:  "and binds tighter than or",
+			input: `name = "a" or name = "b" and name = "c"`,
+			want:  Or{Left: nameIs("a"), Right: And{Left: nameIs("b"), Right: nameIs("c")}},
+		},
+		{
+			name:  "and binds tighter than or on the left",
+			input: `name = "a" and name = "b" or name = "c"`,
+			want:  Or{Left: And{Left: nameIs("a"), Right: nameIs("b")}, Right: nameIs("c")},
+		},
+		{
+			name:  "not binds tighter than and",
+			input: `not name = "a" and name = "b"`,
+			want:  And{Left: Not{Expr: nameIs("a")}, Right: nameIs("b")},
+		},
+		{
+			name:  "parentheses override precedence",
+			input: `(name = "a" or name = "b") and name = "c"`,
+			want:  And{Left: Or{Left: nameIs("a"), Right: nameIs("b")}, Right: nameIs("c")},
+		},
+		{
+			name:  "not applies to parentheses",
+			input: `not (name = "a" or name = "b")`,
+			want:  Not{Expr: Or{Left: nameIs("a"), Right: nameIs("b")}},
+		},
+		{
+			name:  "and is left associative",
+			input: `name = "a" and name = "b" and name = "c"`,
+			want:  And{Left: And{Left: nameIs("a"), Right: nameIs("b")}, Right: nameIs("c")},
+		},
+		{
+			name:  "or is left associative",
+			input: `name = "a" or name = "b" or name = "c"`,
+			want:  Or{Left: Or{Left: nameIs("a"), Right: nameIs("b")}, Right: nameIs("c")},
+		},
+		{
+			name:  "keywords ignore case",
+			input: `name = "a" AND NOT name = "b" Or name = "c"`,
+			want:  Or{Left: And{Left: nameIs("a"), Right: Not{Expr: nameIs("b")}}, Right: nameIs("c")},
+		},
+		{
+			name:  "escaped quote",
+			input: `name = "O\"Brien"`,
+			want:  nameIs(`O"Brien`),
+		},
+		{
+			name:  "escaped backslash",
+			input: `name = "back\\slash"`,
+			want:  nameIs(`back\slash`),
+		},
+		{
+			name:  "keywords and symbols inside strings",
+			input: `name = "a and (b) or not [c] = 'd'"`,
+			want:  nameIs(`a and (b) or not [c] = 'd'`),
+		},
+		{
+			name:  "unicode string",
+			input: `name = "João"`,
+			want:  nameIs("João"),
+		},
+		{
+			name:  "escaped favorites category",
+			input: `favorites["home \"garden\""] > 1`,
+			want:  Comparison{Field: Field{Name: FieldFavorites, Key: `home "garden"`}, Op: OpGreater, Value: 1.0},
+		},
+		{
+			name:  "contains",
+			input: `email contains "@example.com"`,
+			want:  Comparison{Field: Field{Name: FieldEmail}, Op: OpContains, Value: "@example.com"},
+		},
+		{
+			name:  "tags",
+			input: `tags contains "vip"`,
+			want:  Comparison{Field: Field{Name: FieldTags}, Op: OpContains, Value: "vip"},
+		},
+		{
+			name:  "attribute with boolean",
+			input: `attributes.newsletter != true`,
+			want:  Comparison{Field: Field{Name: FieldAttributes, Key: "newsletter"}, Op: OpNotEqual, Value: true},
+		},
+		{
+			name:  "attribute with negative number",
+			input: `attributes.score <= -2.5`,
+			want:  Comparison{Field: Field{Name: FieldAttributes, Key: "score"}, Op: OpLessEqual, Value: -2.5},
+		},
+		{
+			name:  "created_at with date",
+			input: `created_at >= "2025-01-01"`,
+			want:  Comparison{Field: Field{Name: FieldCreatedAt}, Op: OpGreaterEqual, Value: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:  "created_at with time in another zone",
+			input: `created_at < "2025-01-01T03:00:00-03:00"`,
+			want:  Comparison{Field: Field{Name: FieldCreatedAt}, Op: OpLess, Value: time.Date(2025, 1, 1, 6, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:  "nesting up to MaxDepth",
+			input: strings.Repeat("(", MaxDepth) + `name = "a"` + strings.Repeat(")", MaxDepth),
+			want:  nameIs("a"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %#v, want %#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "empty", input: ``, wantErr: "expected a field"},
+		{name: "unknown field", input: `age > 3`, wantErr: "unknown field age"},
+		{name: "unknown nested field", input: `name.first = "a"`, wantErr: "unknown field"},
+		{name: "attributes without name", input: `attributes = 1`, wantErr: "expected attributes.<name>"},
+		{name: "unknown operator symbol", input: `name ~ "a"`, wantErr: "unexpected '~'"},
+		{name: "lone bang", input: `name ! "a"`, wantErr: `unexpected "!"`},
+		{name: "unknown operator word", input: `name like "a"`, wantErr: "expected an operator"},
+		{name: "missing operator", input: `name "a"`, wantErr: "expected an operator"},
+		{name: "missing value", input: `name =`, wantErr: "expected a value"},
+		{name: "ordering strings", input: `name > "a"`, wantErr: "name supports =, != and contains"},
+		{name: "name with number", input: `name = 1`, wantErr: "name supports =, != and contains"},
+		{name: "tags with equal", input: `tags = "vip"`, wantErr: "tags supports contains"},
+		{name: "attribute contains", input: `attributes.plan contains "a"`, wantErr: "attributes support"},
+		{name: "attribute ordered by string", input: `attributes.plan > "a"`, wantErr: "can only be ordered against a number"},
+		{name: "favorites with fraction", input: `favorites > 1.5`, wantErr: "whole number"},
+		{name: "favorites contains", input: `favorites contains 1`, wantErr: "whole number"},
+		{name: "favorites category not quoted", input: `favorites[electronics] > 1`, wantErr: `expected favorites["<category>"]`},
+		{name: "favorites category unclosed", input: `favorites["electronics" > 1`, wantErr: `expected favorites["<category>"]`},
+		{name: "created_at not a date", input: `created_at >= "yesterday"`, wantErr: "RFC 3339 time or a date"},
+		{name: "created_at contains", input: `created_at contains "2025"`, wantErr: "date string"},
+		{name: "unterminated string", input: `name = "abc`, wantErr: "unterminated string"},
+		{name: "string ending in escape", input: `name = "abc\"`, wantErr: "unterminated string"},
+		{name: "invalid escape", input: `name = "a\qb"`, wantErr: "invalid string"},
+		{name: "single quotes", input: `name = 'a'`, wantErr: "unexpected '\\''"},
+		{name: "invalid number", input: `favorites > 1.2.3`, wantErr: "invalid number"},
+		{name: "unclosed parenthesis", input: `(name = "a"`, wantErr: `expected ")"`},
+		{name: "extra parenthesis", input: `name = "a")`, wantErr: "unexpected"},
+		{name: "dangling and", input: `name = "a" and`, wantErr: "expected a field"},
+		{name: "two comparisons without and", input: `name = "a" name = "b"`, wantErr: "unexpected"},
+		{
+			name:    "parentheses nested deeper than MaxDepth",
+			input:   strings.Repeat("(", MaxDepth+1) + `name = "a"` + strings.Repeat(")", MaxDepth+1),
+			wantErr: "nested deeper than",
+		},
+		{
+			name:    "not nested deeper than MaxDepth",
+			input:   strings.Repeat("not ", MaxDepth+1) + `name = "a"`,
+			wantErr: "nested deeper than",
+		},
+		{
+			name:    "not and parentheses nested deeper than MaxDepth",
+			input:   strings.Repeat("not (", MaxDepth/2+1) + `name = "a"` + strings.Repeat(")", MaxDepth/2+1),
+			wantErr: "nested deeper than",
+		},
+		{
+			name:    "longer than MaxLength",
+			input:   `name = "` + strings.Repeat("a", MaxLength) + `"`,
+			wantErr: "longer than",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			if err == nil {
+				t.Fatalf("Parse(%q) returned no error", tt.input)
+			}
+			if !errors.Is(err, domain.ErrInvalidSegment) {
+				t.Errorf("Parse(%q) error %v does not wrap domain.ErrInvalidSegment", tt.input, err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) error %q does not contain %q", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWalk(t *testing.T) {
+	expr, err := Parse(`not (name = "a" or tags contains "b") and favorites > 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields []string
+	Walk(expr, func(comparison Comparison) {
+		fields = append(fields, comparison.Field.Name)
+	})
+
+	want := []string{FieldName, FieldTags, FieldFavorites}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("Walk visited %v, want %v", fields, want)
+	}
+}
@@ -0,0 +1,283 @@
+package segment
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	tokenEOF = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+)
+
+type token struct {
+	kind int
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text) + " at position " + strconv.Itoa(t.pos+1)
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(input); {
+		ch := rune(input[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+
+		case ch == '(' || ch == ')' || ch == '[' || ch == ']':
+			kind := map[rune]int{'(': tokenLParen, ')': tokenRParen, '[': tokenLBracket, ']': tokenRBracket}[ch]
+			tokens = append(tokens, token{kind: kind, text: string(ch), pos: i})
+			i++
+
+		case ch == '=' || ch == '!' || ch == '<' || ch == '>':
+			op := string(ch)
+			if i+1 < len(input) && input[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, invalid("unexpected \"!\" at position %d", i+1)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+
+		case ch == '"':
+			end := i + 1
+			for end < len(input) && input[end] != '"' {
+				if input[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(input) {
+				return nil, invalid("unterminated string at position %d", i+1)
+			}
+			value, err := strconv.Unquote(input[i : end+1])
+			if err != nil {
+				return nil, invalid("invalid string at position %d", i+1)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value, pos: i})
+			i = end + 1
+
+		case ch == '-' || unicode.IsDigit(ch):
+			end := i + 1
+			for end < len(input) && (unicode.IsDigit(rune(input[end])) || input[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: input[i:end], pos: i})
+			i = end
+
+		case unicode.IsLetter(ch) || ch == '_':
+			end := i + 1
+			for end < len(input) && (isIdentRune(rune(input[end])) || input[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: input[i:end], pos: i})
+			i = end
+
+		default:
+			return nil, invalid("unexpected %q at position %d", ch, i+1)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+func isIdentRune(ch rune) bool {
+	return ch < unicode.MaxASCII && (unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '_')
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) at(kind int) bool {
+	return p.peek().kind == kind
+}
+
+func (p *parser) atKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && strings.EqualFold(t.text, keyword)
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.atKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.atKeyword("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+// enter starts a nested expression, returning an error when it is nested
+// deeper than MaxDepth. leave must be called when it ends.
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > MaxDepth {
+		return invalid("expression is nested deeper than %d levels at position %d", MaxDepth, p.peek().pos+1)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if p.atKeyword("not") || p.at(tokenLParen) {
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+	}
+
+	if p.atKeyword("not") {
+		p.next()
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Expr: expr}, nil
+	}
+
+	if p.at(tokenLParen) {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.at(tokenRParen) {
+			return nil, invalid("expected \")\" instead of %s", p.peek())
+		}
+		p.next()
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	field, err := p.parseField()
+	if err != nil {
+		return nil, err
+	}
+
+	var op string
+	switch t := p.next(); {
+	case t.kind == tokenOperator:
+		op = t.text
+	case t.kind == tokenIdent && strings.EqualFold(t.text, OpContains):
+		op = OpContains
+	default:
+		return nil, invalid("expected an operator instead of %s", t)
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return check(Comparison{Field: field, Op: op, Value: value})
+}
+
+func (p *parser) parseField() (Field, error) {
+	t := p.next()
+	if t.kind != tokenIdent {
+		return Field{}, invalid("expected a field instead of %s", t)
+	}
+
+	name, key, _ := strings.Cut(t.text, ".")
+	switch {
+	case name == FieldAttributes:
+		if key == "" {
+			return Field{}, invalid("expected attributes.<name> at position %d", t.pos+1)
+		}
+		return Field{Name: name, Key: key}, nil
+
+	case key != "":
+		return Field{}, invalid("unknown field %s", t)
+
+	case name == FieldFavorites && p.at(tokenLBracket):
+		p.next()
+		category := p.next()
+		if category.kind != tokenString || !p.at(tokenRBracket) {
+			return Field{}, invalid("expected favorites[\"<category>\"] at position %d", t.pos+1)
+		}
+		p.next()
+		return Field{Name: name, Key: category.text}, nil
+	}
+
+	return Field{Name: name}, nil
+}
+
+func (p *parser) parseValue() (any, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenString:
+		return t.text, nil
+	case t.kind == tokenNumber:
+		value, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, invalid("invalid number %s", t)
+		}
+		return value, nil
+	case t.kind == tokenIdent && (t.text == "true" || t.text == "false"):
+		return t.text == "true", nil
+	}
+	return nil, invalid("expected a value instead of %s", t)
+}
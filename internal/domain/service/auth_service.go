@@ -42,6 +42,7 @@ func (s *AuthService) SignUp(c context.Context, username string, password string
 		ID:       uuid.New().String(),
 		Username: username,
 		Password: string(passwordHash),
+		Role:     model.RoleUser,
 	}
 
 	createdUser, err := s.userRepo.Create(user)
@@ -62,7 +63,7 @@ func (s *AuthService) SignIn(c context.Context, username string, password string
 		return "", errors.New("invalid username or password")
 	}
 
-	token, err := s.tokenService.Generate(user.ID)
+	token, err := s.tokenService.Generate(user.ID, user.Role)
 	if err != nil {
 		return "", err
 	}
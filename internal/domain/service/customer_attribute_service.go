@@ -0,0 +1,181 @@
+package service
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+type CustomerAttributeService struct {
+	attributeRepo *db.CustomerAttributeRepository
+}
+
+func NewCustomerAttributeService(attributeRepo *db.CustomerAttributeRepository) *CustomerAttributeService {
+	return &CustomerAttributeService{attributeRepo: attributeRepo}
+}
+
+func (s *CustomerAttributeService) Create(c context.Context, definition model.CustomerAttributeDefinition) (*model.CustomerAttributeDefinition, error) {
+	if !attributeNamePattern.MatchString(definition.Name) {
+		return nil, fmt.Errorf("%w: name must be lowercase letters, digits and underscores, starting with a letter", domain.ErrInvalidAttributes)
+	}
+
+	switch definition.Type {
+	case model.AttributeTypeString, model.AttributeTypeNumber, model.AttributeTypeInteger:
+	case model.AttributeTypeBoolean:
+		if definition.Enum != nil {
+			return nil, fmt.Errorf("%w: boolean attributes cannot have enum values", domain.ErrInvalidAttributes)
+		}
+	default:
+		return nil, fmt.Errorf("%w: type must be string, number, integer or boolean", domain.ErrInvalidAttributes)
+	}
+
+	if definition.Enum != nil && len(definition.Enum) == 0 {
+		return nil, fmt.Errorf("%w: enum must have at least one value", domain.ErrInvalidAttributes)
+	}
+	for _, value := range definition.Enum {
+		if !attributeHasType(value, definition.Type) {
+			return nil, fmt.Errorf("%w: enum value %v is not a %s", domain.ErrInvalidAttributes, value, definition.Type)
+		}
+	}
+
+	createdDefinition, err := s.attributeRepo.Create(c, definition)
+	if err != nil {
+		return nil, err
+	}
+
+	if createdDefinition == nil {
+		return nil, domain.ErrAttributeAlreadyExists
+	}
+
+	return createdDefinition, nil
+}
+
+func (s *CustomerAttributeService) GetAll(c context.Context) ([]model.CustomerAttributeDefinition, error) {
+	return s.attributeRepo.FindAll(c)
+}
+
+// Delete removes the attribute definition along with its values.
+func (s *CustomerAttributeService) Delete(c context.Context, name string) error {
+	deleted, err := s.attributeRepo.Delete(c, name)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// validateCustomerAttributes checks attributes against the definitions and
+// reports every problem found in a domain.ErrInvalidAttributes error.
+func validateCustomerAttributes(definitions []model.CustomerAttributeDefinition, attributes map[string]any) error {
+	byName := make(map[string]model.CustomerAttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		byName[definition.Name] = definition
+	}
+
+	var problems []string
+	for name, value := range attributes {
+		definition, ok := byName[name]
+		switch {
+		case !ok:
+			problems = append(problems, name+" is not defined")
+		case value == nil:
+			problems = append(problems, name+" must not be null")
+		case !attributeHasType(value, definition.Type):
+			problems = append(problems, name+" must be a "+definition.Type)
+		case definition.Enum != nil && !attributeInEnum(value, definition.Enum):
+			problems = append(problems, fmt.Sprintf("%s must be one of %v", name, definition.Enum))
+		}
+	}
+
+	for _, definition := range definitions {
+		if _, ok := attributes[definition.Name]; definition.Required && !ok {
+			problems = append(problems, definition.Name+" is required")
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", domain.ErrInvalidAttributes, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// parseAttributeFilter converts the raw values of an attribute filter to the
+// types of their definitions.
+func parseAttributeFilter(definitions []model.CustomerAttributeDefinition, raw map[string]string) (map[string]any, error) {
+	byName := make(map[string]model.CustomerAttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		byName[definition.Name] = definition
+	}
+
+	filter := make(map[string]any, len(raw))
+	for name, rawValue := range raw {
+		definition, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not defined", domain.ErrInvalidAttributes, name)
+		}
+
+		var (
+			value any
+			err   error
+		)
+		switch definition.Type {
+		case model.AttributeTypeString:
+			value = rawValue
+		case model.AttributeTypeNumber:
+			value, err = strconv.ParseFloat(rawValue, 64)
+		case model.AttributeTypeInteger:
+			value, err = strconv.ParseInt(rawValue, 10, 64)
+		case model.AttributeTypeBoolean:
+			value, err = strconv.ParseBool(rawValue)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be a %s", domain.ErrInvalidAttributes, name, definition.Type)
+		}
+		filter[name] = value
+	}
+
+	return filter, nil
+}
+
+// attributeHasType reports whether a value decoded from JSON has the
+// attribute type.
+func attributeHasType(value any, attributeType string) bool {
+	switch attributeType {
+	case model.AttributeTypeString:
+		_, ok := value.(string)
+		return ok
+	case model.AttributeTypeNumber:
+		_, ok := value.(float64)
+		return ok
+	case model.AttributeTypeInteger:
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case model.AttributeTypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	}
+	return false
+}
+
+func attributeInEnum(value any, enum []any) bool {
+	for _, allowed := range enum {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
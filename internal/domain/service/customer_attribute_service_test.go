@@ -0,0 +1,52 @@
+package service
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateCustomerAttributes(t *testing.T) {
+	definitions := []model.CustomerAttributeDefinition{
+		{Name: "plan", Type: model.AttributeTypeString, Required: true, Enum: []any{"free", "gold"}},
+		{Name: "score", Type: model.AttributeTypeInteger},
+		{Name: "newsletter", Type: model.AttributeTypeBoolean},
+	}
+
+	tests := []struct {
+		name       string
+		attributes map[string]any
+		wantErr    string
+	}{
+		{name: "valid", attributes: map[string]any{"plan": "gold", "score": 3.0, "newsletter": true}},
+		{name: "only required", attributes: map[string]any{"plan": "free"}},
+		{name: "no attributes, as in imports", attributes: map[string]any{}, wantErr: "plan is required"},
+		{name: "missing required", attributes: map[string]any{"score": 1.0}, wantErr: "plan is required"},
+		{name: "undefined", attributes: map[string]any{"plan": "free", "age": 3.0}, wantErr: "age is not defined"},
+		{name: "null", attributes: map[string]any{"plan": "free", "score": nil}, wantErr: "score must not be null"},
+		{name: "fractional integer", attributes: map[string]any{"plan": "free", "score": 1.5}, wantErr: "score must be a integer"},
+		{name: "wrong type", attributes: map[string]any{"plan": "free", "newsletter": "yes"}, wantErr: "newsletter must be a boolean"},
+		{name: "outside enum", attributes: map[string]any{"plan": "silver"}, wantErr: "plan must be one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCustomerAttributes(definitions, tt.attributes)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateCustomerAttributes returned error: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, domain.ErrInvalidAttributes) {
+				t.Fatalf("error %v does not wrap domain.ErrInvalidAttributes", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+)
+
+type CustomerExportService struct {
+	exportRepo     *db.CustomerExportRepository
+	productService ProductService
+}
+
+func NewCustomerExportService(exportRepo *db.CustomerExportRepository, productService ProductService) *CustomerExportService {
+	return &CustomerExportService{
+		exportRepo:     exportRepo,
+		productService: productService,
+	}
+}
+
+// Export calls fn with every customer matching filter, oldest first. When
+// filter asks for products, each favorite is inlined with a snapshot of the
+// product as the catalog lists it when the export starts; products no longer
+// in the catalog keep only their ID.
+func (s *CustomerExportService) Export(c context.Context, filter model.CustomerExportFilter, fn func(model.CustomerExport) error) error {
+	var products map[int]model.Product
+	if filter.Favorites == model.ExportFavoritesProducts {
+		catalog, err := s.productService.GetAll()
+		if err != nil {
+			return err
+		}
+
+		products = make(map[int]model.Product, len(catalog))
+		for _, product := range catalog {
+			products[product.ID] = product
+		}
+	}
+
+	return s.exportRepo.Stream(c, filter, func(export model.CustomerExport) error {
+		if products != nil {
+			export.FavoriteProducts = make([]model.Product, 0, len(export.FavoriteProductIDs))
+			for _, productID := range export.FavoriteProductIDs {
+				product, ok := products[productID]
+				if !ok {
+					product = model.Product{ID: productID}
+				}
+				export.FavoriteProducts = append(export.FavoriteProducts, product)
+			}
+		}
+
+		return fn(export)
+	})
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const maxImportLineBytes = 1 << 20
+
+// readCustomerImport calls fn with every record of payload, in order. Records
+// that cannot be parsed are passed with a non-nil parse error. It fails with
+// domain.ErrInvalidImport when the file as a whole is unusable.
+func readCustomerImport(format string, payload []byte, fn func(row model.CustomerImportRow, parseErr error) error) error {
+	switch format {
+	case model.ImportFormatCSV:
+		return readCustomerImportCSV(payload, fn)
+	case model.ImportFormatNDJSON:
+		return readCustomerImportNDJSON(payload, fn)
+	}
+	return fmt.Errorf("%w: unsupported format %q", domain.ErrInvalidImport, format)
+}
+
+func readCustomerImportCSV(payload []byte, fn func(row model.CustomerImportRow, parseErr error) error) error {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(payload, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("%w: missing CSV header", domain.ErrInvalidImport)
+	}
+
+	nameColumn, emailColumn := -1, -1
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "name":
+			nameColumn = i
+		case "email":
+			emailColumn = i
+		}
+	}
+	if nameColumn < 0 || emailColumn < 0 {
+		return fmt.Errorf("%w: CSV header must include name and email columns", domain.ErrInvalidImport)
+	}
+
+	for rowNumber := 1; ; rowNumber++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		row := model.CustomerImportRow{Row: rowNumber}
+		var parseErr error
+		var csvErr *csv.ParseError
+		switch {
+		case errors.As(err, &csvErr):
+			parseErr = csvErr.Err
+		case err != nil:
+			return err
+		case len(record) <= nameColumn || len(record) <= emailColumn:
+			parseErr = errors.New("missing columns")
+		default:
+			row.Name = record[nameColumn]
+			row.Email = record[emailColumn]
+		}
+
+		if err := fn(row, parseErr); err != nil {
+			return err
+		}
+	}
+}
+
+func readCustomerImportNDJSON(payload []byte, fn func(row model.CustomerImportRow, parseErr error) error) error {
+	scanner := bufio.NewScanner(bytes.NewReader(payload))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineBytes)
+
+	rowNumber := 0
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		rowNumber++
+
+		var record struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		}
+		row := model.CustomerImportRow{Row: rowNumber}
+		parseErr := json.Unmarshal(line, &record)
+		if parseErr == nil {
+			row.Name = record.Name
+			row.Email = record.Email
+		} else {
+			parseErr = errors.New("invalid JSON")
+		}
+
+		if err := fn(row, parseErr); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidImport, err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CustomerRowValidator checks one imported row with the same rules applied
+// when a customer is created through the API.
+type CustomerRowValidator func(name string, email string) []model.CustomerImportRowError
+
+type CustomerImportService struct {
+	importRepo    *db.CustomerImportRepository
+	attributeRepo *db.CustomerAttributeRepository
+	validate      CustomerRowValidator
+	maxRows       int
+	batchSize     int
+	staleAfter    time.Duration
+}
+
+// NewCustomerImportService creates the service. Imports are applied
+// batchSize rows per transaction, and a running import is taken over by
+// another worker when it makes no progress for staleAfter.
+func NewCustomerImportService(
+	importRepo *db.CustomerImportRepository,
+	attributeRepo *db.CustomerAttributeRepository,
+	validate CustomerRowValidator,
+	maxRows int,
+	batchSize int,
+	staleAfter time.Duration,
+) *CustomerImportService {
+	return &CustomerImportService{
+		importRepo:    importRepo,
+		attributeRepo: attributeRepo,
+		validate:      validate,
+		maxRows:       maxRows,
+		batchSize:     batchSize,
+		staleAfter:    staleAfter,
+	}
+}
+
+// Start checks that payload is a readable file of the given format and
+// queues it for import.
+func (s *CustomerImportService) Start(c context.Context, userID string, format string, dryRun bool, payload []byte) (*model.CustomerImport, error) {
+	totalRows := 0
+	err := readCustomerImport(format, payload, func(model.CustomerImportRow, error) error {
+		totalRows++
+		if totalRows > s.maxRows {
+			return fmt.Errorf("%w: more than %d rows", domain.ErrInvalidImport, s.maxRows)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if totalRows == 0 {
+		return nil, fmt.Errorf("%w: no rows", domain.ErrInvalidImport)
+	}
+
+	return s.importRepo.Create(c, model.CustomerImport{
+		ID:        uuid.New().String(),
+		Format:    format,
+		DryRun:    dryRun,
+		Payload:   payload,
+		CreatedBy: userID,
+		TotalRows: totalRows,
+	})
+}
+
+func (s *CustomerImportService) GetByID(c context.Context, id string) (*model.CustomerImport, error) {
+	return s.importRepo.FindByID(c, id)
+}
+
+// GetErrors returns one page of the row errors of an import, in row order.
+// Pages start at 1.
+func (s *CustomerImportService) GetErrors(c context.Context, id string, page int, pageSize int) ([]model.CustomerImportRowError, int, error) {
+	rowErrors, total, err := s.importRepo.FindErrors(c, id, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if rowErrors == nil {
+		rowErrors = []model.CustomerImportRowError{}
+	}
+
+	return rowErrors, total, nil
+}
+
+// ProcessNext runs the oldest queued import to completion. It reports whether
+// there was an import to run. Progress is committed batch by batch, so an
+// import interrupted midway resumes after its last committed batch.
+func (s *CustomerImportService) ProcessNext(c context.Context) (bool, error) {
+	customerImport, err := s.importRepo.ClaimNext(c, s.staleAfter)
+	if err != nil || customerImport == nil {
+		return false, err
+	}
+
+	status, errorMessage := model.ImportStatusCompleted, ""
+	if err := s.process(c, customerImport); err != nil {
+		if c.Err() != nil {
+			// Shutting down; another worker resumes the import once it is stale.
+			return true, err
+		}
+		log.Println("Error processing customer import", customerImport.ID, err)
+		status, errorMessage = model.ImportStatusFailed, "import failed"
+		if errors.Is(err, domain.ErrInvalidImport) {
+			errorMessage = err.Error()
+		}
+	}
+
+	return true, s.importRepo.Finish(c, customerImport.ID, status, errorMessage)
+}
+
+// process validates each row like a customer created through the API,
+// attributes included. Rows carry no attributes, so they all fail while a
+// required attribute is defined.
+func (s *CustomerImportService) process(c context.Context, customerImport *model.CustomerImport) error {
+	definitions, err := s.attributeRepo.FindAll(c)
+	if err != nil {
+		return err
+	}
+
+	var (
+		rows      []model.CustomerImportRow
+		rowErrors []model.CustomerImportRowError
+		lastRow   int
+	)
+	seenEmails := make(map[string]int)
+
+	flush := func() error {
+		if lastRow <= customerImport.ProcessedRows {
+			return nil
+		}
+		err := s.importRepo.ApplyBatch(c, customerImport.ID, rows, rowErrors, lastRow, customerImport.DryRun)
+		rows, rowErrors = nil, nil
+		return err
+	}
+
+	err = readCustomerImport(customerImport.Format, customerImport.Payload, func(row model.CustomerImportRow, parseErr error) error {
+		lastRow = row.Row
+		resumed := row.Row <= customerImport.ProcessedRows
+
+		switch {
+		case parseErr != nil:
+			rowErrors = append(rowErrors, model.CustomerImportRowError{Row: row.Row, Message: parseErr.Error()})
+		default:
+			row.Name = strings.TrimSpace(row.Name)
+			row.Email = domain.NormalizeEmail(row.Email)
+
+			if invalid := s.validate(row.Name, row.Email); len(invalid) > 0 {
+				for _, rowError := range invalid {
+					rowError.Row = row.Row
+					rowErrors = append(rowErrors, rowError)
+				}
+				break
+			}
+
+			if err := validateCustomerAttributes(definitions, map[string]any{}); err != nil {
+				rowErrors = append(rowErrors, model.CustomerImportRowError{
+					Row:     row.Row,
+					Field:   "attributes",
+					Message: strings.TrimPrefix(err.Error(), domain.ErrInvalidAttributes.Error()+": "),
+				})
+				break
+			}
+
+			key := strings.ToLower(row.Email)
+			if firstRow, ok := seenEmails[key]; ok {
+				rowErrors = append(rowErrors, model.CustomerImportRowError{
+					Row:     row.Row,
+					Field:   "email",
+					Message: fmt.Sprintf("duplicate of row %d", firstRow),
+				})
+				break
+			}
+			seenEmails[key] = row.Row
+			rows = append(rows, row)
+		}
+
+		if resumed {
+			// Already committed by a previous attempt; only the seen emails matter.
+			rows, rowErrors = nil, nil
+			return nil
+		}
+
+		if len(rows)+len(rowErrors) >= s.batchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return flush()
+}
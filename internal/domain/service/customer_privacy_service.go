@@ -0,0 +1,55 @@
+package service
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+)
+
+type CustomerPrivacyService struct {
+	privacyRepo *db.CustomerPrivacyRepository
+}
+
+func NewCustomerPrivacyService(privacyRepo *db.CustomerPrivacyRepository) *CustomerPrivacyService {
+	return &CustomerPrivacyService{privacyRepo: privacyRepo}
+}
+
+// DataExport returns everything stored about the customer, including deleted
+// and erased customers.
+func (s *CustomerPrivacyService) DataExport(c context.Context, customerID string) (*model.CustomerDataExport, error) {
+	export, err := s.privacyRepo.FindDataExport(c, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if export == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	return export, nil
+}
+
+func (s *CustomerPrivacyService) Erase(c context.Context, customerID string, erasedBy string) (*model.CustomerErasureReceipt, error) {
+	return s.privacyRepo.Erase(c, customerID, erasedBy)
+}
+
+// VerifyErasureReceipts checks the receipt chain. It returns the number of
+// receipts checked and the first receipt whose hash or link to the previous
+// receipt does not match, or nil when the chain is intact.
+func (s *CustomerPrivacyService) VerifyErasureReceipts(c context.Context) (int, *model.CustomerErasureReceipt, error) {
+	receipts, err := s.privacyRepo.FindErasureReceipts(c)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	previousHash := domain.GenesisErasureHash
+	for i, receipt := range receipts {
+		if receipt.PreviousHash != previousHash || domain.ErasureReceiptHash(receipt) != receipt.Hash {
+			return i + 1, &receipts[i], nil
+		}
+		previousHash = receipt.Hash
+	}
+
+	return len(receipts), nil, nil
+}
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"app/internal/domain/model"
+	"context"
+)
+
+// CustomerSearchIndex finds active customers whose name or email resemble
+// query, best matches first.
+type CustomerSearchIndex interface {
+	Search(c context.Context, query string, limit int, offset int) ([]model.CustomerMatch, int, error)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/domain/segment"
+	"app/internal/infra/db"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type CustomerSegmentService struct {
+	segmentRepo    *db.CustomerSegmentRepository
+	attributeRepo  *db.CustomerAttributeRepository
+	productService ProductService
+}
+
+func NewCustomerSegmentService(
+	segmentRepo *db.CustomerSegmentRepository,
+	attributeRepo *db.CustomerAttributeRepository,
+	productService ProductService,
+) *CustomerSegmentService {
+	return &CustomerSegmentService{
+		segmentRepo:    segmentRepo,
+		attributeRepo:  attributeRepo,
+		productService: productService,
+	}
+}
+
+func (s *CustomerSegmentService) Create(c context.Context, customerSegment model.CustomerSegment) (*model.CustomerSegment, error) {
+	if _, err := s.parse(c, customerSegment.Expression); err != nil {
+		return nil, err
+	}
+
+	customerSegment.ID = uuid.New().String()
+	return s.segmentRepo.Create(c, customerSegment)
+}
+
+func (s *CustomerSegmentService) GetAll(c context.Context) ([]model.CustomerSegment, error) {
+	return s.segmentRepo.FindAll(c)
+}
+
+func (s *CustomerSegmentService) GetByID(c context.Context, id string) (*model.CustomerSegment, error) {
+	customerSegment, err := s.segmentRepo.FindByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if customerSegment == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	return customerSegment, nil
+}
+
+func (s *CustomerSegmentService) Update(c context.Context, customerSegment model.CustomerSegment) (*model.CustomerSegment, error) {
+	if _, err := s.parse(c, customerSegment.Expression); err != nil {
+		return nil, err
+	}
+
+	updatedSegment, err := s.segmentRepo.Update(c, customerSegment)
+	if err != nil {
+		return nil, err
+	}
+
+	if updatedSegment == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	return updatedSegment, nil
+}
+
+func (s *CustomerSegmentService) Delete(c context.Context, id string) error {
+	deleted, err := s.segmentRepo.Delete(c, id)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// Members evaluates the segment and returns one page of its customers,
+// oldest first. Pages start at 1.
+func (s *CustomerSegmentService) Members(c context.Context, id string, page int, pageSize int) (*model.CustomerPage, error) {
+	customerSegment, err := s.GetByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := s.parse(c, customerSegment.Expression)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.productCategories(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	customers, total, err := s.segmentRepo.FindMembers(c, expr, categories, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CustomerPage{
+		Items:    customers,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+// Preview returns the number of customers matching expression without
+// saving it.
+func (s *CustomerSegmentService) Preview(c context.Context, expression string) (int, error) {
+	expr, err := s.parse(c, expression)
+	if err != nil {
+		return 0, err
+	}
+
+	categories, err := s.productCategories(expr)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.segmentRepo.CountMembers(c, expr, categories)
+}
+
+// parse parses expression and checks that the attributes it uses are
+// defined.
+func (s *CustomerSegmentService) parse(c context.Context, expression string) (segment.Expr, error) {
+	expr, err := segment.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	definitions, err := s.attributeRepo.FindAll(c)
+	if err != nil {
+		return nil, err
+	}
+
+	defined := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		defined[definition.Name] = true
+	}
+
+	segment.Walk(expr, func(comparison segment.Comparison) {
+		if err == nil && comparison.Field.Name == segment.FieldAttributes && !defined[comparison.Field.Key] {
+			err = fmt.Errorf("%w: attribute %s is not defined", domain.ErrInvalidSegment, comparison.Field.Key)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return expr, nil
+}
+
+// productCategories maps product IDs to their categories in the catalog when
+// expr counts favorites by category, and returns nil otherwise.
+func (s *CustomerSegmentService) productCategories(expr segment.Expr) (map[int]string, error) {
+	byCategory := false
+	segment.Walk(expr, func(comparison segment.Comparison) {
+		byCategory = byCategory || (comparison.Field.Name == segment.FieldFavorites && comparison.Field.Key != "")
+	})
+	if !byCategory {
+		return nil, nil
+	}
+
+	products, err := s.productService.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[int]string, len(products))
+	for _, product := range products {
+		categories[product.ID] = product.Category
+	}
+
+	return categories, nil
+}
@@ -5,19 +5,54 @@ import (
 	"app/internal/domain/model"
 	"app/internal/infra/db"
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type CustomerService struct {
-	customerRepo *db.CustomerRepository
+	customerRepo  *db.CustomerRepository
+	attributeRepo *db.CustomerAttributeRepository
+	searchIndex   CustomerSearchIndex
+	transactor    *db.Transactor
+	outboxRepo    *db.OutboxRepository
+	gracePeriod   time.Duration
 }
 
-func NewCustomerService(repo *db.CustomerRepository) *CustomerService {
-	return &CustomerService{customerRepo: repo}
+// NewCustomerService creates the service. Deleted customers can be restored
+// during gracePeriod, after which they are purged.
+func NewCustomerService(
+	repo *db.CustomerRepository,
+	attributeRepo *db.CustomerAttributeRepository,
+	searchIndex CustomerSearchIndex,
+	transactor *db.Transactor,
+	outboxRepo *db.OutboxRepository,
+	gracePeriod time.Duration,
+) *CustomerService {
+	return &CustomerService{
+		customerRepo:  repo,
+		attributeRepo: attributeRepo,
+		searchIndex:   searchIndex,
+		transactor:    transactor,
+		outboxRepo:    outboxRepo,
+		gracePeriod:   gracePeriod,
+	}
 }
 
-func (s *CustomerService) Create(c context.Context, name string, email string) (*model.Customer, error) {
+// Create adds a customer in state, which defaults to active when empty.
+func (s *CustomerService) Create(
+	c context.Context,
+	name string,
+	email string,
+	attributes map[string]any,
+	state model.CustomerState,
+) (*model.Customer, error) {
+	email = domain.NormalizeEmail(email)
+
+	if err := s.validateAttributes(c, attributes); err != nil {
+		return nil, err
+	}
+
 	emailExists, err := s.customerRepo.FindByEmail(c, email, "")
 	if err != nil {
 		return nil, err
@@ -27,12 +62,26 @@ func (s *CustomerService) Create(c context.Context, name string, email string) (
 	}
 
 	customer := model.Customer{
-		ID:    uuid.New().String(),
-		Name:  name,
-		Email: email,
+		ID:         uuid.New().String(),
+		Name:       name,
+		Email:      email,
+		Attributes: attributes,
+		State:      state,
 	}
+	if customer.State == "" {
+		customer.State = model.CustomerStateActive
+	}
+
+	var createdCustomer *model.Customer
+	err = s.transactor.Do(c, func(c context.Context) error {
+		var err error
+		createdCustomer, err = s.customerRepo.Create(c, customer)
+		if err != nil {
+			return err
+		}
 
-	createdCustomer, err := s.customerRepo.Create(c, customer)
+		return recordEvent(c, s.outboxRepo, model.EventCustomerCreated, createdCustomer.ID, createdCustomer)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -44,19 +93,134 @@ func (s *CustomerService) GetByID(c context.Context, id string) (*model.Customer
 	return s.customerRepo.FindByID(c, id)
 }
 
-func (s *CustomerService) GetAll(c context.Context) ([]model.Customer, error) {
-	return s.customerRepo.FindAll(c)
+// GetAll returns the customers that are not deleted, only those in state when
+// it is not empty and those with the given attribute values when
+// attributeFilter is not empty. Filter values are parsed with the type of
+// their attribute.
+func (s *CustomerService) GetAll(
+	c context.Context,
+	attributeFilter map[string]string,
+	state model.CustomerState,
+) ([]model.Customer, error) {
+	if len(attributeFilter) == 0 {
+		return s.customerRepo.FindAll(c, state)
+	}
+
+	definitions, err := s.attributeRepo.FindAll(c)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := parseAttributeFilter(definitions, attributeFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.customerRepo.FindByAttributes(c, filter, state)
 }
 
-func (s *CustomerService) Update(c context.Context, id string, name string, email string) error {
-	emailExists, err := s.customerRepo.FindByEmail(c, email, id)
+// Search returns one page of the customers matching query, best matches
+// first. Pages start at 1.
+func (s *CustomerService) Search(c context.Context, query string, page int, pageSize int) (*model.CustomerSearchPage, error) {
+	matches, total, err := s.searchIndex.Search(c, query, pageSize, (page-1)*pageSize)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if emailExists != nil {
-		return domain.ErrEmailAlreadyExists
+
+	if matches == nil {
+		matches = []model.CustomerMatch{}
+	}
+
+	return &model.CustomerSearchPage{
+		Items:    matches,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+// Update replaces the customer's name, email and, unless nil, attributes.
+// When expectedVersion is not zero the update only succeeds if it is still
+// the customer's version.
+func (s *CustomerService) Update(
+	c context.Context,
+	id string,
+	name string,
+	email string,
+	attributes map[string]any,
+	expectedVersion int,
+) (*model.Customer, error) {
+	return s.Modify(c, id, expectedVersion, func(customer *model.Customer) error {
+		customer.Name = name
+		customer.Email = email
+		if attributes != nil {
+			customer.Attributes = attributes
+		}
+		return nil
+	})
+}
+
+// Modify applies change to the customer's name, email and attributes and
+// stores the result. The customer is read and locked in the transaction that
+// stores it, so concurrent changes are applied one after the other. When
+// expectedVersion is not zero the change only succeeds if it is still the
+// customer's version.
+func (s *CustomerService) Modify(
+	c context.Context,
+	id string,
+	expectedVersion int,
+	change func(customer *model.Customer) error,
+) (*model.Customer, error) {
+	var updatedCustomer *model.Customer
+	err := s.transactor.Do(c, func(c context.Context) error {
+		customer, err := s.customerRepo.FindByIDForUpdate(c, id)
+		if err != nil {
+			return err
+		}
+
+		if customer == nil {
+			return domain.ErrNotFound
+		}
+
+		if expectedVersion != 0 && customer.Version != expectedVersion {
+			return domain.ErrVersionConflict
+		}
+
+		if err := change(customer); err != nil {
+			return err
+		}
+		customer.Email = domain.NormalizeEmail(customer.Email)
+
+		emailExists, err := s.customerRepo.FindByEmail(c, customer.Email, id)
+		if err != nil {
+			return err
+		}
+		if emailExists != nil {
+			return domain.ErrEmailAlreadyExists
+		}
+
+		if err := s.validateAttributes(c, customer.Attributes); err != nil {
+			return err
+		}
+
+		updatedCustomer, err = s.customerRepo.Update(c, *customer)
+		if err != nil {
+			return err
+		}
+		if updatedCustomer == nil {
+			return domain.ErrNotFound
+		}
+
+		return recordEvent(c, s.outboxRepo, model.EventCustomerUpdated, id, updatedCustomer)
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	return updatedCustomer, nil
+}
+
+func (s *CustomerService) Delete(c context.Context, id string) error {
 	customer, err := s.customerRepo.FindByID(c, id)
 	if err != nil {
 		return err
@@ -66,21 +230,69 @@ func (s *CustomerService) Update(c context.Context, id string, name string, emai
 		return domain.ErrNotFound
 	}
 
-	customer.Name = name
-	customer.Email = email
+	return s.transactor.Do(c, func(c context.Context) error {
+		if err := s.customerRepo.Delete(c, id); err != nil {
+			return err
+		}
 
-	return s.customerRepo.Update(c, *customer)
+		return recordEvent(c, s.outboxRepo, model.EventCustomerDeleted, id, model.CustomerRefEventPayload{ID: id})
+	})
 }
 
-func (s *CustomerService) Delete(c context.Context, id string) error {
-	customer, err := s.customerRepo.FindByID(c, id)
+func (s *CustomerService) GetDeleted(c context.Context) ([]model.Customer, error) {
+	return s.customerRepo.FindDeleted(c)
+}
+
+func (s *CustomerService) Restore(c context.Context, id string) (*model.Customer, error) {
+	customer, err := s.customerRepo.FindDeletedByID(c, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if customer == nil {
-		return domain.ErrNotFound
+		return nil, domain.ErrNotFound
+	}
+
+	emailExists, err := s.customerRepo.FindByEmail(c, customer.Email, id)
+	if err != nil {
+		return nil, err
+	}
+	if emailExists != nil {
+		return nil, domain.ErrEmailAlreadyExists
+	}
+
+	var restoredCustomer *model.Customer
+	err = s.transactor.Do(c, func(c context.Context) error {
+		var err error
+		restoredCustomer, err = s.customerRepo.Restore(c, id, s.gracePeriod)
+		if err != nil || restoredCustomer == nil {
+			return err
+		}
+
+		return recordEvent(c, s.outboxRepo, model.EventCustomerRestored, id, restoredCustomer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if restoredCustomer == nil {
+		return nil, domain.ErrRestoreExpired
+	}
+
+	return restoredCustomer, nil
+}
+
+// PurgeDeleted permanently removes customers deleted longer than the grace
+// period ago, along with their favorites.
+func (s *CustomerService) PurgeDeleted(c context.Context) (int64, error) {
+	return s.customerRepo.PurgeDeleted(c, s.gracePeriod)
+}
+
+func (s *CustomerService) validateAttributes(c context.Context, attributes map[string]any) error {
+	definitions, err := s.attributeRepo.FindAll(c)
+	if err != nil {
+		return err
 	}
 
-	return s.customerRepo.Delete(c, id)
+	return validateCustomerAttributes(definitions, attributes)
 }
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type CustomerStateService struct {
+	stateRepo    *db.CustomerStateRepository
+	customerRepo *db.CustomerRepository
+	transactor   *db.Transactor
+	outboxRepo   *db.OutboxRepository
+}
+
+func NewCustomerStateService(
+	stateRepo *db.CustomerStateRepository,
+	customerRepo *db.CustomerRepository,
+	transactor *db.Transactor,
+	outboxRepo *db.OutboxRepository,
+) *CustomerStateService {
+	return &CustomerStateService{
+		stateRepo:    stateRepo,
+		customerRepo: customerRepo,
+		transactor:   transactor,
+		outboxRepo:   outboxRepo,
+	}
+}
+
+// Transition moves the customer to state on behalf of actor. It returns
+// domain.ErrInvalidTransition when the current state cannot move to state and
+// domain.ErrVersionConflict when the customer changed since expectedVersion,
+// unless it is zero.
+func (s *CustomerStateService) Transition(
+	c context.Context,
+	customerID string,
+	state model.CustomerState,
+	reason string,
+	actor string,
+	expectedVersion int,
+) (*model.Customer, error) {
+	customer, err := s.customerRepo.FindByID(c, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if customer == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	if expectedVersion != 0 && customer.Version != expectedVersion {
+		return nil, domain.ErrVersionConflict
+	}
+
+	if !customer.State.CanTransitionTo(state) {
+		return nil, domain.ErrInvalidTransition
+	}
+
+	transition := model.CustomerStateTransition{
+		ID:         uuid.New().String(),
+		CustomerID: customerID,
+		From:       customer.State,
+		To:         state,
+		Reason:     reason,
+		Actor:      actor,
+	}
+
+	var updatedCustomer *model.Customer
+	err = s.transactor.Do(c, func(c context.Context) error {
+		var err error
+		updatedCustomer, err = s.stateRepo.Transition(c, transition, customer.Version)
+		if err != nil || updatedCustomer == nil {
+			return err
+		}
+
+		return recordEvent(c, s.outboxRepo, model.EventCustomerStateChanged, customerID, transition)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if updatedCustomer == nil {
+		return nil, domain.ErrVersionConflict
+	}
+
+	return updatedCustomer, nil
+}
+
+func (s *CustomerStateService) GetTransitions(c context.Context, customerID string) ([]model.CustomerStateTransition, error) {
+	customer, err := s.customerRepo.FindByID(c, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if customer == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	return s.stateRepo.FindByCustomerID(c, customerID)
+}
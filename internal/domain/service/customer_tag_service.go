@@ -0,0 +1,83 @@
+package service
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+	"regexp"
+	"strings"
+)
+
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N} _:.-]{0,49}$`)
+
+type CustomerTagService struct {
+	tagRepo      *db.CustomerTagRepository
+	customerRepo *db.CustomerRepository
+}
+
+func NewCustomerTagService(tagRepo *db.CustomerTagRepository, customerRepo *db.CustomerRepository) *CustomerTagService {
+	return &CustomerTagService{tagRepo: tagRepo, customerRepo: customerRepo}
+}
+
+// NormalizeTag trims and lowercases tag, so tags differing only in case are
+// the same.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+func (s *CustomerTagService) GetByCustomerID(c context.Context, customerID string) ([]model.CustomerTag, error) {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return nil, err
+	}
+
+	return s.tagRepo.FindByCustomerID(c, customerID)
+}
+
+// Add tags the customer. Adding a tag the customer already has does nothing.
+func (s *CustomerTagService) Add(c context.Context, customerID string, tag string) error {
+	tag = NormalizeTag(tag)
+	if !tagPattern.MatchString(tag) {
+		return domain.ErrInvalidTag
+	}
+
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return err
+	}
+
+	return s.tagRepo.Add(c, customerID, tag)
+}
+
+func (s *CustomerTagService) Remove(c context.Context, customerID string, tag string) error {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return err
+	}
+
+	removed, err := s.tagRepo.Remove(c, customerID, NormalizeTag(tag))
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *CustomerTagService) CountByTag(c context.Context) ([]model.TagCount, error) {
+	return s.tagRepo.CountByTag(c)
+}
+
+func (s *CustomerTagService) ensureCustomerExists(c context.Context, customerID string) error {
+	customer, err := s.customerRepo.FindByID(c, customerID)
+	if err != nil {
+		return err
+	}
+
+	if customer == nil {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
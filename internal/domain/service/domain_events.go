@@ -0,0 +1,17 @@
+package service
+
+import (
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+)
+
+// recordEvent stores an event in the outbox. Called inside a transaction it
+// is stored only if the change it describes is committed.
+func recordEvent(c context.Context, outboxRepo *db.OutboxRepository, eventType string, aggregateID string, payload any) error {
+	event, err := model.NewDomainEvent(eventType, aggregateID, payload)
+	if err != nil {
+		return err
+	}
+	return outboxRepo.Add(c, event)
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+	"log"
+)
+
+type FavoriteAnalyticsService struct {
+	analyticsRepo  *db.FavoriteAnalyticsRepository
+	productService ProductService
+}
+
+func NewFavoriteAnalyticsService(analyticsRepo *db.FavoriteAnalyticsRepository, productService ProductService) *FavoriteAnalyticsService {
+	return &FavoriteAnalyticsService{
+		analyticsRepo:  analyticsRepo,
+		productService: productService,
+	}
+}
+
+// Refresh recomputes the analytics. It returns false when another replica
+// was already refreshing them.
+func (s *FavoriteAnalyticsService) Refresh(c context.Context) (bool, error) {
+	return s.analyticsRepo.Refresh(c)
+}
+
+// GetPopular returns up to limit products by favorites added in the last
+// days, today included.
+func (s *FavoriteAnalyticsService) GetPopular(c context.Context, days int, limit int) (*model.PopularProducts, error) {
+	refreshedAt, err := s.analyticsRepo.RefreshedAt(c)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.analyticsRepo.FindPopular(c, days, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := s.productTitles()
+	for i := range products {
+		products[i].Title = titles[products[i].ProductID]
+	}
+
+	return &model.PopularProducts{
+		Days:        days,
+		Items:       products,
+		RefreshedAt: refreshedAt,
+	}, nil
+}
+
+// GetActivity returns the favorites added and removed on each of the last
+// days, today included, oldest first.
+func (s *FavoriteAnalyticsService) GetActivity(c context.Context, days int) (*model.FavoriteActivity, error) {
+	refreshedAt, err := s.analyticsRepo.RefreshedAt(c)
+	if err != nil {
+		return nil, err
+	}
+
+	activity, err := s.analyticsRepo.FindDailyActivity(c, days)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.FavoriteActivity{
+		Days:        days,
+		Items:       activity,
+		RefreshedAt: refreshedAt,
+	}, nil
+}
+
+func (s *FavoriteAnalyticsService) GetSummary(c context.Context) (*model.FavoriteSummary, error) {
+	refreshedAt, err := s.analyticsRepo.RefreshedAt(c)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.analyticsRepo.FindSummary(c)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.RefreshedAt = refreshedAt
+	if summary.Customers > 0 {
+		summary.AveragePerCustomer = float64(summary.Favorites) / float64(summary.Customers)
+	}
+	if summary.CustomersWithFavorites > 0 {
+		summary.AveragePerCustomerWithFavorites = float64(summary.Favorites) / float64(summary.CustomersWithFavorites)
+	}
+
+	return summary, nil
+}
+
+// GetProductCounts returns one page of the favorited products with their
+// catalog titles, the most favorited first.
+func (s *FavoriteAnalyticsService) GetProductCounts(c context.Context, page int, pageSize int) (*model.ProductFavoriteCountPage, error) {
+	refreshedAt, err := s.analyticsRepo.RefreshedAt(c)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, total, err := s.analyticsRepo.FindProductCounts(c, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := s.productTitles()
+	for i := range counts {
+		counts[i].Title = titles[counts[i].ProductID]
+	}
+
+	return &model.ProductFavoriteCountPage{
+		Items:       counts,
+		Total:       total,
+		Page:        page,
+		PageSize:    pageSize,
+		RefreshedAt: refreshedAt,
+	}, nil
+}
+
+// productTitles returns the titles of the catalog by product ID. The counts
+// do not depend on the catalog, so they are returned without titles when it
+// is unavailable.
+func (s *FavoriteAnalyticsService) productTitles() map[int]string {
+	products, err := s.productService.GetAll()
+	if err != nil {
+		log.Println("Error fetching products for favorite analytics", err)
+		return nil
+	}
+
+	titles := make(map[int]string, len(products))
+	for _, product := range products {
+		titles[product.ID] = product.Title
+	}
+	return titles
+}
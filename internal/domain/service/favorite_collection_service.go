@@ -0,0 +1,196 @@
+package service
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type FavoriteCollectionService struct {
+	collectionRepo *db.FavoriteCollectionRepository
+	customerRepo   *db.CustomerRepository
+	productService ProductService
+}
+
+func NewFavoriteCollectionService(
+	collectionRepo *db.FavoriteCollectionRepository,
+	customerRepo *db.CustomerRepository,
+	productService ProductService,
+) *FavoriteCollectionService {
+	return &FavoriteCollectionService{
+		collectionRepo: collectionRepo,
+		customerRepo:   customerRepo,
+		productService: productService,
+	}
+}
+
+// Create returns domain.ErrCollectionAlreadyExists when the customer has a
+// collection with the same name, ignoring case.
+func (s *FavoriteCollectionService) Create(c context.Context, collection model.FavoriteCollection) (*model.FavoriteCollection, error) {
+	if err := s.ensureCustomerExists(c, collection.CustomerID); err != nil {
+		return nil, err
+	}
+
+	collection.ID = uuid.New().String()
+	return s.collectionRepo.Create(c, collection)
+}
+
+func (s *FavoriteCollectionService) GetByCustomerID(c context.Context, customerID string) ([]model.FavoriteCollection, error) {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return nil, err
+	}
+
+	return s.collectionRepo.FindByCustomerID(c, customerID)
+}
+
+// GetByID returns domain.ErrNotFound when the customer or the collection does
+// not exist.
+func (s *FavoriteCollectionService) GetByID(c context.Context, customerID string, id string) (*model.FavoriteCollection, error) {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return nil, err
+	}
+
+	collection, err := s.collectionRepo.FindByID(c, customerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	return collection, nil
+}
+
+func (s *FavoriteCollectionService) Update(c context.Context, collection model.FavoriteCollection) (*model.FavoriteCollection, error) {
+	if err := s.ensureCustomerExists(c, collection.CustomerID); err != nil {
+		return nil, err
+	}
+
+	updatedCollection, err := s.collectionRepo.Update(c, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	if updatedCollection == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	return updatedCollection, nil
+}
+
+// Delete removes the collection. Its products stay favorites of the customer.
+func (s *FavoriteCollectionService) Delete(c context.Context, customerID string, id string) error {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return err
+	}
+
+	deleted, err := s.collectionRepo.Delete(c, customerID, id)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// Reorder positions the collections in the order of ids, which must list
+// every collection of the customer once.
+func (s *FavoriteCollectionService) Reorder(c context.Context, customerID string, ids []string) ([]model.FavoriteCollection, error) {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return nil, err
+	}
+
+	reordered, err := s.collectionRepo.Reorder(c, customerID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if !reordered {
+		return nil, domain.ErrInvalidCollectionOrder
+	}
+
+	return s.collectionRepo.FindByCustomerID(c, customerID)
+}
+
+// AddFavorite adds a favorite of the customer to the collection. It returns
+// domain.ErrFavoriteNotFound when the product is not a favorite.
+func (s *FavoriteCollectionService) AddFavorite(c context.Context, customerID string, id string, productID int) error {
+	if _, err := s.GetByID(c, customerID, id); err != nil {
+		return err
+	}
+
+	return s.collectionRepo.AddItem(c, customerID, id, productID)
+}
+
+// RemoveFavorite removes the product from the collection only. It returns
+// domain.ErrFavoriteNotFound when the product is not in the collection.
+func (s *FavoriteCollectionService) RemoveFavorite(c context.Context, customerID string, id string, productID int) error {
+	if _, err := s.GetByID(c, customerID, id); err != nil {
+		return err
+	}
+
+	removed, err := s.collectionRepo.RemoveItem(c, id, productID)
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return domain.ErrFavoriteNotFound
+	}
+
+	return nil
+}
+
+// GetProducts returns the products of the collection as they are in the
+// catalog, in the order they were added.
+func (s *FavoriteCollectionService) GetProducts(c context.Context, customerID string, id string) ([]model.Product, error) {
+	if _, err := s.GetByID(c, customerID, id); err != nil {
+		return nil, err
+	}
+
+	productIDs, err := s.collectionRepo.FindProductIDs(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.productService.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := make(map[int]model.Product, len(products))
+	for _, product := range products {
+		catalog[product.ID] = product
+	}
+
+	collectionProducts := []model.Product{}
+	for _, productID := range productIDs {
+		if product, ok := catalog[productID]; ok {
+			collectionProducts = append(collectionProducts, product)
+		}
+	}
+
+	return collectionProducts, nil
+}
+
+// ensureCustomerExists returns domain.ErrNotFound for unknown and deleted
+// customers.
+func (s *FavoriteCollectionService) ensureCustomerExists(c context.Context, customerID string) error {
+	customer, err := s.customerRepo.FindByID(c, customerID)
+	if err != nil {
+		return err
+	}
+
+	if customer == nil {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
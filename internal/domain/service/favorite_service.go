@@ -1,32 +1,338 @@
 package service
 
 import (
+	"app/internal/domain"
 	"app/internal/domain/model"
 	"app/internal/infra/db"
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 )
 
 type FavoriteService struct {
 	favoriteRepo   *db.FavoriteRepository
+	customerRepo   *db.CustomerRepository
 	productService ProductService
+	transactor     *db.Transactor
+	outboxRepo     *db.OutboxRepository
+	changeRepo     *db.FavoriteChangeRepository
 }
 
-func NewFavoriteService(favoriteRepo *db.FavoriteRepository, productService ProductService) *FavoriteService {
+func NewFavoriteService(
+	favoriteRepo *db.FavoriteRepository,
+	customerRepo *db.CustomerRepository,
+	productService ProductService,
+	transactor *db.Transactor,
+	outboxRepo *db.OutboxRepository,
+	changeRepo *db.FavoriteChangeRepository,
+) *FavoriteService {
 	return &FavoriteService{
 		favoriteRepo:   favoriteRepo,
+		customerRepo:   customerRepo,
 		productService: productService,
+		transactor:     transactor,
+		outboxRepo:     outboxRepo,
+		changeRepo:     changeRepo,
 	}
 }
-func (s *FavoriteService) AddFavorite(c context.Context, customerID string, productID int) error {
-	return s.favoriteRepo.AddFavorite(c, customerID, productID)
+
+// AddFavorite adds the product after the last favorite of the customer.
+// Adding a favorite again leaves its details unchanged. It returns
+// domain.ErrCustomerSuspended or domain.ErrCustomerClosed when the customer
+// cannot add favorites in its current state.
+func (s *FavoriteService) AddFavorite(c context.Context, customerID string, productID int, details model.FavoriteDetails) error {
+	tags, err := normalizeFavoriteTags(details.Tags)
+	if err != nil {
+		return err
+	}
+	details.Tags = tags
+
+	customer, err := s.customerRepo.FindByID(c, customerID)
+	if err != nil {
+		return err
+	}
+
+	if customer == nil {
+		return domain.ErrNotFound
+	}
+
+	switch customer.State {
+	case model.CustomerStateSuspended:
+		return domain.ErrCustomerSuspended
+	case model.CustomerStateClosed:
+		return domain.ErrCustomerClosed
+	}
+
+	return s.transactor.Do(c, func(c context.Context) error {
+		_, err := s.addFavorite(c, customerID, productID, details)
+		return err
+	})
 }
 
-func (s *FavoriteService) RemoveFavorite(c context.Context, productID int) error {
-	return s.favoriteRepo.RemoveFavorite(c, productID)
+// RemoveFavorite returns domain.ErrNotFound when the customer does not exist
+// and domain.ErrFavoriteNotFound when the product is not one of its favorites.
+func (s *FavoriteService) RemoveFavorite(c context.Context, customerID string, productID int) error {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return err
+	}
+
+	return s.transactor.Do(c, func(c context.Context) error {
+		removed, err := s.removeFavorite(c, customerID, productID)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return domain.ErrFavoriteNotFound
+		}
+		return nil
+	})
 }
 
-func (s *FavoriteService) GetCustomerFavoriteProducts(c context.Context, customerID string) ([]model.Product, error) {
-	productIDs, err := s.favoriteRepo.FindProductsByCustomerID(c, customerID)
+// errBatchFailed rolls back an all-or-nothing batch with a failed operation.
+var errBatchFailed = errors.New("batch operation failed")
+
+// Batch applies the operations in order in a single transaction, checking
+// the products added against a single read of the catalog. Additions of
+// products missing from the catalog or with invalid tags and removals of
+// products that are not favorites fail, and an all-or-nothing batch with a
+// failed operation is not applied. It returns domain.ErrCustomerSuspended or
+// domain.ErrCustomerClosed when the batch adds favorites the customer cannot
+// add in its current state.
+func (s *FavoriteService) Batch(c context.Context, customerID string, mode string, operations []model.FavoriteOperation) (*model.FavoriteBatchResult, error) {
+	customer, err := s.customerRepo.FindByID(c, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if customer == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	adds := slices.ContainsFunc(operations, func(op model.FavoriteOperation) bool {
+		return op.Op == model.FavoriteOperationAdd
+	})
+	if adds {
+		switch customer.State {
+		case model.CustomerStateSuspended:
+			return nil, domain.ErrCustomerSuspended
+		case model.CustomerStateClosed:
+			return nil, domain.ErrCustomerClosed
+		}
+	}
+
+	catalog := map[int]bool{}
+	if adds {
+		products, err := s.productService.GetAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range products {
+			catalog[product.ID] = true
+		}
+	}
+
+	result := model.FavoriteBatchResult{Mode: mode, Results: make([]model.FavoriteOperationResult, len(operations))}
+	operations = slices.Clone(operations)
+	failed := false
+	for i, op := range operations {
+		result.Results[i] = model.FavoriteOperationResult{Op: op.Op, ProductID: op.ProductID}
+		if op.Op != model.FavoriteOperationAdd {
+			continue
+		}
+
+		if !catalog[op.ProductID] {
+			result.Results[i].Status = model.FavoriteOperationFailed
+			result.Results[i].Error = "product not found in catalog"
+			failed = true
+			continue
+		}
+
+		tags, err := normalizeFavoriteTags(op.Details.Tags)
+		if err != nil {
+			result.Results[i].Status = model.FavoriteOperationFailed
+			result.Results[i].Error = err.Error()
+			failed = true
+			continue
+		}
+		operations[i].Details.Tags = tags
+	}
+
+	if failed && mode == model.FavoriteBatchAllOrNothing {
+		skipBatch(&result)
+		return &result, nil
+	}
+
+	err = s.transactor.Do(c, func(c context.Context) error {
+		for i, op := range operations {
+			if result.Results[i].Status == model.FavoriteOperationFailed {
+				continue
+			}
+
+			switch op.Op {
+			case model.FavoriteOperationAdd:
+				added, err := s.addFavorite(c, customerID, op.ProductID, op.Details)
+				if err != nil {
+					return err
+				}
+				result.Results[i].Status = model.FavoriteOperationUnchanged
+				if added {
+					result.Results[i].Status = model.FavoriteOperationAdded
+				}
+			case model.FavoriteOperationRemove:
+				removed, err := s.removeFavorite(c, customerID, op.ProductID)
+				if err != nil {
+					return err
+				}
+				if !removed {
+					result.Results[i].Status = model.FavoriteOperationFailed
+					result.Results[i].Error = domain.ErrFavoriteNotFound.Error()
+					failed = true
+					continue
+				}
+				result.Results[i].Status = model.FavoriteOperationRemoved
+			}
+		}
+
+		if failed && mode == model.FavoriteBatchAllOrNothing {
+			return errBatchFailed
+		}
+		return nil
+	})
+	if err != nil {
+		if err == errBatchFailed {
+			skipBatch(&result)
+			return &result, nil
+		}
+		return nil, err
+	}
+
+	result.Applied = true
+	return &result, nil
+}
+
+// skipBatch marks the operations that did not fail as skipped.
+func skipBatch(result *model.FavoriteBatchResult) {
+	for i := range result.Results {
+		if result.Results[i].Status != model.FavoriteOperationFailed {
+			result.Results[i].Status = model.FavoriteOperationSkipped
+		}
+	}
+}
+
+// addFavorite records the change and the event when the product was not a
+// favorite yet. Meant to run in a transaction.
+func (s *FavoriteService) addFavorite(c context.Context, customerID string, productID int, details model.FavoriteDetails) (bool, error) {
+	added, err := s.favoriteRepo.AddFavorite(c, customerID, productID, details)
+	if err != nil || !added {
+		return false, err
+	}
+
+	if err := s.changeRepo.Append(c, customerID, model.FavoriteChangeAdded, productID); err != nil {
+		return false, err
+	}
+
+	return true, recordEvent(c, s.outboxRepo, model.EventFavoriteAdded, customerID, model.FavoriteEventPayload{
+		CustomerID: customerID,
+		ProductID:  productID,
+	})
+}
+
+// removeFavorite records the change and the event when the product was a
+// favorite. Meant to run in a transaction.
+func (s *FavoriteService) removeFavorite(c context.Context, customerID string, productID int) (bool, error) {
+	removed, err := s.favoriteRepo.RemoveFavorite(c, customerID, productID)
+	if err != nil || !removed {
+		return false, err
+	}
+
+	if err := s.changeRepo.Append(c, customerID, model.FavoriteChangeRemoved, productID); err != nil {
+		return false, err
+	}
+
+	return true, recordEvent(c, s.outboxRepo, model.EventFavoriteRemoved, customerID, model.FavoriteEventPayload{
+		CustomerID: customerID,
+		ProductID:  productID,
+	})
+}
+
+// UpdateFavorite changes the details and the position of the favorite. It
+// returns domain.ErrFavoriteNotFound when the product is not a favorite of
+// the customer.
+func (s *FavoriteService) UpdateFavorite(c context.Context, customerID string, productID int, update model.FavoriteUpdate) (*model.CustomerFavorite, error) {
+	if update.Tags != nil {
+		tags, err := normalizeFavoriteTags(*update.Tags)
+		if err != nil {
+			return nil, err
+		}
+		update.Tags = &tags
+	}
+
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return nil, err
+	}
+
+	var favorite *model.CustomerFavorite
+	err := s.transactor.Do(c, func(c context.Context) error {
+		var err error
+		favorite, err = s.favoriteRepo.UpdateFavorite(c, customerID, productID, update)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if favorite == nil {
+		return nil, domain.ErrFavoriteNotFound
+	}
+
+	return favorite, nil
+}
+
+// Reorder positions the favorites in the order of productIDs, which must list
+// every favorite of the customer once.
+func (s *FavoriteService) Reorder(c context.Context, customerID string, productIDs []int) ([]model.FavoriteProduct, error) {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return nil, err
+	}
+
+	err := s.transactor.Do(c, func(c context.Context) error {
+		reordered, err := s.favoriteRepo.Reorder(c, customerID, productIDs)
+		if err != nil {
+			return err
+		}
+		if !reordered {
+			return domain.ErrInvalidFavoriteOrder
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCustomerFavoriteProducts(c, customerID, "")
+}
+
+// GetChanges returns the favorites of the customer added and removed after
+// sinceID, or every favorite without it. It returns domain.ErrSyncExpired
+// when the removals after sinceID are no longer kept.
+func (s *FavoriteService) GetChanges(c context.Context, customerID string, sinceID *int64) (*model.FavoriteChanges, error) {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return nil, err
+	}
+
+	return s.favoriteRepo.FindChanges(c, customerID, sinceID)
+}
+
+// GetCustomerFavoriteProducts returns the favorites of the customer by
+// position with their products as they are in the catalog, only the ones
+// tagged with tag when it is not empty.
+func (s *FavoriteService) GetCustomerFavoriteProducts(c context.Context, customerID string, tag string) ([]model.FavoriteProduct, error) {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return nil, err
+	}
+
+	customerFavorites, err := s.favoriteRepo.FindByCustomerID(c, customerID, NormalizeTag(tag))
 	if err != nil {
 		return nil, err
 	}
@@ -36,14 +342,53 @@ func (s *FavoriteService) GetCustomerFavoriteProducts(c context.Context, custome
 		return nil, err
 	}
 
-	var favorites []model.Product
-	for _, id := range productIDs {
-		for _, p := range products {
-			if p.ID == id {
-				favorites = append(favorites, p)
-			}
+	catalog := make(map[int]model.Product, len(products))
+	for _, product := range products {
+		catalog[product.ID] = product
+	}
+
+	favorites := []model.FavoriteProduct{}
+	for _, favorite := range customerFavorites {
+		if product, ok := catalog[favorite.ProductID]; ok {
+			favorites = append(favorites, model.FavoriteProduct{
+				Product:         product,
+				FavoriteDetails: favorite.FavoriteDetails,
+				Position:        favorite.Position,
+				AddedAt:         favorite.CreatedAt,
+			})
 		}
 	}
 
 	return favorites, nil
 }
+
+// ensureCustomerExists returns domain.ErrNotFound for unknown and deleted
+// customers.
+func (s *FavoriteService) ensureCustomerExists(c context.Context, customerID string) error {
+	customer, err := s.customerRepo.FindByID(c, customerID)
+	if err != nil {
+		return err
+	}
+
+	if customer == nil {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// normalizeFavoriteTags normalizes the tags as customer tags are and drops
+// the repeated ones.
+func normalizeFavoriteTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if !tagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("%w: tag %q is not valid", domain.ErrInvalidFavorite, tag)
+		}
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+)
+
+// FavoriteChangeNotifier wakes subscribers when favorites of a customer
+// change.
+type FavoriteChangeNotifier interface {
+	Subscribe(customerID string) (<-chan struct{}, func())
+}
+
+type FavoriteStreamService struct {
+	changeRepo   *db.FavoriteChangeRepository
+	customerRepo *db.CustomerRepository
+	notifier     FavoriteChangeNotifier
+	replayLimit  int
+}
+
+func NewFavoriteStreamService(
+	changeRepo *db.FavoriteChangeRepository,
+	customerRepo *db.CustomerRepository,
+	notifier FavoriteChangeNotifier,
+	replayLimit int,
+) *FavoriteStreamService {
+	return &FavoriteStreamService{
+		changeRepo:   changeRepo,
+		customerRepo: customerRepo,
+		notifier:     notifier,
+		replayLimit:  replayLimit,
+	}
+}
+
+// FavoriteStream follows the favorite changes of a customer from a cursor.
+type FavoriteStream struct {
+	service    *FavoriteStreamService
+	customerID string
+	cursor     int64
+	reset      bool
+	wake       <-chan struct{}
+	cancel     func()
+}
+
+// Open starts following the favorite changes of the customer. Without
+// lastEventID only changes made from now on are followed. When changes after
+// lastEventID were already removed from the history, the stream starts from
+// now and Reset reports it.
+func (s *FavoriteStreamService) Open(c context.Context, customerID string, lastEventID *int64) (*FavoriteStream, error) {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return nil, err
+	}
+
+	// Subscribing first makes sure nothing committed after the cursor is
+	// read goes unnoticed.
+	wake, cancel := s.notifier.Subscribe(customerID)
+	stream := &FavoriteStream{
+		service:    s,
+		customerID: customerID,
+		wake:       wake,
+		cancel:     cancel,
+	}
+
+	if lastEventID != nil {
+		oldestID, err := s.changeRepo.OldestID(c)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		if *lastEventID+1 >= oldestID {
+			stream.cursor = *lastEventID
+			return stream, nil
+		}
+		stream.reset = true
+	}
+
+	latestID, err := s.changeRepo.LatestID(c, customerID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	stream.cursor = latestID
+
+	return stream, nil
+}
+
+// ensureCustomerExists returns domain.ErrNotFound for unknown and deleted
+// customers.
+func (s *FavoriteStreamService) ensureCustomerExists(c context.Context, customerID string) error {
+	customer, err := s.customerRepo.FindByID(c, customerID)
+	if err != nil {
+		return err
+	}
+
+	if customer == nil {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// Wake receives when new changes may be available, and is closed when the
+// stream can no longer be followed.
+func (s *FavoriteStream) Wake() <-chan struct{} {
+	return s.wake
+}
+
+// Cursor returns the ID of the last change read.
+func (s *FavoriteStream) Cursor() int64 {
+	return s.cursor
+}
+
+// Reset reports whether changes after the requested Last-Event-ID were lost,
+// so the client has to reload the favorites.
+func (s *FavoriteStream) Reset() bool {
+	return s.reset
+}
+
+// Next returns the changes after the cursor and advances it. It returns
+// nothing when the stream is caught up.
+func (s *FavoriteStream) Next(c context.Context) ([]model.FavoriteChange, error) {
+	changes, err := s.service.changeRepo.FindSince(c, s.customerID, s.cursor, s.service.replayLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(changes) > 0 {
+		s.cursor = changes[len(changes)-1].ID
+	}
+	return changes, nil
+}
+
+func (s *FavoriteStream) Close() {
+	s.cancel()
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// EventSink delivers domain events to another system. Events are delivered
+// at least once, so sinks may receive an event again and consumers should
+// deduplicate by its ID.
+type EventSink interface {
+	Name() string
+	Publish(c context.Context, event model.DomainEvent) error
+}
+
+type OutboxDispatcher struct {
+	outboxRepo   *db.OutboxRepository
+	sinks        []EventSink
+	batchSize    int
+	retryDelay   time.Duration
+	maxDelay     time.Duration
+	retention    time.Duration
+	claimTimeout time.Duration
+}
+
+// NewOutboxDispatcher creates a dispatcher publishing up to batchSize events
+// at a time to every sink, claiming them for claimTimeout. Failed events are
+// retried after retryDelay, doubled on every attempt up to maxDelay.
+// Dispatched events are kept for retention.
+func NewOutboxDispatcher(
+	outboxRepo *db.OutboxRepository,
+	sinks []EventSink,
+	batchSize int,
+	retryDelay time.Duration,
+	maxDelay time.Duration,
+	retention time.Duration,
+	claimTimeout time.Duration,
+) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		outboxRepo:   outboxRepo,
+		sinks:        sinks,
+		batchSize:    batchSize,
+		retryDelay:   retryDelay,
+		maxDelay:     maxDelay,
+		retention:    retention,
+		claimTimeout: claimTimeout,
+	}
+}
+
+// DispatchNext publishes the next batch of due events. The batch is claimed
+// in a short transaction and published outside it, so slow sinks hold no
+// locks or connections. An event is marked as dispatched once every sink
+// accepted it; when a sink fails the event is published again to every sink
+// on its next attempt. It returns false when no event was due.
+func (d *OutboxDispatcher) DispatchNext(c context.Context) (bool, error) {
+	events, err := d.outboxRepo.ClaimDue(c, d.batchSize, d.claimTimeout)
+	if err != nil {
+		return false, err
+	}
+
+	for _, event := range events {
+		if err := d.publish(c, event.DomainEvent); err != nil {
+			log.Println("Error dispatching event", event.ID, err)
+			if err := d.outboxRepo.MarkFailed(c, event.ID, err.Error(), d.backoff(event.Attempts)); err != nil {
+				return true, err
+			}
+			continue
+		}
+
+		if err := d.outboxRepo.MarkDispatched(c, event.ID); err != nil {
+			return true, err
+		}
+	}
+
+	return len(events) > 0, nil
+}
+
+// PurgeDispatched removes events dispatched longer than the retention ago.
+func (d *OutboxDispatcher) PurgeDispatched(c context.Context) (int64, error) {
+	return d.outboxRepo.DeleteDispatched(c, d.retention)
+}
+
+func (d *OutboxDispatcher) publish(c context.Context, event model.DomainEvent) error {
+	for _, sink := range d.sinks {
+		if err := sink.Publish(c, event); err != nil {
+			return fmt.Errorf("%s sink: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (d *OutboxDispatcher) backoff(attempts int) time.Duration {
+	delay := d.retryDelay
+	for i := 0; i < attempts && delay < d.maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, d.maxDelay)
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+)
+
+// PriceAlertNotifier tells customers that a favorite got cheaper.
+type PriceAlertNotifier interface {
+	Name() string
+	Notify(c context.Context, alert model.PriceAlert) error
+}
+
+type PriceAlertService struct {
+	priceRepo      *db.ProductPriceRepository
+	alertRepo      *db.PriceAlertRepository
+	customerRepo   *db.CustomerRepository
+	productService ProductService
+	transactor     *db.Transactor
+	notifiers      []PriceAlertNotifier
+	batchSize      int
+	maxAttempts    int
+	claimTimeout   time.Duration
+}
+
+// NewPriceAlertService creates a service notifying up to batchSize alerts at
+// a time through every notifier, claiming them for claimTimeout. Alerts
+// failing maxAttempts times are no longer notified.
+func NewPriceAlertService(
+	priceRepo *db.ProductPriceRepository,
+	alertRepo *db.PriceAlertRepository,
+	customerRepo *db.CustomerRepository,
+	productService ProductService,
+	transactor *db.Transactor,
+	notifiers []PriceAlertNotifier,
+	batchSize int,
+	maxAttempts int,
+	claimTimeout time.Duration,
+) *PriceAlertService {
+	return &PriceAlertService{
+		priceRepo:      priceRepo,
+		alertRepo:      alertRepo,
+		customerRepo:   customerRepo,
+		productService: productService,
+		transactor:     transactor,
+		notifiers:      notifiers,
+		batchSize:      batchSize,
+		maxAttempts:    maxAttempts,
+		claimTimeout:   claimTimeout,
+	}
+}
+
+// GetSettings returns disabled settings when the customer never set up
+// price alerts.
+func (s *PriceAlertService) GetSettings(c context.Context, customerID string) (*model.PriceAlertSettings, error) {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.alertRepo.FindSettings(c, customerID)
+	if err != nil || settings != nil {
+		return settings, err
+	}
+
+	return &model.PriceAlertSettings{
+		CustomerID:    customerID,
+		ThresholdType: model.PriceAlertPercent,
+		Threshold:     10,
+	}, nil
+}
+
+// UpdateSettings returns an error wrapping domain.ErrInvalidPriceAlert when
+// the threshold is not positive or a percentage is not under 100.
+func (s *PriceAlertService) UpdateSettings(c context.Context, settings model.PriceAlertSettings) (*model.PriceAlertSettings, error) {
+	switch {
+	case settings.ThresholdType != model.PriceAlertPercent && settings.ThresholdType != model.PriceAlertAbsolute:
+		return nil, fmt.Errorf("%w: threshold_type must be percent or absolute", domain.ErrInvalidPriceAlert)
+	case settings.Threshold <= 0:
+		return nil, fmt.Errorf("%w: threshold must be positive", domain.ErrInvalidPriceAlert)
+	case settings.ThresholdType == model.PriceAlertPercent && settings.Threshold >= 100:
+		return nil, fmt.Errorf("%w: percent threshold must be under 100", domain.ErrInvalidPriceAlert)
+	}
+
+	if err := s.ensureCustomerExists(c, settings.CustomerID); err != nil {
+		return nil, err
+	}
+
+	return s.alertRepo.SaveSettings(c, settings)
+}
+
+func (s *PriceAlertService) GetAlerts(c context.Context, customerID string) ([]model.PriceAlert, error) {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return nil, err
+	}
+
+	return s.alertRepo.FindByCustomerID(c, customerID)
+}
+
+// GetPriceHistory returns the price changes of the product seen in the
+// catalog, oldest first.
+func (s *PriceAlertService) GetPriceHistory(c context.Context, productID int) ([]model.ProductPrice, error) {
+	return s.priceRepo.FindByProductID(c, productID)
+}
+
+// CheckPrices records the prices of the catalog that changed and alerts the
+// customers whose favorites dropped past their threshold. It returns the
+// number of alerts created.
+func (s *PriceAlertService) CheckPrices(c context.Context) (int64, error) {
+	products, err := s.productService.GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	var alerts int64
+	err = s.transactor.Do(c, func(c context.Context) error {
+		changed, err := s.priceRepo.Record(c, products)
+		if err != nil || len(changed) == 0 {
+			return err
+		}
+
+		alerts, err = s.alertRepo.CreateAlerts(c, changed)
+		return err
+	})
+
+	return alerts, err
+}
+
+// NotifyPending sends the pending alerts in batches. Each batch is claimed
+// in a short transaction and notified outside it, so slow notifiers hold no
+// locks or connections. An alert is marked as notified once every notifier
+// accepted it; when a notifier fails the alert is sent on the next call only
+// to the notifiers that did not accept it yet. It returns the number of
+// alerts notified.
+func (s *PriceAlertService) NotifyPending(c context.Context) (int, error) {
+	notified := 0
+	afterID := int64(0)
+	for {
+		alerts, err := s.alertRepo.ClaimPending(c, afterID, s.batchSize, s.maxAttempts, s.claimTimeout)
+		if err != nil {
+			return notified, err
+		}
+
+		for _, alert := range alerts {
+			if err := s.notify(c, alert); err != nil {
+				log.Println("Error notifying price alert", alert.ID, err)
+				if err := s.alertRepo.MarkFailed(c, alert.ID, err.Error()); err != nil {
+					return notified, err
+				}
+				continue
+			}
+
+			if err := s.alertRepo.MarkNotified(c, alert.ID); err != nil {
+				return notified, err
+			}
+			notified++
+		}
+
+		if len(alerts) < s.batchSize {
+			return notified, nil
+		}
+		afterID = alerts[len(alerts)-1].ID
+	}
+}
+
+// notify calls the notifiers that did not accept the alert yet, recording
+// each one that does.
+func (s *PriceAlertService) notify(c context.Context, alert model.PriceAlert) error {
+	for _, notifier := range s.notifiers {
+		if slices.Contains(alert.NotifiedBy, notifier.Name()) {
+			continue
+		}
+
+		if err := notifier.Notify(c, alert); err != nil {
+			return fmt.Errorf("%s notifier: %w", notifier.Name(), err)
+		}
+
+		if err := s.alertRepo.MarkNotifiedBy(c, alert.ID, notifier.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureCustomerExists returns domain.ErrNotFound for unknown and deleted
+// customers.
+func (s *PriceAlertService) ensureCustomerExists(c context.Context, customerID string) error {
+	customer, err := s.customerRepo.FindByID(c, customerID)
+	if err != nil {
+		return err
+	}
+
+	if customer == nil {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ShareLinkService struct {
+	shareLinkRepo  *db.ShareLinkRepository
+	customerRepo   *db.CustomerRepository
+	collectionRepo *db.FavoriteCollectionRepository
+	favoriteRepo   *db.FavoriteRepository
+	productService ProductService
+}
+
+func NewShareLinkService(
+	shareLinkRepo *db.ShareLinkRepository,
+	customerRepo *db.CustomerRepository,
+	collectionRepo *db.FavoriteCollectionRepository,
+	favoriteRepo *db.FavoriteRepository,
+	productService ProductService,
+) *ShareLinkService {
+	return &ShareLinkService{
+		shareLinkRepo:  shareLinkRepo,
+		customerRepo:   customerRepo,
+		collectionRepo: collectionRepo,
+		favoriteRepo:   favoriteRepo,
+		productService: productService,
+	}
+}
+
+// Create generates a link to the favorites of the customer, or to the
+// collection when link.CollectionID is set. Only public collections can be
+// shared. The returned link is the only one carrying the token.
+func (s *ShareLinkService) Create(c context.Context, link model.ShareLink) (*model.ShareLink, error) {
+	if link.ExpiresAt != nil && !link.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", domain.ErrInvalidShareLink)
+	}
+
+	if err := s.ensureCustomerExists(c, link.CustomerID); err != nil {
+		return nil, err
+	}
+
+	if link.CollectionID != "" {
+		collection, err := s.collectionRepo.FindByID(c, link.CustomerID, link.CollectionID)
+		if err != nil {
+			return nil, err
+		}
+		if collection == nil {
+			return nil, domain.ErrNotFound
+		}
+		if !collection.Public {
+			return nil, domain.ErrCollectionPrivate
+		}
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+
+	link.ID = uuid.New().String()
+	created, err := s.shareLinkRepo.Create(c, link, hashShareToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	created.Token = token
+	return created, nil
+}
+
+func (s *ShareLinkService) GetByCustomerID(c context.Context, customerID string) ([]model.ShareLink, error) {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return nil, err
+	}
+
+	return s.shareLinkRepo.FindByCustomerID(c, customerID)
+}
+
+// Revoke disables the link for good. Revoking it again does nothing.
+func (s *ShareLinkService) Revoke(c context.Context, customerID string, id string) error {
+	if err := s.ensureCustomerExists(c, customerID); err != nil {
+		return err
+	}
+
+	revoked, err := s.shareLinkRepo.Revoke(c, customerID, id)
+	if err != nil {
+		return err
+	}
+
+	if !revoked {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// View returns what the link with the token shows and counts the view. It
+// returns domain.ErrNotFound, without telling why, when the link is unknown,
+// revoked or expired, when the customer was deleted, suspended or closed and
+// when the shared collection was deleted or made private.
+func (s *ShareLinkService) View(c context.Context, token string) (*model.SharedFavorites, *model.ShareLink, error) {
+	link, err := s.shareLinkRepo.FindActiveByTokenHash(c, hashShareToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	if link == nil {
+		return nil, nil, domain.ErrNotFound
+	}
+
+	customer, err := s.customerRepo.FindByID(c, link.CustomerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if customer == nil || customer.State == model.CustomerStateSuspended || customer.State == model.CustomerStateClosed {
+		return nil, nil, domain.ErrNotFound
+	}
+
+	shared := model.SharedFavorites{}
+	var productIDs []int
+	if link.CollectionID != "" {
+		collection, err := s.collectionRepo.FindByID(c, link.CustomerID, link.CollectionID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if collection == nil || !collection.Public {
+			return nil, nil, domain.ErrNotFound
+		}
+
+		shared.Name = collection.Name
+		productIDs, err = s.collectionRepo.FindProductIDs(c, collection.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		productIDs, err = s.favoriteRepo.FindProductsByCustomerID(c, link.CustomerID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	products, err := s.productService.GetAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	catalog := make(map[int]model.Product, len(products))
+	for _, product := range products {
+		catalog[product.ID] = product
+	}
+
+	shared.Products = []model.Product{}
+	for _, productID := range productIDs {
+		if product, ok := catalog[productID]; ok {
+			shared.Products = append(shared.Products, product)
+		}
+	}
+
+	if err := s.shareLinkRepo.RecordView(c, link.ID); err != nil {
+		return nil, nil, err
+	}
+
+	return &shared, link, nil
+}
+
+// ensureCustomerExists returns domain.ErrNotFound for unknown and deleted
+// customers.
+func (s *ShareLinkService) ensureCustomerExists(c context.Context, customerID string) error {
+	customer, err := s.customerRepo.FindByID(c, customerID)
+	if err != nil {
+		return err
+	}
+
+	if customer == nil {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func newShareToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+func hashShareToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
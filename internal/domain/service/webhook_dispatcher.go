@@ -0,0 +1,146 @@
+package service
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// WebhookSender posts a webhook body with the given headers. It returns the
+// response status code, or an error when no response was received.
+type WebhookSender interface {
+	Send(c context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
+
+// WebhookDispatcher fans outbox events out to the matching subscriptions and
+// sends the resulting deliveries.
+type WebhookDispatcher struct {
+	webhookRepo  *db.WebhookRepository
+	transactor   *db.Transactor
+	sender       WebhookSender
+	batchSize    int
+	maxAttempts  int
+	retryDelay   time.Duration
+	maxDelay     time.Duration
+	retention    time.Duration
+	claimTimeout time.Duration
+}
+
+// NewWebhookDispatcher creates a dispatcher sending up to batchSize
+// deliveries at a time, claiming them for claimTimeout. Failed deliveries
+// are retried after retryDelay, doubled on every attempt up to maxDelay, and
+// dead-lettered after maxAttempts. Finished deliveries are kept for
+// retention.
+func NewWebhookDispatcher(
+	webhookRepo *db.WebhookRepository,
+	transactor *db.Transactor,
+	sender WebhookSender,
+	batchSize int,
+	maxAttempts int,
+	retryDelay time.Duration,
+	maxDelay time.Duration,
+	retention time.Duration,
+	claimTimeout time.Duration,
+) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhookRepo:  webhookRepo,
+		transactor:   transactor,
+		sender:       sender,
+		batchSize:    batchSize,
+		maxAttempts:  maxAttempts,
+		retryDelay:   retryDelay,
+		maxDelay:     maxDelay,
+		retention:    retention,
+		claimTimeout: claimTimeout,
+	}
+}
+
+func (d *WebhookDispatcher) Name() string {
+	return "webhook_subscriptions"
+}
+
+// Publish enqueues a delivery of the event for every subscription to its
+// type. Enqueueing is idempotent, so an event published again by the outbox
+// dispatcher is delivered once per subscription.
+func (d *WebhookDispatcher) Publish(c context.Context, event model.DomainEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return d.webhookRepo.Enqueue(c, event, body)
+}
+
+// DeliverNext sends the next batch of due deliveries. The batch is claimed
+// in a short transaction and sent outside it, so slow receivers hold no
+// locks or connections, and each attempt is recorded in its own transaction.
+// It returns false when no delivery was due.
+func (d *WebhookDispatcher) DeliverNext(c context.Context) (bool, error) {
+	deliveries, err := d.webhookRepo.ClaimDue(c, d.batchSize, d.claimTimeout)
+	if err != nil {
+		return false, err
+	}
+
+	for _, delivery := range deliveries {
+		attempt, status := d.send(c, delivery)
+		err := d.transactor.Do(c, func(c context.Context) error {
+			return d.webhookRepo.RecordAttempt(c, delivery.ID, attempt, status, d.backoff(delivery.Attempts))
+		})
+		if err != nil {
+			return true, err
+		}
+	}
+
+	return len(deliveries) > 0, nil
+}
+
+// PurgeFinished removes deliveries finished longer than the retention ago.
+func (d *WebhookDispatcher) PurgeFinished(c context.Context) (int64, error) {
+	return d.webhookRepo.DeleteFinished(c, d.retention)
+}
+
+func (d *WebhookDispatcher) send(c context.Context, delivery model.DueWebhookDelivery) (model.WebhookDeliveryAttempt, string) {
+	attempt := model.WebhookDeliveryAttempt{Attempt: delivery.Attempts + 1}
+
+	timestamp := time.Now().Unix()
+	headers := map[string]string{
+		"Content-Type":        "application/json",
+		"X-Webhook-ID":        delivery.ID,
+		"X-Webhook-Timestamp": strconv.FormatInt(timestamp, 10),
+		"X-Webhook-Signature": domain.WebhookSignature(delivery.Secret, timestamp, delivery.Payload),
+		"X-Event-ID":          delivery.EventID,
+		"X-Event-Type":        delivery.Type,
+	}
+
+	start := time.Now()
+	statusCode, err := d.sender.Send(c, delivery.URL, headers, delivery.Payload)
+	attempt.DurationMs = int(time.Since(start).Milliseconds())
+
+	if err == nil {
+		attempt.StatusCode = &statusCode
+		if statusCode >= 200 && statusCode <= 299 {
+			return attempt, model.WebhookDeliverySucceeded
+		}
+		err = fmt.Errorf("receiver returned status %d", statusCode)
+	}
+	attempt.Error = err.Error()
+	log.Println("Error delivering webhook", delivery.ID, err)
+
+	if attempt.Attempt >= d.maxAttempts {
+		return attempt, model.WebhookDeliveryDead
+	}
+	return attempt, model.WebhookDeliveryPending
+}
+
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	delay := d.retryDelay
+	for i := 0; i < attempts && delay < d.maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, d.maxDelay)
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"app/internal/domain/model"
+	infraservice "app/internal/infra/service"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestWebhookDispatcher(maxAttempts int) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		sender:      infraservice.NewWebhookSender(time.Second),
+		maxAttempts: maxAttempts,
+		retryDelay:  30 * time.Second,
+		maxDelay:    5 * time.Minute,
+	}
+}
+
+func TestWebhookDispatcherSignsDeliveries(t *testing.T) {
+	var (
+		headers http.Header
+		body    []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	delivery := model.DueWebhookDelivery{
+		ID:      "delivery-1",
+		URL:     server.URL,
+		Secret:  "s3cret",
+		EventID: "event-1",
+		Type:    model.EventFavoriteAdded,
+		Payload: []byte(`{"id":"event-1","type":"favorite.added"}`),
+	}
+
+	attempt, status := newTestWebhookDispatcher(3).send(t.Context(), delivery)
+	if status != model.WebhookDeliverySucceeded {
+		t.Fatalf("status = %q, want %q (error %q)", status, model.WebhookDeliverySucceeded, attempt.Error)
+	}
+	if attempt.StatusCode == nil || *attempt.StatusCode != http.StatusNoContent {
+		t.Errorf("attempt status code = %v, want %d", attempt.StatusCode, http.StatusNoContent)
+	}
+	if attempt.Attempt != 1 {
+		t.Errorf("attempt = %d, want 1", attempt.Attempt)
+	}
+
+	if string(body) != string(delivery.Payload) {
+		t.Errorf("body = %s, want %s", body, delivery.Payload)
+	}
+	if _, err := strconv.ParseInt(headers.Get("X-Webhook-Timestamp"), 10, 64); err != nil {
+		t.Fatalf("X-Webhook-Timestamp = %q is not a Unix time", headers.Get("X-Webhook-Timestamp"))
+	}
+
+	mac := hmac.New(sha256.New, []byte(delivery.Secret))
+	mac.Write([]byte(headers.Get("X-Webhook-Timestamp") + "." + string(body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := headers.Get("X-Webhook-Signature"); !hmac.Equal([]byte(got), []byte(want)) {
+		t.Errorf("X-Webhook-Signature = %q, want %q", got, want)
+	}
+
+	for name, want := range map[string]string{
+		"Content-Type": "application/json",
+		"X-Webhook-ID": delivery.ID,
+		"X-Event-ID":   delivery.EventID,
+		"X-Event-Type": delivery.Type,
+	} {
+		if got := headers.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestWebhookDispatcherDeadLetters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	const maxAttempts = 3
+	tests := []struct {
+		name       string
+		url        string
+		attempts   int
+		wantStatus string
+	}{
+		{name: "first failure", url: server.URL, attempts: 0, wantStatus: model.WebhookDeliveryPending},
+		{name: "failure before the last attempt", url: server.URL, attempts: maxAttempts - 2, wantStatus: model.WebhookDeliveryPending},
+		{name: "failure of the last attempt", url: server.URL, attempts: maxAttempts - 1, wantStatus: model.WebhookDeliveryDead},
+		{name: "no response before the last attempt", url: unreachable.URL, attempts: maxAttempts - 2, wantStatus: model.WebhookDeliveryPending},
+		{name: "no response on the last attempt", url: unreachable.URL, attempts: maxAttempts - 1, wantStatus: model.WebhookDeliveryDead},
+	}
+
+	d := newTestWebhookDispatcher(maxAttempts)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delivery := model.DueWebhookDelivery{ID: "delivery-1", URL: tt.url, Payload: []byte(`{}`), Attempts: tt.attempts}
+
+			attempt, status := d.send(t.Context(), delivery)
+			if status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", status, tt.wantStatus)
+			}
+			if attempt.Attempt != tt.attempts+1 {
+				t.Errorf("attempt = %d, want %d", attempt.Attempt, tt.attempts+1)
+			}
+			if attempt.Error == "" {
+				t.Error("attempt error is empty")
+			}
+		})
+	}
+}
+
+func TestWebhookDispatcherDoesNotFollowRedirects(t *testing.T) {
+	var redirected atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	delivery := model.DueWebhookDelivery{ID: "delivery-1", URL: server.URL, Payload: []byte(`{}`)}
+
+	attempt, status := newTestWebhookDispatcher(3).send(t.Context(), delivery)
+	if status != model.WebhookDeliveryPending {
+		t.Errorf("status = %q, want %q", status, model.WebhookDeliveryPending)
+	}
+	if attempt.StatusCode == nil || *attempt.StatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("attempt status code = %v, want %d", attempt.StatusCode, http.StatusTemporaryRedirect)
+	}
+	if n := redirected.Load(); n != 0 {
+		t.Errorf("redirect target received %d requests, want 0", n)
+	}
+}
+
+func TestWebhookDispatcherBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: 30 * time.Second},
+		{attempts: 1, want: time.Minute},
+		{attempts: 2, want: 2 * time.Minute},
+		{attempts: 3, want: 4 * time.Minute},
+		{attempts: 4, want: 5 * time.Minute},
+		{attempts: 10, want: 5 * time.Minute},
+		{attempts: 1000, want: 5 * time.Minute},
+	}
+
+	d := newTestWebhookDispatcher(3)
+	for _, tt := range tests {
+		if got := d.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
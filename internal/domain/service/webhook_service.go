@@ -0,0 +1,171 @@
+package service
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+type WebhookService struct {
+	webhookRepo *db.WebhookRepository
+}
+
+func NewWebhookService(webhookRepo *db.WebhookRepository) *WebhookService {
+	return &WebhookService{webhookRepo: webhookRepo}
+}
+
+// Create stores the subscription, generating its secret when empty. The
+// returned subscription is the only one carrying the secret.
+func (s *WebhookService) Create(c context.Context, subscription model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	if err := validateWebhookSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	if subscription.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		subscription.Secret = secret
+	}
+
+	subscription.ID = uuid.New().String()
+	return s.webhookRepo.Create(c, subscription)
+}
+
+func (s *WebhookService) GetAll(c context.Context) ([]model.WebhookSubscription, error) {
+	return s.webhookRepo.FindAll(c)
+}
+
+func (s *WebhookService) GetByID(c context.Context, id string) (*model.WebhookSubscription, error) {
+	subscription, err := s.webhookRepo.FindByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	return subscription, nil
+}
+
+// Update replaces the URL and event types of the subscription, and its
+// secret when not empty. Pending deliveries are sent with the new settings.
+func (s *WebhookService) Update(c context.Context, subscription model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	if err := validateWebhookSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.webhookRepo.Update(c, subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	if updated == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	return updated, nil
+}
+
+func (s *WebhookService) Delete(c context.Context, id string) error {
+	deleted, err := s.webhookRepo.Delete(c, id)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// Deliveries returns one page of the deliveries of the subscription, newest
+// first, only those with status when it is not empty. Pages start at 1.
+func (s *WebhookService) Deliveries(
+	c context.Context,
+	subscriptionID string,
+	status string,
+	page int,
+	pageSize int,
+) (*model.WebhookDeliveryPage, error) {
+	if _, err := s.GetByID(c, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	deliveries, total, err := s.webhookRepo.FindDeliveries(c, subscriptionID, status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.WebhookDeliveryPage{
+		Items:    deliveries,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+func (s *WebhookService) GetDelivery(c context.Context, subscriptionID string, deliveryID string) (*model.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.FindDelivery(c, subscriptionID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if delivery == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	return delivery, nil
+}
+
+// Redeliver schedules the delivery to be sent again right away, whatever its
+// status. A dead-lettered delivery gets a single attempt.
+func (s *WebhookService) Redeliver(c context.Context, subscriptionID string, deliveryID string) (*model.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.Redeliver(c, subscriptionID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if delivery == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	return delivery, nil
+}
+
+func validateWebhookSubscription(subscription model.WebhookSubscription) error {
+	target, err := url.Parse(subscription.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", domain.ErrInvalidWebhook)
+	}
+
+	if len(subscription.EventTypes) == 0 {
+		return fmt.Errorf("%w: event_types must not be empty", domain.ErrInvalidWebhook)
+	}
+	for _, eventType := range subscription.EventTypes {
+		if !slices.Contains(model.WebhookEventTypes, eventType) {
+			return fmt.Errorf("%w: unknown event type %q", domain.ErrInvalidWebhook, eventType)
+		}
+	}
+
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
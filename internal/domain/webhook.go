@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// WebhookSignature signs a webhook delivery. Receivers recompute it over the
+// timestamp header, a dot and the raw body, and compare it with the signature
+// header in constant time. Including the timestamp lets them reject replayed
+// deliveries.
+func WebhookSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -1,31 +1,25 @@
 package db
 
 import (
+	"app/internal/config"
 	"database/sql"
-	"fmt"
 	"log"
-	"os"
 
 	_ "github.com/lib/pq"
 )
 
-func Connect() *sql.DB {
-	dsn := fmt.Sprintf(
-		"port=%s user=%s password=%s dbname=%s host=%s sslmode=disable",
-		os.Getenv("POSTGRES_PORT"),
-		os.Getenv("POSTGRES_USER"),
-		os.Getenv("POSTGRES_PASSWORD"),
-		os.Getenv("POSTGRES_DB"),
-		os.Getenv("POSTGRES_HOST"),
-	)
+func Connect(cfg config.DatabaseConfig) *sql.DB {
+	log.Printf("Connecting to database %s at %s:%d", cfg.Name, cfg.Host, cfg.Port)
 
-	fmt.Println("Connecting to:", dsn)
-
-	db, err := sql.Open("postgres", dsn)
+	db, err := sql.Open("postgres", cfg.DSN())
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
 	err = db.Ping()
 	if err != nil {
 		log.Fatal("Failed to ping database:", err)
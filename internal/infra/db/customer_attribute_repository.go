@@ -0,0 +1,134 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+const customerAttributeColumns = "name, type, required, enum, COALESCE(description, ''), created_at"
+
+type CustomerAttributeRepository struct {
+	db *sql.DB
+}
+
+func NewCustomerAttributeRepository(db *sql.DB) *CustomerAttributeRepository {
+	return &CustomerAttributeRepository{
+		db: db,
+	}
+}
+
+func scanCustomerAttribute(row rowScanner) (*model.CustomerAttributeDefinition, error) {
+	var (
+		definition model.CustomerAttributeDefinition
+		enum       []byte
+	)
+	if err := row.Scan(
+		&definition.Name,
+		&definition.Type,
+		&definition.Required,
+		&enum,
+		&definition.Description,
+		&definition.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if enum != nil {
+		if err := json.Unmarshal(enum, &definition.Enum); err != nil {
+			return nil, err
+		}
+	}
+	return &definition, nil
+}
+
+// Create stores the definition. It returns nil when an attribute with the
+// same name already exists.
+func (r *CustomerAttributeRepository) Create(c context.Context, definition model.CustomerAttributeDefinition) (*model.CustomerAttributeDefinition, error) {
+	var enum []byte
+	if definition.Enum != nil {
+		var err error
+		if enum, err = json.Marshal(definition.Enum); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `
+		INSERT INTO customer_attribute_definitions (name, type, required, enum, description)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		ON CONFLICT (name) DO NOTHING
+		RETURNING ` + customerAttributeColumns
+
+	row := r.db.QueryRowContext(c, query,
+		definition.Name,
+		definition.Type,
+		definition.Required,
+		enum,
+		definition.Description,
+	)
+
+	createdDefinition, err := scanCustomerAttribute(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return createdDefinition, nil
+}
+
+func (r *CustomerAttributeRepository) FindAll(c context.Context) ([]model.CustomerAttributeDefinition, error) {
+	query := `
+		SELECT ` + customerAttributeColumns + `
+		FROM customer_attribute_definitions
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(c, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var definitions []model.CustomerAttributeDefinition
+	for rows.Next() {
+		definition, err := scanCustomerAttribute(rows)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, *definition)
+	}
+
+	return definitions, rows.Err()
+}
+
+// Delete removes the definition and its values from every customer. It
+// returns false when there is no such attribute.
+func (r *CustomerAttributeRepository) Delete(c context.Context, name string) (bool, error) {
+	tx, err := r.db.BeginTx(c, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(c, "DELETE FROM customer_attribute_definitions WHERE name = $1", name)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil || deleted == 0 {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(c, `
+		UPDATE customers
+		SET attributes = attributes - $1::text, version = version + 1, updated_at = now()
+		WHERE attributes ? $1::text
+	`, name); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// customerExportFetchSize is the number of rows read from the export cursor
+// at a time.
+const customerExportFetchSize = 500
+
+type CustomerExportRepository struct {
+	db *sql.DB
+}
+
+func NewCustomerExportRepository(db *sql.DB) *CustomerExportRepository {
+	return &CustomerExportRepository{
+		db: db,
+	}
+}
+
+// Stream calls fn with every active customer matching filter, oldest first,
+// together with its favorite product IDs when filter asks for favorites. Rows
+// are read through a cursor in a read-only transaction, so the export is a
+// consistent snapshot and never held in memory as a whole.
+func (r *CustomerExportRepository) Stream(c context.Context, filter model.CustomerExportFilter, fn func(model.CustomerExport) error) error {
+	tx, err := r.db.BeginTx(c, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	favorites := "'{}'::int[]"
+	if filter.Favorites != model.ExportFavoritesNone {
+		favorites = `ARRAY(
+			SELECT product_id
+			FROM customers_favorite_products
+			WHERE customer_id = customers.id
+			ORDER BY created_at, product_id
+		)`
+	}
+
+	query := `
+		DECLARE customer_export NO SCROLL CURSOR FOR
+		SELECT ` + customerColumns + `, ` + favorites + `
+		FROM customers
+		WHERE deleted_at IS NULL
+			AND ($1::timestamp IS NULL OR created_at >= $1::timestamp)
+			AND ($2::timestamp IS NULL OR created_at < $2::timestamp)
+		ORDER BY created_at, id
+	`
+	if _, err := tx.ExecContext(c, query, filter.CreatedFrom, filter.CreatedBefore); err != nil {
+		return err
+	}
+
+	for {
+		fetched, err := fetchCustomerExports(c, tx, fn)
+		if err != nil {
+			return err
+		}
+		if fetched < customerExportFetchSize {
+			return nil
+		}
+	}
+}
+
+func fetchCustomerExports(c context.Context, tx *sql.Tx, fn func(model.CustomerExport) error) (int, error) {
+	rows, err := tx.QueryContext(c, fmt.Sprintf("FETCH FORWARD %d FROM customer_export", customerExportFetchSize))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	fetched := 0
+	for rows.Next() {
+		var productIDs pq.Int64Array
+		customer, err := scanCustomer(rows, &productIDs)
+		if err != nil {
+			return fetched, err
+		}
+
+		export := model.CustomerExport{Customer: *customer}
+		export.FavoriteProductIDs = make([]int, len(productIDs))
+		for i, productID := range productIDs {
+			export.FavoriteProductIDs[i] = int(productID)
+		}
+
+		if err := fn(export); err != nil {
+			return fetched, err
+		}
+		fetched++
+	}
+
+	return fetched, rows.Err()
+}
@@ -0,0 +1,297 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const customerImportColumns = `id, status, format, dry_run, created_by, total_rows, processed_rows,
+	created_rows, failed_rows, COALESCE(error, ''), created_at, started_at, finished_at`
+
+type CustomerImportRepository struct {
+	db *sql.DB
+}
+
+func NewCustomerImportRepository(db *sql.DB) *CustomerImportRepository {
+	return &CustomerImportRepository{
+		db: db,
+	}
+}
+
+func scanCustomerImport(row rowScanner, extra ...any) (*model.CustomerImport, error) {
+	var customerImport model.CustomerImport
+	dest := []any{
+		&customerImport.ID,
+		&customerImport.Status,
+		&customerImport.Format,
+		&customerImport.DryRun,
+		&customerImport.CreatedBy,
+		&customerImport.TotalRows,
+		&customerImport.ProcessedRows,
+		&customerImport.CreatedRows,
+		&customerImport.FailedRows,
+		&customerImport.Error,
+		&customerImport.CreatedAt,
+		&customerImport.StartedAt,
+		&customerImport.FinishedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &customerImport, nil
+}
+
+func (r *CustomerImportRepository) Create(c context.Context, customerImport model.CustomerImport) (*model.CustomerImport, error) {
+	query := `
+		INSERT INTO customer_imports (id, format, dry_run, payload, created_by, total_rows)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + customerImportColumns
+
+	row := r.db.QueryRowContext(c, query,
+		customerImport.ID,
+		customerImport.Format,
+		customerImport.DryRun,
+		customerImport.Payload,
+		customerImport.CreatedBy,
+		customerImport.TotalRows,
+	)
+
+	return scanCustomerImport(row)
+}
+
+func (r *CustomerImportRepository) FindByID(c context.Context, id string) (*model.CustomerImport, error) {
+	query := `
+		SELECT ` + customerImportColumns + `
+		FROM customer_imports
+		WHERE id = $1
+	`
+
+	customerImport, err := scanCustomerImport(r.db.QueryRowContext(c, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return customerImport, nil
+}
+
+func (r *CustomerImportRepository) FindErrors(c context.Context, importID string, limit int, offset int) ([]model.CustomerImportRowError, int, error) {
+	query := `
+		SELECT row_number, COALESCE(field, ''), message, count(*) OVER ()
+		FROM customer_import_errors
+		WHERE import_id = $1
+		ORDER BY row_number, id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(c, query, importID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		rowErrors []model.CustomerImportRowError
+		total     int
+	)
+	for rows.Next() {
+		var rowError model.CustomerImportRowError
+		if err := rows.Scan(&rowError.Row, &rowError.Field, &rowError.Message, &total); err != nil {
+			return nil, 0, err
+		}
+		rowErrors = append(rowErrors, rowError)
+	}
+
+	return rowErrors, total, rows.Err()
+}
+
+// ClaimNext marks the oldest pending import as running and returns it with
+// its payload. Running imports whose heartbeat is older than staleAfter are
+// claimed again, since the instance processing them stopped.
+func (r *CustomerImportRepository) ClaimNext(c context.Context, staleAfter time.Duration) (*model.CustomerImport, error) {
+	query := `
+		UPDATE customer_imports
+		SET status = 'running', started_at = COALESCE(started_at, now()), heartbeat_at = now()
+		WHERE id = (
+			SELECT id
+			FROM customer_imports
+			WHERE status = 'pending'
+				OR (status = 'running' AND heartbeat_at < now() - make_interval(secs => $1))
+			ORDER BY created_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING ` + customerImportColumns + `, payload`
+
+	var payload []byte
+	customerImport, err := scanCustomerImport(r.db.QueryRowContext(c, query, staleAfter.Seconds()), &payload)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	customerImport.Payload = payload
+	return customerImport, nil
+}
+
+// ApplyBatch creates the valid rows of a batch and records the errors in one
+// transaction, moving the import progress to processedRows. Rows whose email
+// is already taken are recorded as errors. In dry runs nothing is created.
+func (r *CustomerImportRepository) ApplyBatch(
+	c context.Context,
+	importID string,
+	rows []model.CustomerImportRow,
+	rowErrors []model.CustomerImportRowError,
+	processedRows int,
+	dryRun bool,
+) error {
+	tx, err := r.db.BeginTx(c, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var duplicates []model.CustomerImportRowError
+	if dryRun {
+		duplicates, err = findTakenEmails(c, tx, rows)
+	} else {
+		duplicates, err = insertImportedCustomers(c, tx, rows)
+	}
+	if err != nil {
+		return err
+	}
+	rowErrors = append(rowErrors, duplicates...)
+
+	errorStmt, err := tx.PrepareContext(c, `
+		INSERT INTO customer_import_errors (import_id, row_number, field, message)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+	`)
+	if err != nil {
+		return err
+	}
+	defer errorStmt.Close()
+
+	failedRows := make(map[int]bool)
+	for _, rowError := range rowErrors {
+		if _, err := errorStmt.ExecContext(c, importID, rowError.Row, rowError.Field, rowError.Message); err != nil {
+			return err
+		}
+		failedRows[rowError.Row] = true
+	}
+
+	createdRows := 0
+	if !dryRun {
+		createdRows = len(rows) - len(duplicates)
+	}
+
+	if _, err := tx.ExecContext(c, `
+		UPDATE customer_imports
+		SET processed_rows = $2, created_rows = created_rows + $3, failed_rows = failed_rows + $4, heartbeat_at = now()
+		WHERE id = $1
+	`, importID, processedRows, createdRows, len(failedRows)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertImportedCustomers creates the customers along with their
+// customer.created events.
+func insertImportedCustomers(c context.Context, tx *sql.Tx, rows []model.CustomerImportRow) ([]model.CustomerImportRowError, error) {
+	stmt, err := tx.PrepareContext(c, `
+		INSERT INTO customers (id, name, email)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (lower(email)) WHERE deleted_at IS NULL DO NOTHING
+		RETURNING `+customerColumns)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var duplicates []model.CustomerImportRowError
+	for _, row := range rows {
+		customer, err := scanCustomer(stmt.QueryRowContext(c, uuid.New().String(), row.Name, row.Email))
+		if err == sql.ErrNoRows {
+			duplicates = append(duplicates, duplicateEmailError(row))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		event, err := model.NewDomainEvent(model.EventCustomerCreated, customer.ID, customer)
+		if err != nil {
+			return nil, err
+		}
+		if err := insertOutboxEvent(c, tx, event); err != nil {
+			return nil, err
+		}
+	}
+
+	return duplicates, nil
+}
+
+func findTakenEmails(c context.Context, tx *sql.Tx, rows []model.CustomerImportRow) ([]model.CustomerImportRowError, error) {
+	emails := make([]string, len(rows))
+	for i, row := range rows {
+		emails[i] = strings.ToLower(row.Email)
+	}
+
+	result, err := tx.QueryContext(c, `
+		SELECT lower(email)
+		FROM customers
+		WHERE deleted_at IS NULL AND lower(email) = ANY($1)
+	`, pq.Array(emails))
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	taken := make(map[string]bool)
+	for result.Next() {
+		var email string
+		if err := result.Scan(&email); err != nil {
+			return nil, err
+		}
+		taken[email] = true
+	}
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+
+	var duplicates []model.CustomerImportRowError
+	for _, row := range rows {
+		if taken[strings.ToLower(row.Email)] {
+			duplicates = append(duplicates, duplicateEmailError(row))
+		}
+	}
+
+	return duplicates, nil
+}
+
+func duplicateEmailError(row model.CustomerImportRow) model.CustomerImportRowError {
+	return model.CustomerImportRowError{Row: row.Row, Field: "email", Message: "email already exists"}
+}
+
+// Finish records the final status and drops the payload, which is no longer
+// needed.
+func (r *CustomerImportRepository) Finish(c context.Context, id string, status string, errorMessage string) error {
+	query := `
+		UPDATE customer_imports
+		SET status = $2, error = NULLIF($3, ''), finished_at = now(), payload = NULL
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(c, query, id, status, errorMessage)
+	return err
+}
@@ -0,0 +1,469 @@
+package db
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// erasureLockID serializes erasures so receipts form a single chain.
+const erasureLockID = 72_310_002
+
+// customerReferenceErasers tells how an erasure handles each table with a
+// foreign key to customers(id). Erasing fails while a referencing table is
+// missing here, so every new reference has to decide how it is erased.
+var customerReferenceErasers = map[string]func(c context.Context, tx *sql.Tx, customerID string) (model.ErasureAction, error){
+	// Favorites are kept for the aggregate statistics, but their notes and
+	// tags are free-form and are cleared.
+	"customers_favorite_products": func(c context.Context, tx *sql.Tx, customerID string) (model.ErasureAction, error) {
+		action := model.ErasureAction{Table: "customers_favorite_products", Action: model.ErasureActionAnonymized}
+		result, err := tx.ExecContext(c,
+			"UPDATE customers_favorite_products SET note = '', tags = '{}' WHERE customer_id = $1",
+			customerID,
+		)
+		if err != nil {
+			return action, err
+		}
+		action.Rows, err = result.RowsAffected()
+		return action, err
+	},
+	// Tags are free-form and may describe the customer, so they are deleted.
+	"customer_tags": func(c context.Context, tx *sql.Tx, customerID string) (model.ErasureAction, error) {
+		action := model.ErasureAction{Table: "customer_tags", Action: model.ErasureActionDeleted}
+		result, err := tx.ExecContext(c, "DELETE FROM customer_tags WHERE customer_id = $1", customerID)
+		if err != nil {
+			return action, err
+		}
+		action.Rows, err = result.RowsAffected()
+		return action, err
+	},
+	// Transitions are kept as the audit trail of the account, but their
+	// reasons are free-form and are cleared.
+	"customer_state_transitions": func(c context.Context, tx *sql.Tx, customerID string) (model.ErasureAction, error) {
+		action := model.ErasureAction{Table: "customer_state_transitions", Action: model.ErasureActionAnonymized}
+		result, err := tx.ExecContext(c,
+			"UPDATE customer_state_transitions SET reason = NULL WHERE customer_id = $1",
+			customerID,
+		)
+		if err != nil {
+			return action, err
+		}
+		action.Rows, err = result.RowsAffected()
+		return action, err
+	},
+	// Collection names and descriptions are free-form, so collections are
+	// deleted with their items. The favorites themselves are kept.
+	"favorite_collections": func(c context.Context, tx *sql.Tx, customerID string) (model.ErasureAction, error) {
+		action := model.ErasureAction{Table: "favorite_collections", Action: model.ErasureActionDeleted}
+		result, err := tx.ExecContext(c, "DELETE FROM favorite_collections WHERE customer_id = $1", customerID)
+		if err != nil {
+			return action, err
+		}
+		action.Rows, err = result.RowsAffected()
+		return action, err
+	},
+	// Price alert settings and alerts tell what the customer wanted to buy
+	// and when, so they are deleted.
+	"price_alert_settings": func(c context.Context, tx *sql.Tx, customerID string) (model.ErasureAction, error) {
+		action := model.ErasureAction{Table: "price_alert_settings", Action: model.ErasureActionDeleted}
+		result, err := tx.ExecContext(c, "DELETE FROM price_alert_settings WHERE customer_id = $1", customerID)
+		if err != nil {
+			return action, err
+		}
+		action.Rows, err = result.RowsAffected()
+		return action, err
+	},
+	"price_alerts": func(c context.Context, tx *sql.Tx, customerID string) (model.ErasureAction, error) {
+		action := model.ErasureAction{Table: "price_alerts", Action: model.ErasureActionDeleted}
+		result, err := tx.ExecContext(c, "DELETE FROM price_alerts WHERE customer_id = $1", customerID)
+		if err != nil {
+			return action, err
+		}
+		action.Rows, err = result.RowsAffected()
+		return action, err
+	},
+	// Share links would keep exposing the favorites, so they are deleted.
+	"share_links": func(c context.Context, tx *sql.Tx, customerID string) (model.ErasureAction, error) {
+		action := model.ErasureAction{Table: "share_links", Action: model.ErasureActionDeleted}
+		result, err := tx.ExecContext(c, "DELETE FROM share_links WHERE customer_id = $1", customerID)
+		if err != nil {
+			return action, err
+		}
+		action.Rows, err = result.RowsAffected()
+		return action, err
+	},
+	// Tombstones only tell clients syncing favorites what was removed, so
+	// they are deleted.
+	"favorite_tombstones": func(c context.Context, tx *sql.Tx, customerID string) (model.ErasureAction, error) {
+		action := model.ErasureAction{Table: "favorite_tombstones", Action: model.ErasureActionDeleted}
+		result, err := tx.ExecContext(c, "DELETE FROM favorite_tombstones WHERE customer_id = $1", customerID)
+		if err != nil {
+			return action, err
+		}
+		action.Rows, err = result.RowsAffected()
+		return action, err
+	},
+	// Favorite changes only feed the live streams of the customer, so they
+	// are deleted.
+	"favorite_changes": func(c context.Context, tx *sql.Tx, customerID string) (model.ErasureAction, error) {
+		action := model.ErasureAction{Table: "favorite_changes", Action: model.ErasureActionDeleted}
+		result, err := tx.ExecContext(c, "DELETE FROM favorite_changes WHERE customer_id = $1", customerID)
+		if err != nil {
+			return action, err
+		}
+		action.Rows, err = result.RowsAffected()
+		return action, err
+	},
+}
+
+const erasureReceiptColumns = "id, customer_id, erased_by, erased_at, actions, previous_hash, hash"
+
+type CustomerPrivacyRepository struct {
+	db *sql.DB
+}
+
+func NewCustomerPrivacyRepository(db *sql.DB) *CustomerPrivacyRepository {
+	return &CustomerPrivacyRepository{
+		db: db,
+	}
+}
+
+func scanErasureReceipt(row rowScanner) (*model.CustomerErasureReceipt, error) {
+	var (
+		receipt model.CustomerErasureReceipt
+		actions []byte
+	)
+	if err := row.Scan(
+		&receipt.ID,
+		&receipt.CustomerID,
+		&receipt.ErasedBy,
+		&receipt.ErasedAt,
+		&actions,
+		&receipt.PreviousHash,
+		&receipt.Hash,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(actions, &receipt.Actions); err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// FindDataExport returns everything stored about the customer, deleted or
+// erased included. It returns nil when the customer does not exist.
+func (r *CustomerPrivacyRepository) FindDataExport(c context.Context, customerID string) (*model.CustomerDataExport, error) {
+	tx, err := r.db.BeginTx(c, &sql.TxOptions{ReadOnly: true, Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var export model.CustomerDataExport
+	customer, err := scanCustomer(tx.QueryRowContext(c, `
+		SELECT `+customerColumns+`, erased_at
+		FROM customers
+		WHERE id = $1
+	`, customerID), &export.ErasedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	export.Customer = *customer
+
+	if export.Favorites, err = findCustomerFavorites(c, tx, customerID, ""); err != nil {
+		return nil, err
+	}
+
+	if export.Collections, err = findCustomerCollections(c, tx, customerID); err != nil {
+		return nil, err
+	}
+
+	if export.ShareLinks, err = findShareLinks(c, tx, customerID); err != nil {
+		return nil, err
+	}
+
+	if export.PriceAlertSettings, err = findPriceAlertSettings(c, tx, customerID); err != nil {
+		return nil, err
+	}
+
+	if export.PriceAlerts, err = findPriceAlerts(c, tx, customerID); err != nil {
+		return nil, err
+	}
+
+	if export.Tags, err = findCustomerTags(c, tx, customerID); err != nil {
+		return nil, err
+	}
+
+	if export.StateTransitions, err = findCustomerStateTransitions(c, tx, customerID); err != nil {
+		return nil, err
+	}
+
+	if export.RemovedFavorites, err = findFavoriteTombstones(c, tx, customerID, 0); err != nil {
+		return nil, err
+	}
+
+	if export.FavoriteChanges, err = findFavoriteChanges(c, tx, customerID, 0, 0); err != nil {
+		return nil, err
+	}
+
+	if export.ErasureReceipts, err = findErasureReceipts(c, tx, "WHERE customer_id = $1", customerID); err != nil {
+		return nil, err
+	}
+
+	return &export, tx.Commit()
+}
+
+func findCustomerTags(c context.Context, tx *sql.Tx, customerID string) ([]model.CustomerTag, error) {
+	rows, err := tx.QueryContext(c, `
+		SELECT tag, created_at
+		FROM customer_tags
+		WHERE customer_id = $1
+		ORDER BY tag
+	`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []model.CustomerTag{}
+	for rows.Next() {
+		var tag model.CustomerTag
+		if err := rows.Scan(&tag.Tag, &tag.CreatedAt); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func findErasureReceipts(c context.Context, tx *sql.Tx, where string, args ...any) ([]model.CustomerErasureReceipt, error) {
+	rows, err := tx.QueryContext(c, `
+		SELECT `+erasureReceiptColumns+`
+		FROM customer_erasure_receipts
+		`+where+`
+		ORDER BY seq
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipts := []model.CustomerErasureReceipt{}
+	for rows.Next() {
+		receipt, err := scanErasureReceipt(rows)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, *receipt)
+	}
+
+	return receipts, rows.Err()
+}
+
+// Erase anonymizes the customer, deleted or not, and records a receipt
+// chained to the previous one. The customer stays soft deleted and is never
+// purged, so its favorites keep counting in aggregates. Stored idempotent
+// responses mentioning the customer are deleted, the payloads of its customer
+// events and their webhook deliveries are reduced to its ID and a
+// customer.erased event is recorded. It returns domain.ErrNotFound for
+// unknown customers and domain.ErrAlreadyErased when erased before.
+func (r *CustomerPrivacyRepository) Erase(c context.Context, customerID string, erasedBy string) (*model.CustomerErasureReceipt, error) {
+	tx, err := r.db.BeginTx(c, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(c, "SELECT pg_advisory_xact_lock($1)", erasureLockID); err != nil {
+		return nil, err
+	}
+
+	var erasedAt *time.Time
+	err = tx.QueryRowContext(c, "SELECT erased_at FROM customers WHERE id = $1 FOR UPDATE", customerID).Scan(&erasedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if erasedAt != nil {
+		return nil, domain.ErrAlreadyErased
+	}
+
+	receipt := model.CustomerErasureReceipt{
+		ID:         uuid.New().String(),
+		CustomerID: customerID,
+		ErasedBy:   erasedBy,
+		ErasedAt:   time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	referenceActions, err := eraseCustomerReferences(c, tx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(c, `
+		UPDATE customers
+		SET name = 'Erased customer',
+			email = 'erased-' || id || '@erased.invalid',
+			attributes = '{}',
+			erased_at = $2,
+			deleted_at = COALESCE(deleted_at, $2),
+			version = version + 1,
+			updated_at = $2
+		WHERE id = $1
+	`, customerID, receipt.ErasedAt); err != nil {
+		return nil, err
+	}
+	receipt.Actions = append(receipt.Actions, model.ErasureAction{Table: "customers", Action: model.ErasureActionAnonymized, Rows: 1})
+	receipt.Actions = append(receipt.Actions, referenceActions...)
+
+	result, err := tx.ExecContext(c, `
+		DELETE FROM idempotency_keys
+		WHERE position(convert_to($1, 'UTF8') IN response_body) > 0
+	`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	deletedKeys, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	receipt.Actions = append(receipt.Actions, model.ErasureAction{Table: "idempotency_keys", Action: model.ErasureActionDeleted, Rows: deletedKeys})
+
+	result, err = tx.ExecContext(c, `
+		UPDATE outbox
+		SET payload = jsonb_build_object('id', aggregate_id)
+		WHERE aggregate_id = $1 AND type LIKE 'customer.%'
+	`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	anonymizedEvents, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	receipt.Actions = append(receipt.Actions, model.ErasureAction{Table: "outbox", Action: model.ErasureActionAnonymized, Rows: anonymizedEvents})
+
+	result, err = tx.ExecContext(c, `
+		UPDATE webhook_deliveries
+		SET payload = jsonb_set(payload, '{payload}', jsonb_build_object('id', aggregate_id))
+		WHERE aggregate_id = $1 AND event_type LIKE 'customer.%'
+	`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	anonymizedDeliveries, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	receipt.Actions = append(receipt.Actions, model.ErasureAction{Table: "webhook_deliveries", Action: model.ErasureActionAnonymized, Rows: anonymizedDeliveries})
+
+	event, err := model.NewDomainEvent(model.EventCustomerErased, customerID, model.CustomerRefEventPayload{ID: customerID})
+	if err != nil {
+		return nil, err
+	}
+	if err := insertOutboxEvent(c, tx, event); err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(c, "SELECT hash FROM customer_erasure_receipts ORDER BY seq DESC LIMIT 1").Scan(&receipt.PreviousHash)
+	if err == sql.ErrNoRows {
+		receipt.PreviousHash = domain.GenesisErasureHash
+	} else if err != nil {
+		return nil, err
+	}
+	receipt.Hash = domain.ErasureReceiptHash(receipt)
+
+	actions, err := json.Marshal(receipt.Actions)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(c, `
+		INSERT INTO customer_erasure_receipts (`+erasureReceiptColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		receipt.ID,
+		receipt.CustomerID,
+		receipt.ErasedBy,
+		receipt.ErasedAt,
+		actions,
+		receipt.PreviousHash,
+		receipt.Hash,
+	); err != nil {
+		return nil, err
+	}
+
+	return &receipt, tx.Commit()
+}
+
+// eraseCustomerReferences applies customerReferenceErasers to every table
+// referencing customers, failing on tables it does not know.
+func eraseCustomerReferences(c context.Context, tx *sql.Tx, customerID string) ([]model.ErasureAction, error) {
+	rows, err := tx.QueryContext(c, `
+		SELECT DISTINCT conrelid::regclass::text
+		FROM pg_constraint
+		WHERE contype = 'f' AND confrelid = 'customers'::regclass AND conrelid <> 'customers'::regclass
+		ORDER BY 1
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var actions []model.ErasureAction
+	for _, table := range tables {
+		erase, ok := customerReferenceErasers[table]
+		if !ok {
+			return nil, fmt.Errorf("erasure does not cover table %s referencing customers", table)
+		}
+
+		action, err := erase(c, tx, customerID)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
+
+// FindErasureReceipts returns every erasure receipt in chain order.
+func (r *CustomerPrivacyRepository) FindErasureReceipts(c context.Context) ([]model.CustomerErasureReceipt, error) {
+	tx, err := r.db.BeginTx(c, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	receipts, err := findErasureReceipts(c, tx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return receipts, tx.Commit()
+}
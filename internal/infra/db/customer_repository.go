@@ -1,11 +1,40 @@
 package db
 
 import (
+	"app/internal/domain"
 	"app/internal/domain/model"
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+const customerColumns = "id, name, email, attributes, state, version, created_at, updated_at, deleted_at"
+
+// customerEmailIndex enforces that active customers have distinct emails
+// ignoring case.
+const customerEmailIndex = "customers_email_lower_idx"
+
+// mapCustomerError turns the unique violation of the email index into
+// domain.ErrEmailAlreadyExists, so racing writes are reported as conflicts.
+func mapCustomerError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == customerEmailIndex {
+		return domain.ErrEmailAlreadyExists
+	}
+	return err
+}
+
+func encodeAttributes(attributes map[string]any) ([]byte, error) {
+	if attributes == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(attributes)
+}
+
 type CustomerRepository struct {
 	DB *sql.DB
 }
@@ -14,55 +43,133 @@ func NewCustomerRepository(db *sql.DB) *CustomerRepository {
 	return &CustomerRepository{DB: db}
 }
 
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanCustomer reads customerColumns followed by any extra columns into
+// extra.
+func scanCustomer(row rowScanner, extra ...any) (*model.Customer, error) {
+	var (
+		customer   model.Customer
+		attributes []byte
+	)
+	dest := []any{
+		&customer.ID,
+		&customer.Name,
+		&customer.Email,
+		&attributes,
+		&customer.State,
+		&customer.Version,
+		&customer.CreatedAt,
+		&customer.UpdatedAt,
+		&customer.DeletedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(attributes, &customer.Attributes); err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
 func (r *CustomerRepository) Create(c context.Context, customer model.Customer) (*model.Customer, error) {
+	attributes, err := encodeAttributes(customer.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		INSERT INTO customers (id, name, email)
-		VALUES ($1, $2, $3)
-		RETURNING id, name, email, created_at
-	`
+		INSERT INTO customers (id, name, email, attributes, state)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + customerColumns
 
-	row := r.DB.QueryRowContext(c, query, customer.ID, customer.Name, customer.Email)
+	row := conn(c, r.DB).QueryRowContext(c, query, customer.ID, customer.Name, customer.Email, attributes, customer.State)
 
-	var createdCustomer model.Customer
-	if err := row.Scan(
-		&createdCustomer.ID,
-		&createdCustomer.Name,
-		&createdCustomer.Email,
-		&createdCustomer.CreatedAt,
-	); err != nil {
-		return nil, err
+	createdCustomer, err := scanCustomer(row)
+	if err != nil {
+		return nil, mapCustomerError(err)
 	}
 
-	return &createdCustomer, nil
+	return createdCustomer, nil
 }
 
 func (r *CustomerRepository) FindByID(c context.Context, id string) (*model.Customer, error) {
 	query := `
-		SELECT id, name, email, created_at
+		SELECT ` + customerColumns + `
 		FROM customers
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	row := r.DB.QueryRowContext(c, query, id)
+	row := conn(c, r.DB).QueryRowContext(c, query, id)
 
-	var customer model.Customer
-	if err := row.Scan(&customer.ID, &customer.Name, &customer.Email, &customer.CreatedAt); err != nil {
+	customer, err := scanCustomer(row)
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	return &customer, nil
+	return customer, nil
+}
+
+// FindByIDForUpdate returns the customer like FindByID, locking it until the
+// transaction carried by c ends, so it can be changed without racing other
+// writers.
+func (r *CustomerRepository) FindByIDForUpdate(c context.Context, id string) (*model.Customer, error) {
+	query := `
+		SELECT ` + customerColumns + `
+		FROM customers
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	customer, err := scanCustomer(conn(c, r.DB).QueryRowContext(c, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// FindAll returns the customers that are not deleted, only those in state
+// when it is not empty.
+func (r *CustomerRepository) FindAll(c context.Context, state model.CustomerState) ([]model.Customer, error) {
+	query := `
+		SELECT ` + customerColumns + `
+		FROM customers
+		WHERE deleted_at IS NULL AND ($1::text = '' OR state = $1)
+	`
+
+	return r.queryCustomers(c, query, state)
 }
 
-func (r *CustomerRepository) FindAll(c context.Context) ([]model.Customer, error) {
+// FindByAttributes returns the customers that are not deleted whose
+// attributes include every given value, only those in state when it is not
+// empty.
+func (r *CustomerRepository) FindByAttributes(c context.Context, attributes map[string]any, state model.CustomerState) ([]model.Customer, error) {
+	filter, err := encodeAttributes(attributes)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT id, name, email, created_at
+		SELECT ` + customerColumns + `
 		FROM customers
+		WHERE deleted_at IS NULL AND attributes @> $1 AND ($2::text = '' OR state = $2)
 	`
 
-	rows, err := r.DB.QueryContext(c, query)
+	return r.queryCustomers(c, query, filter, state)
+}
+
+func (r *CustomerRepository) queryCustomers(c context.Context, query string, args ...any) ([]model.Customer, error) {
+	rows, err := conn(c, r.DB).QueryContext(c, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -70,49 +177,135 @@ func (r *CustomerRepository) FindAll(c context.Context) ([]model.Customer, error
 
 	var customers []model.Customer
 	for rows.Next() {
-		var customer model.Customer
-		if err := rows.Scan(
-			&customer.ID,
-			&customer.Name,
-			&customer.Email,
-			&customer.CreatedAt,
-		); err != nil {
+		customer, err := scanCustomer(rows)
+		if err != nil {
 			return nil, err
 		}
-		customers = append(customers, customer)
+		customers = append(customers, *customer)
 	}
 
-	return customers, nil
+	return customers, rows.Err()
 }
 
-func (r *CustomerRepository) Update(c context.Context, customer model.Customer) error {
+// Update writes name, email and attributes only if the stored version still
+// matches customer.Version, and returns the customer with its new version. It
+// returns nil when the version no longer matches or the customer does not
+// exist.
+func (r *CustomerRepository) Update(c context.Context, customer model.Customer) (*model.Customer, error) {
+	attributes, err := encodeAttributes(customer.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 		UPDATE customers
-		SET name = $1, email = $2, created_at = $3
-		WHERE id = $4
-	`
+		SET name = $1, email = $2, attributes = $3, version = version + 1, updated_at = now()
+		WHERE id = $4 AND version = $5 AND deleted_at IS NULL
+		RETURNING ` + customerColumns
 
-	_, err := r.DB.ExecContext(c, query,
+	row := conn(c, r.DB).QueryRowContext(c, query,
 		customer.Name,
 		customer.Email,
-		customer.CreatedAt,
+		attributes,
 		customer.ID,
+		customer.Version,
 	)
-	return err
+
+	updatedCustomer, err := scanCustomer(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, mapCustomerError(err)
+	}
+
+	return updatedCustomer, nil
 }
 
+// Delete soft deletes the customer. It is hidden from every read until it is
+// restored or purged.
 func (r *CustomerRepository) Delete(c context.Context, id string) error {
-	query := "DELETE FROM customers WHERE id = $1"
+	query := `
+		UPDATE customers
+		SET deleted_at = now(), version = version + 1, updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
 
-	_, err := r.DB.ExecContext(c, query, id)
+	_, err := conn(c, r.DB).ExecContext(c, query, id)
 	return err
 }
 
+func (r *CustomerRepository) FindDeletedByID(c context.Context, id string) (*model.Customer, error) {
+	query := `
+		SELECT ` + customerColumns + `
+		FROM customers
+		WHERE id = $1 AND deleted_at IS NOT NULL AND erased_at IS NULL
+	`
+
+	row := conn(c, r.DB).QueryRowContext(c, query, id)
+
+	customer, err := scanCustomer(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+func (r *CustomerRepository) FindDeleted(c context.Context) ([]model.Customer, error) {
+	query := `
+		SELECT ` + customerColumns + `
+		FROM customers
+		WHERE deleted_at IS NOT NULL AND erased_at IS NULL
+		ORDER BY deleted_at DESC
+	`
+
+	return r.queryCustomers(c, query)
+}
+
+// Restore undoes a soft delete made less than gracePeriod ago. It returns nil
+// when there is no such deleted customer.
+func (r *CustomerRepository) Restore(c context.Context, id string, gracePeriod time.Duration) (*model.Customer, error) {
+	query := `
+		UPDATE customers
+		SET deleted_at = NULL, version = version + 1, updated_at = now()
+		WHERE id = $1 AND erased_at IS NULL AND deleted_at > now() - make_interval(secs => $2)
+		RETURNING ` + customerColumns
+
+	row := conn(c, r.DB).QueryRowContext(c, query, id, gracePeriod.Seconds())
+
+	customer, err := scanCustomer(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, mapCustomerError(err)
+	}
+
+	return customer, nil
+}
+
+// PurgeDeleted permanently removes customers soft deleted more than
+// gracePeriod ago. Their favorites are removed by the foreign key cascade.
+// Erased customers are kept, already anonymized, for their favorites.
+func (r *CustomerRepository) PurgeDeleted(c context.Context, gracePeriod time.Duration) (int64, error) {
+	query := "DELETE FROM customers WHERE deleted_at <= now() - make_interval(secs => $1) AND erased_at IS NULL"
+
+	result, err := conn(c, r.DB).ExecContext(c, query, gracePeriod.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 func (r *CustomerRepository) FindByEmail(c context.Context, email string, id string) (*model.Customer, error) {
 	query := `
-		SELECT id, name, email, created_at
+		SELECT ` + customerColumns + `
 		FROM customers
-		WHERE email = $1
+		WHERE lower(email) = lower($1) AND deleted_at IS NULL
 	`
 	args := []interface{}{email}
 
@@ -120,15 +313,15 @@ func (r *CustomerRepository) FindByEmail(c context.Context, email string, id str
 		query += " AND id != $2"
 		args = append(args, id)
 	}
-	row := r.DB.QueryRowContext(c, query, args...)
+	row := conn(c, r.DB).QueryRowContext(c, query, args...)
 
-	var customer model.Customer
-	if err := row.Scan(&customer.ID, &customer.Name, &customer.Email, &customer.CreatedAt); err != nil {
+	customer, err := scanCustomer(row)
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	return &customer, nil
+	return customer, nil
 }
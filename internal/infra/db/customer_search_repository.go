@@ -0,0 +1,109 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+	"strconv"
+)
+
+// CustomerSearchRepository ranks customers with pg_trgm word similarity over
+// unaccented, lowercased name and email, using the trigram indexes.
+type CustomerSearchRepository struct {
+	db        *sql.DB
+	threshold float64
+}
+
+func NewCustomerSearchRepository(db *sql.DB, threshold float64) *CustomerSearchRepository {
+	return &CustomerSearchRepository{
+		db:        db,
+		threshold: threshold,
+	}
+}
+
+// TrigramSearchAvailable reports whether the database has what
+// CustomerSearchRepository needs.
+func TrigramSearchAvailable(c context.Context, db *sql.DB) (bool, error) {
+	query := `
+		SELECT
+			(SELECT count(*) FROM pg_extension WHERE extname IN ('pg_trgm', 'unaccent')) = 2
+			AND EXISTS (SELECT 1 FROM pg_proc WHERE proname = 'search_normalize')
+	`
+
+	var available bool
+	if err := db.QueryRowContext(c, query).Scan(&available); err != nil {
+		return false, err
+	}
+	return available, nil
+}
+
+func (r *CustomerSearchRepository) Search(c context.Context, query string, limit int, offset int) ([]model.CustomerMatch, int, error) {
+	tx, err := r.db.BeginTx(c, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	// The threshold of the <% operator is a setting, scoped here to the
+	// transaction.
+	if _, err := tx.ExecContext(c,
+		"SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)",
+		strconv.FormatFloat(r.threshold, 'f', -1, 64),
+	); err != nil {
+		return nil, 0, err
+	}
+
+	sqlQuery := `
+		WITH term AS (SELECT search_normalize($1) AS value)
+		SELECT ` + customerColumns + `, score, count(*) OVER () AS total
+		FROM (
+			SELECT customers.*, GREATEST(
+				word_similarity(term.value, search_normalize(name)),
+				word_similarity(term.value, search_normalize(email))
+			) AS score
+			FROM customers, term
+			WHERE deleted_at IS NULL
+				AND (term.value <% search_normalize(name) OR term.value <% search_normalize(email))
+		) matches
+		ORDER BY score DESC, name, id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := tx.QueryContext(c, sqlQuery, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		matches []model.CustomerMatch
+		total   int
+	)
+	for rows.Next() {
+		var score float64
+		customer, err := scanCustomer(rows, &score, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		matches = append(matches, model.CustomerMatch{Customer: *customer, Score: score})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// An offset past the last match returns no row to read the total from.
+	if len(matches) == 0 && offset > 0 {
+		countQuery := `
+			WITH term AS (SELECT search_normalize($1) AS value)
+			SELECT count(*)
+			FROM customers, term
+			WHERE deleted_at IS NULL
+				AND (term.value <% search_normalize(name) OR term.value <% search_normalize(email))
+		`
+		if err := tx.QueryRowContext(c, countQuery, query).Scan(&total); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return matches, total, nil
+}
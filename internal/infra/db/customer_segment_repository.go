@@ -0,0 +1,290 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"app/internal/domain/segment"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const customerSegmentColumns = "id, name, COALESCE(description, ''), expression, created_by, created_at, updated_at"
+
+type CustomerSegmentRepository struct {
+	db *sql.DB
+}
+
+func NewCustomerSegmentRepository(db *sql.DB) *CustomerSegmentRepository {
+	return &CustomerSegmentRepository{
+		db: db,
+	}
+}
+
+func scanCustomerSegment(row rowScanner) (*model.CustomerSegment, error) {
+	var customerSegment model.CustomerSegment
+	if err := row.Scan(
+		&customerSegment.ID,
+		&customerSegment.Name,
+		&customerSegment.Description,
+		&customerSegment.Expression,
+		&customerSegment.CreatedBy,
+		&customerSegment.CreatedAt,
+		&customerSegment.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &customerSegment, nil
+}
+
+func (r *CustomerSegmentRepository) Create(c context.Context, customerSegment model.CustomerSegment) (*model.CustomerSegment, error) {
+	query := `
+		INSERT INTO customer_segments (id, name, description, expression, created_by)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+		RETURNING ` + customerSegmentColumns
+
+	return scanCustomerSegment(r.db.QueryRowContext(c, query,
+		customerSegment.ID,
+		customerSegment.Name,
+		customerSegment.Description,
+		customerSegment.Expression,
+		customerSegment.CreatedBy,
+	))
+}
+
+func (r *CustomerSegmentRepository) FindByID(c context.Context, id string) (*model.CustomerSegment, error) {
+	query := `
+		SELECT ` + customerSegmentColumns + `
+		FROM customer_segments
+		WHERE id = $1
+	`
+
+	customerSegment, err := scanCustomerSegment(r.db.QueryRowContext(c, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return customerSegment, nil
+}
+
+func (r *CustomerSegmentRepository) FindAll(c context.Context) ([]model.CustomerSegment, error) {
+	query := `
+		SELECT ` + customerSegmentColumns + `
+		FROM customer_segments
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(c, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	segments := []model.CustomerSegment{}
+	for rows.Next() {
+		customerSegment, err := scanCustomerSegment(rows)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, *customerSegment)
+	}
+
+	return segments, rows.Err()
+}
+
+// Update replaces the name, description and expression of the segment. It
+// returns nil when the segment does not exist.
+func (r *CustomerSegmentRepository) Update(c context.Context, customerSegment model.CustomerSegment) (*model.CustomerSegment, error) {
+	query := `
+		UPDATE customer_segments
+		SET name = $2, description = NULLIF($3, ''), expression = $4, updated_at = now()
+		WHERE id = $1
+		RETURNING ` + customerSegmentColumns
+
+	updatedSegment, err := scanCustomerSegment(r.db.QueryRowContext(c, query,
+		customerSegment.ID,
+		customerSegment.Name,
+		customerSegment.Description,
+		customerSegment.Expression,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return updatedSegment, nil
+}
+
+// Delete removes the segment. It returns false when it does not exist.
+func (r *CustomerSegmentRepository) Delete(c context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(c, "DELETE FROM customer_segments WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+// FindMembers returns one page of the active customers matching expr, oldest
+// first, and the total number of members. categories maps product IDs to
+// their catalog category, for favorites counted by category.
+func (r *CustomerSegmentRepository) FindMembers(
+	c context.Context,
+	expr segment.Expr,
+	categories map[int]string,
+	limit int,
+	offset int,
+) ([]model.Customer, int, error) {
+	compiler, err := newSegmentCompiler(categories)
+	if err != nil {
+		return nil, 0, err
+	}
+	where := compiler.compile(expr)
+
+	query := `
+		SELECT ` + customerColumns + `, count(*) OVER ()
+		FROM customers
+		WHERE deleted_at IS NULL AND (` + where + `)
+		ORDER BY created_at, id
+		LIMIT ` + compiler.arg(limit) + ` OFFSET ` + compiler.arg(offset)
+
+	rows, err := r.db.QueryContext(c, query, compiler.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		customers = []model.Customer{}
+		total     int
+	)
+	for rows.Next() {
+		customer, err := scanCustomer(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		customers = append(customers, *customer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// An offset past the last member returns no row to read the total from.
+	if len(customers) == 0 && offset > 0 {
+		total, err = r.CountMembers(c, expr, categories)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return customers, total, nil
+}
+
+// CountMembers returns the number of active customers matching expr.
+func (r *CustomerSegmentRepository) CountMembers(c context.Context, expr segment.Expr, categories map[int]string) (int, error) {
+	compiler, err := newSegmentCompiler(categories)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		SELECT count(*)
+		FROM customers
+		WHERE deleted_at IS NULL AND (` + compiler.compile(expr) + `)
+	`
+
+	var total int
+	err = r.db.QueryRowContext(c, query, compiler.args...).Scan(&total)
+	return total, err
+}
+
+// segmentCompiler turns a segment expression into a SQL condition over the
+// customers table, collecting its arguments.
+type segmentCompiler struct {
+	args       []any
+	categories string
+}
+
+func newSegmentCompiler(categories map[int]string) (*segmentCompiler, error) {
+	compiler := &segmentCompiler{categories: "'{}'::jsonb"}
+	if categories != nil {
+		encoded, err := json.Marshal(categories)
+		if err != nil {
+			return nil, err
+		}
+		compiler.categories = compiler.arg(encoded) + "::jsonb"
+	}
+	return compiler, nil
+}
+
+func (s *segmentCompiler) arg(value any) string {
+	s.args = append(s.args, value)
+	return "$" + strconv.Itoa(len(s.args))
+}
+
+func (s *segmentCompiler) compile(expr segment.Expr) string {
+	switch e := expr.(type) {
+	case segment.And:
+		return "(" + s.compile(e.Left) + " AND " + s.compile(e.Right) + ")"
+	case segment.Or:
+		return "(" + s.compile(e.Left) + " OR " + s.compile(e.Right) + ")"
+	case segment.Not:
+		return "NOT COALESCE(" + s.compile(e.Expr) + ", false)"
+	case segment.Comparison:
+		return s.compileComparison(e)
+	}
+	panic(fmt.Sprintf("unknown segment expression %T", expr))
+}
+
+func (s *segmentCompiler) compileComparison(comparison segment.Comparison) string {
+	op := comparison.Op
+	if op == segment.OpNotEqual {
+		op = "<>"
+	}
+
+	switch comparison.Field.Name {
+	case segment.FieldName, segment.FieldEmail:
+		column := comparison.Field.Name
+		if comparison.Op == segment.OpContains {
+			return "strpos(lower(" + column + "), lower(" + s.arg(comparison.Value) + "::text)) > 0"
+		}
+		return "lower(" + column + ") " + op + " lower(" + s.arg(comparison.Value) + "::text)"
+
+	case segment.FieldCreatedAt:
+		return "created_at " + op + " " + s.arg(comparison.Value) + "::timestamp"
+
+	case segment.FieldAttributes:
+		key := s.arg(comparison.Field.Key) + "::text"
+		if comparison.Op == segment.OpEqual || comparison.Op == segment.OpNotEqual {
+			value, _ := json.Marshal(comparison.Value)
+			contains := "attributes @> jsonb_build_object(" + key + ", " + s.arg(string(value)) + "::jsonb)"
+			if comparison.Op == segment.OpNotEqual {
+				return "NOT " + contains
+			}
+			return contains
+		}
+		return "CASE WHEN jsonb_typeof(attributes -> " + key + ") = 'number' THEN (attributes ->> " + key + ")::numeric END " +
+			op + " " + s.arg(comparison.Value) + "::numeric"
+
+	case segment.FieldTags:
+		return "EXISTS (SELECT 1 FROM customer_tags t WHERE t.customer_id = customers.id AND t.tag = " +
+			s.arg(comparison.Value) + "::text)"
+
+	case segment.FieldFavorites:
+		condition := ""
+		if comparison.Field.Key != "" {
+			condition = " AND " + s.categories + " ->> f.product_id::text = " + s.arg(comparison.Field.Key) + "::text"
+		}
+		return "(SELECT count(*) FROM customers_favorite_products f WHERE f.customer_id = customers.id" + condition + ") " +
+			op + " " + s.arg(int64(comparison.Value.(float64)))
+	}
+
+	panic("unknown segment field " + comparison.Field.Name)
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+)
+
+type CustomerStateRepository struct {
+	db *sql.DB
+}
+
+func NewCustomerStateRepository(db *sql.DB) *CustomerStateRepository {
+	return &CustomerStateRepository{
+		db: db,
+	}
+}
+
+// Transition moves the customer to transition.To and records the transition
+// in the same transaction, joining the one carried by c if any. The change
+// only happens if the customer is still in transition.From and, when version
+// is not zero, still has that version. It returns nil when either no longer
+// holds or the customer does not exist.
+func (r *CustomerStateRepository) Transition(
+	c context.Context,
+	transition model.CustomerStateTransition,
+	version int,
+) (*model.Customer, error) {
+	var customer *model.Customer
+	err := inTx(c, r.db, func(c context.Context) error {
+		var err error
+		customer, err = scanCustomer(conn(c, r.db).QueryRowContext(c, `
+			UPDATE customers
+			SET state = $1, version = version + 1, updated_at = now()
+			WHERE id = $2 AND state = $3 AND ($4 = 0 OR version = $4) AND deleted_at IS NULL
+			RETURNING `+customerColumns,
+			transition.To,
+			transition.CustomerID,
+			transition.From,
+			version,
+		))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				customer = nil
+				return nil
+			}
+			return err
+		}
+
+		_, err = conn(c, r.db).ExecContext(c, `
+			INSERT INTO customer_state_transitions (id, customer_id, from_state, to_state, reason, actor)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+		`,
+			transition.ID,
+			transition.CustomerID,
+			transition.From,
+			transition.To,
+			transition.Reason,
+			transition.Actor,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// FindByCustomerID returns the transitions of the customer, oldest first.
+func (r *CustomerStateRepository) FindByCustomerID(c context.Context, customerID string) ([]model.CustomerStateTransition, error) {
+	return findCustomerStateTransitions(c, r.db, customerID)
+}
+
+func findCustomerStateTransitions(c context.Context, q DBTX, customerID string) ([]model.CustomerStateTransition, error) {
+	rows, err := q.QueryContext(c, `
+		SELECT id, customer_id, from_state, to_state, COALESCE(reason, ''), actor, created_at
+		FROM customer_state_transitions
+		WHERE customer_id = $1
+		ORDER BY created_at, id
+	`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transitions := []model.CustomerStateTransition{}
+	for rows.Next() {
+		var transition model.CustomerStateTransition
+		if err := rows.Scan(
+			&transition.ID,
+			&transition.CustomerID,
+			&transition.From,
+			&transition.To,
+			&transition.Reason,
+			&transition.Actor,
+			&transition.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, transition)
+	}
+
+	return transitions, rows.Err()
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+)
+
+type CustomerTagRepository struct {
+	db *sql.DB
+}
+
+func NewCustomerTagRepository(db *sql.DB) *CustomerTagRepository {
+	return &CustomerTagRepository{
+		db: db,
+	}
+}
+
+func (r *CustomerTagRepository) FindByCustomerID(c context.Context, customerID string) ([]model.CustomerTag, error) {
+	query := `
+		SELECT tag, created_at
+		FROM customer_tags
+		WHERE customer_id = $1
+		ORDER BY tag
+	`
+
+	rows, err := r.db.QueryContext(c, query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []model.CustomerTag{}
+	for rows.Next() {
+		var tag model.CustomerTag
+		if err := rows.Scan(&tag.Tag, &tag.CreatedAt); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (r *CustomerTagRepository) Add(c context.Context, customerID string, tag string) error {
+	query := `
+		INSERT INTO customer_tags (customer_id, tag)
+		VALUES ($1, $2)
+		ON CONFLICT (customer_id, tag) DO NOTHING
+	`
+	_, err := r.db.ExecContext(c, query, customerID, tag)
+	return err
+}
+
+// Remove deletes the tag from the customer. It returns false when the
+// customer did not have it.
+func (r *CustomerTagRepository) Remove(c context.Context, customerID string, tag string) (bool, error) {
+	query := "DELETE FROM customer_tags WHERE customer_id = $1 AND tag = $2"
+
+	result, err := r.db.ExecContext(c, query, customerID, tag)
+	if err != nil {
+		return false, err
+	}
+
+	removed, err := result.RowsAffected()
+	return removed > 0, err
+}
+
+// CountByTag returns every tag in use with its number of active customers.
+func (r *CustomerTagRepository) CountByTag(c context.Context) ([]model.TagCount, error) {
+	query := `
+		SELECT t.tag, count(*)
+		FROM customer_tags t
+		JOIN customers ON customers.id = t.customer_id AND customers.deleted_at IS NULL
+		GROUP BY t.tag
+		ORDER BY t.tag
+	`
+
+	rows, err := r.db.QueryContext(c, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []model.TagCount{}
+	for rows.Next() {
+		var count model.TagCount
+		if err := rows.Scan(&count.Tag, &count.Customers); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,174 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// analyticsRefreshLockID lets a single replica refresh the analytics views
+// at a time.
+const analyticsRefreshLockID = 72_310_004
+
+// favoriteAnalyticsViews are refreshed in order by Refresh.
+var favoriteAnalyticsViews = []string{
+	"favorite_daily_activity",
+	"favorite_product_counts",
+	"favorite_summary",
+}
+
+type FavoriteAnalyticsRepository struct {
+	db *sql.DB
+}
+
+func NewFavoriteAnalyticsRepository(db *sql.DB) *FavoriteAnalyticsRepository {
+	return &FavoriteAnalyticsRepository{
+		db: db,
+	}
+}
+
+// recordFavoriteActivity adds the addition or removal of a favorite to the
+// history behind the analytics views.
+func recordFavoriteActivity(c context.Context, q DBTX, productID int, activityType string) error {
+	_, err := q.ExecContext(c,
+		"INSERT INTO favorite_activity (product_id, type) VALUES ($1, $2)",
+		productID, activityType,
+	)
+	return err
+}
+
+// Refresh recomputes the analytics views without blocking their readers. It
+// returns false without refreshing when another replica is refreshing them.
+func (r *FavoriteAnalyticsRepository) Refresh(c context.Context) (bool, error) {
+	tx, err := r.db.BeginTx(c, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(c, "SELECT pg_try_advisory_xact_lock($1)", analyticsRefreshLockID).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+
+	for _, view := range favoriteAnalyticsViews {
+		if _, err := tx.ExecContext(c, "REFRESH MATERIALIZED VIEW CONCURRENTLY "+view); err != nil {
+			return false, err
+		}
+	}
+
+	if _, err := tx.ExecContext(c, "UPDATE favorite_analytics_refresh SET refreshed_at = now()"); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// RefreshedAt returns when the analytics views were last refreshed.
+func (r *FavoriteAnalyticsRepository) RefreshedAt(c context.Context) (time.Time, error) {
+	var refreshedAt time.Time
+	err := r.db.QueryRowContext(c, "SELECT refreshed_at FROM favorite_analytics_refresh").Scan(&refreshedAt)
+	return refreshedAt, err
+}
+
+// FindPopular returns up to limit products by favorites added in the last
+// days, today included, the most added first.
+func (r *FavoriteAnalyticsRepository) FindPopular(c context.Context, days int, limit int) ([]model.PopularProduct, error) {
+	rows, err := r.db.QueryContext(c, `
+		SELECT a.product_id, sum(a.added), sum(a.removed), COALESCE(p.favorites, 0)
+		FROM favorite_daily_activity a
+		LEFT JOIN favorite_product_counts p ON p.product_id = a.product_id
+		WHERE a.day > current_date - $1::int
+		GROUP BY a.product_id, p.favorites
+		HAVING sum(a.added) > 0
+		ORDER BY sum(a.added) DESC, COALESCE(p.favorites, 0) DESC, a.product_id
+		LIMIT $2
+	`, days, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []model.PopularProduct{}
+	for rows.Next() {
+		var product model.PopularProduct
+		if err := rows.Scan(&product.ProductID, &product.Added, &product.Removed, &product.Favorites); err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	return products, rows.Err()
+}
+
+// FindDailyActivity returns the favorites added and removed on each of the
+// last days, today included, oldest first. Days without changes are zero.
+func (r *FavoriteAnalyticsRepository) FindDailyActivity(c context.Context, days int) ([]model.FavoriteDailyActivity, error) {
+	rows, err := r.db.QueryContext(c, `
+		SELECT to_char(d.day, 'YYYY-MM-DD'), COALESCE(sum(a.added), 0), COALESCE(sum(a.removed), 0)
+		FROM generate_series(current_date - ($1::int - 1), current_date, interval '1 day') AS d(day)
+		LEFT JOIN favorite_daily_activity a ON a.day = d.day::date
+		GROUP BY d.day
+		ORDER BY d.day
+	`, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := []model.FavoriteDailyActivity{}
+	for rows.Next() {
+		var day model.FavoriteDailyActivity
+		if err := rows.Scan(&day.Day, &day.Added, &day.Removed); err != nil {
+			return nil, err
+		}
+		activity = append(activity, day)
+	}
+
+	return activity, rows.Err()
+}
+
+// FindSummary returns the counts of customers and favorites, leaving the
+// averages and RefreshedAt to the caller.
+func (r *FavoriteAnalyticsRepository) FindSummary(c context.Context) (*model.FavoriteSummary, error) {
+	var summary model.FavoriteSummary
+	err := r.db.QueryRowContext(c, `
+		SELECT customers, customers_with_favorites, favorites
+		FROM favorite_summary
+	`).Scan(&summary.Customers, &summary.CustomersWithFavorites, &summary.Favorites)
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// FindProductCounts returns one page of the products with favorites, the
+// most favorited first, and the number of such products.
+func (r *FavoriteAnalyticsRepository) FindProductCounts(c context.Context, limit int, offset int) ([]model.ProductFavoriteCount, int, error) {
+	rows, err := r.db.QueryContext(c, `
+		SELECT product_id, favorites, count(*) OVER ()
+		FROM favorite_product_counts
+		ORDER BY favorites DESC, product_id
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	counts := []model.ProductFavoriteCount{}
+	total := 0
+	for rows.Next() {
+		var count model.ProductFavoriteCount
+		if err := rows.Scan(&count.ProductID, &count.Favorites, &total); err != nil {
+			return nil, 0, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, total, rows.Err()
+}
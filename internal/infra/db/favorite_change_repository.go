@@ -0,0 +1,118 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// FavoriteChangesChannel is the notification channel announcing favorite
+// changes. The payload is the ID of the customer.
+const FavoriteChangesChannel = "favorite_changes"
+
+type FavoriteChangeRepository struct {
+	db *sql.DB
+}
+
+func NewFavoriteChangeRepository(db *sql.DB) *FavoriteChangeRepository {
+	return &FavoriteChangeRepository{
+		db: db,
+	}
+}
+
+// Append records the change and notifies FavoriteChangesChannel. Called with
+// a context carrying a transaction, both take effect when it commits. The
+// changes of a customer are serialized until commit, so their IDs are
+// committed in order.
+func (r *FavoriteChangeRepository) Append(c context.Context, customerID string, changeType string, productID int) error {
+	db := conn(c, r.db)
+
+	if err := lockCustomerFavorites(c, db, customerID); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(c, `
+		INSERT INTO favorite_changes (customer_id, type, product_id)
+		VALUES ($1, $2, $3)
+	`, customerID, changeType, productID); err != nil {
+		return err
+	}
+
+	_, err := db.ExecContext(c, "SELECT pg_notify($1, $2)", FavoriteChangesChannel, customerID)
+	return err
+}
+
+// FindSince returns up to limit changes of the customer after afterID,
+// oldest first.
+func (r *FavoriteChangeRepository) FindSince(c context.Context, customerID string, afterID int64, limit int) ([]model.FavoriteChange, error) {
+	return findFavoriteChanges(c, r.db, customerID, afterID, limit)
+}
+
+// findFavoriteChanges returns the changes of the customer after afterID,
+// oldest first. A limit of 0 returns all of them.
+func findFavoriteChanges(c context.Context, q DBTX, customerID string, afterID int64, limit int) ([]model.FavoriteChange, error) {
+	rows, err := q.QueryContext(c, `
+		SELECT id, customer_id, type, product_id, changed_at
+		FROM favorite_changes
+		WHERE customer_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT NULLIF($3::int, 0)
+	`, customerID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []model.FavoriteChange{}
+	for rows.Next() {
+		var change model.FavoriteChange
+		if err := rows.Scan(
+			&change.ID,
+			&change.CustomerID,
+			&change.Type,
+			&change.ProductID,
+			&change.ChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+// LatestID returns the ID of the last change of the customer, or 0.
+func (r *FavoriteChangeRepository) LatestID(c context.Context, customerID string) (int64, error) {
+	var id int64
+	err := r.db.QueryRowContext(c,
+		"SELECT COALESCE(max(id), 0) FROM favorite_changes WHERE customer_id = $1",
+		customerID,
+	).Scan(&id)
+	return id, err
+}
+
+// OldestID returns the ID of the oldest change kept. Changes before it may
+// have been removed. With no changes kept it is the ID the next change gets.
+func (r *FavoriteChangeRepository) OldestID(c context.Context) (int64, error) {
+	var id int64
+	err := r.db.QueryRowContext(c, `
+		SELECT COALESCE(
+			(SELECT min(id) FROM favorite_changes),
+			COALESCE(pg_sequence_last_value('favorite_changes_id_seq'), 0) + 1
+		)
+	`).Scan(&id)
+	return id, err
+}
+
+// DeleteOlderThan removes changes made longer than retention ago.
+func (r *FavoriteChangeRepository) DeleteOlderThan(c context.Context, retention time.Duration) (int64, error) {
+	result, err := r.db.ExecContext(c,
+		"DELETE FROM favorite_changes WHERE changed_at < now() - make_interval(secs => $1)",
+		retention.Seconds(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,311 @@
+package db
+
+import (
+	"app/internal/domain"
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const favoriteCollectionColumns = `
+	id, customer_id, name, COALESCE(description, ''), public, position,
+	(SELECT count(*) FROM favorite_collection_items i WHERE i.collection_id = favorite_collections.id),
+	created_at, updated_at`
+
+const (
+	// favoriteCollectionNameIndex enforces distinct collection names per
+	// customer ignoring case.
+	favoriteCollectionNameIndex = "favorite_collections_name_idx"
+	// favoriteCollectionItemFavoriteKey requires collection items to be
+	// favorites of the customer.
+	favoriteCollectionItemFavoriteKey = "favorite_collection_items_favorite_fkey"
+)
+
+// mapFavoriteCollectionError turns violations of the name index and of the
+// favorite reference into domain errors.
+func mapFavoriteCollectionError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == "23505" && pqErr.Constraint == favoriteCollectionNameIndex:
+			return domain.ErrCollectionAlreadyExists
+		case pqErr.Code == "23503" && pqErr.Constraint == favoriteCollectionItemFavoriteKey:
+			return domain.ErrFavoriteNotFound
+		}
+	}
+	return err
+}
+
+type FavoriteCollectionRepository struct {
+	db *sql.DB
+}
+
+func NewFavoriteCollectionRepository(db *sql.DB) *FavoriteCollectionRepository {
+	return &FavoriteCollectionRepository{
+		db: db,
+	}
+}
+
+func scanFavoriteCollection(row rowScanner) (*model.FavoriteCollection, error) {
+	var collection model.FavoriteCollection
+	if err := row.Scan(
+		&collection.ID,
+		&collection.CustomerID,
+		&collection.Name,
+		&collection.Description,
+		&collection.Public,
+		&collection.Position,
+		&collection.Favorites,
+		&collection.CreatedAt,
+		&collection.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// Create adds the collection after the last collection of the customer.
+func (r *FavoriteCollectionRepository) Create(c context.Context, collection model.FavoriteCollection) (*model.FavoriteCollection, error) {
+	query := `
+		INSERT INTO favorite_collections (id, customer_id, name, description, public, position)
+		SELECT $1::uuid, $2::uuid, $3::text, NULLIF($4::text, ''), $5::boolean,
+			COALESCE((SELECT max(position) + 1 FROM favorite_collections WHERE customer_id = $2::uuid), 0)
+		RETURNING ` + favoriteCollectionColumns
+
+	created, err := scanFavoriteCollection(r.db.QueryRowContext(c, query,
+		collection.ID,
+		collection.CustomerID,
+		collection.Name,
+		collection.Description,
+		collection.Public,
+	))
+	if err != nil {
+		return nil, mapFavoriteCollectionError(err)
+	}
+
+	return created, nil
+}
+
+func (r *FavoriteCollectionRepository) FindByCustomerID(c context.Context, customerID string) ([]model.FavoriteCollection, error) {
+	query := `
+		SELECT ` + favoriteCollectionColumns + `
+		FROM favorite_collections
+		WHERE customer_id = $1
+		ORDER BY position, created_at
+	`
+
+	rows, err := r.db.QueryContext(c, query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := []model.FavoriteCollection{}
+	for rows.Next() {
+		collection, err := scanFavoriteCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, *collection)
+	}
+
+	return collections, rows.Err()
+}
+
+// FindByID returns nil when the customer has no collection with the ID.
+func (r *FavoriteCollectionRepository) FindByID(c context.Context, customerID string, id string) (*model.FavoriteCollection, error) {
+	query := `
+		SELECT ` + favoriteCollectionColumns + `
+		FROM favorite_collections
+		WHERE id = $1 AND customer_id = $2
+	`
+
+	collection, err := scanFavoriteCollection(r.db.QueryRowContext(c, query, id, customerID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+// Update replaces the name, description and visibility of the collection. It
+// returns nil when the customer has no collection with the ID.
+func (r *FavoriteCollectionRepository) Update(c context.Context, collection model.FavoriteCollection) (*model.FavoriteCollection, error) {
+	query := `
+		UPDATE favorite_collections
+		SET name = $3, description = NULLIF($4, ''), public = $5, updated_at = now()
+		WHERE id = $1 AND customer_id = $2
+		RETURNING ` + favoriteCollectionColumns
+
+	updated, err := scanFavoriteCollection(r.db.QueryRowContext(c, query,
+		collection.ID,
+		collection.CustomerID,
+		collection.Name,
+		collection.Description,
+		collection.Public,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, mapFavoriteCollectionError(err)
+	}
+
+	return updated, nil
+}
+
+// Delete removes the collection, keeping its products as favorites. It
+// returns false when the customer has no collection with the ID.
+func (r *FavoriteCollectionRepository) Delete(c context.Context, customerID string, id string) (bool, error) {
+	result, err := r.db.ExecContext(c,
+		"DELETE FROM favorite_collections WHERE id = $1 AND customer_id = $2",
+		id, customerID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+// Reorder positions the collections of the customer in the order of ids. It
+// returns false unless ids lists every collection of the customer once.
+func (r *FavoriteCollectionRepository) Reorder(c context.Context, customerID string, ids []string) (bool, error) {
+	tx, err := r.db.BeginTx(c, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Locking the collections keeps collections created meanwhile from
+	// being left out of the order.
+	var total int
+	if err := tx.QueryRowContext(c, `
+		SELECT count(*) FROM (
+			SELECT id FROM favorite_collections WHERE customer_id = $1 FOR UPDATE
+		) locked
+	`, customerID).Scan(&total); err != nil {
+		return false, err
+	}
+	if total != len(ids) {
+		return false, nil
+	}
+
+	result, err := tx.ExecContext(c, `
+		UPDATE favorite_collections f
+		SET position = o.position - 1, updated_at = now()
+		FROM unnest($2::uuid[]) WITH ORDINALITY AS o(id, position)
+		WHERE f.id = o.id AND f.customer_id = $1
+	`, customerID, pq.Array(ids))
+	if err != nil {
+		return false, err
+	}
+
+	// Repeated IDs update a collection once, leaving another one out.
+	updated, err := result.RowsAffected()
+	if err != nil || int(updated) != len(ids) {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// AddItem adds the favorite to the collection. It returns
+// domain.ErrFavoriteNotFound when the product is not a favorite of the
+// customer owning the collection.
+func (r *FavoriteCollectionRepository) AddItem(c context.Context, customerID string, collectionID string, productID int) error {
+	query := `
+		INSERT INTO favorite_collection_items (collection_id, customer_id, product_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (collection_id, product_id) DO NOTHING
+	`
+	_, err := r.db.ExecContext(c, query, collectionID, customerID, productID)
+	return mapFavoriteCollectionError(err)
+}
+
+// RemoveItem returns false when the product was not in the collection.
+func (r *FavoriteCollectionRepository) RemoveItem(c context.Context, collectionID string, productID int) (bool, error) {
+	result, err := r.db.ExecContext(c,
+		"DELETE FROM favorite_collection_items WHERE collection_id = $1 AND product_id = $2",
+		collectionID, productID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	removed, err := result.RowsAffected()
+	return removed > 0, err
+}
+
+// FindProductIDs returns the products of the collection in the order they
+// were added.
+func (r *FavoriteCollectionRepository) FindProductIDs(c context.Context, collectionID string) ([]int, error) {
+	return findFavoriteCollectionProductIDs(c, r.db, collectionID)
+}
+
+func findFavoriteCollectionProductIDs(c context.Context, q DBTX, collectionID string) ([]int, error) {
+	rows, err := q.QueryContext(c, `
+		SELECT product_id
+		FROM favorite_collection_items
+		WHERE collection_id = $1
+		ORDER BY added_at, product_id
+	`, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	productIDs := []int{}
+	for rows.Next() {
+		var productID int
+		if err := rows.Scan(&productID); err != nil {
+			return nil, err
+		}
+		productIDs = append(productIDs, productID)
+	}
+
+	return productIDs, rows.Err()
+}
+
+// findCustomerCollections returns the collections of the customer with their
+// products.
+func findCustomerCollections(c context.Context, q DBTX, customerID string) ([]model.CustomerCollection, error) {
+	rows, err := q.QueryContext(c, `
+		SELECT `+favoriteCollectionColumns+`
+		FROM favorite_collections
+		WHERE customer_id = $1
+		ORDER BY position, created_at
+	`, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	collections := []model.CustomerCollection{}
+	for rows.Next() {
+		collection, err := scanFavoriteCollection(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		collections = append(collections, model.CustomerCollection{FavoriteCollection: *collection})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range collections {
+		if collections[i].ProductIDs, err = findFavoriteCollectionProductIDs(c, q, collections[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return collections, nil
+}
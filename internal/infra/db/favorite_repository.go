@@ -1,10 +1,17 @@
 package db
 
 import (
+	"app/internal/domain"
+	"app/internal/domain/model"
 	"context"
 	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+const favoriteColumns = "product_id, note, tags, priority, position, created_at"
+
 type FavoriteRepository struct {
 	db *sql.DB
 }
@@ -15,38 +22,363 @@ func NewFavoriteRepository(db *sql.DB) *FavoriteRepository {
 	}
 }
 
-func (r *FavoriteRepository) AddFavorite(c context.Context, customerID string, productID int) error {
+func scanFavorite(row rowScanner) (*model.CustomerFavorite, error) {
+	var favorite model.CustomerFavorite
+	if err := row.Scan(
+		&favorite.ProductID,
+		&favorite.Note,
+		pq.Array(&favorite.Tags),
+		&favorite.Priority,
+		&favorite.Position,
+		&favorite.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &favorite, nil
+}
+
+// lockCustomerFavorites serializes the changes to the favorites of the
+// customer until the transaction in c ends, so their sync IDs are committed
+// in order.
+func lockCustomerFavorites(c context.Context, q DBTX, customerID string) error {
+	_, err := q.ExecContext(c, "SELECT pg_advisory_xact_lock(hashtextextended($1, 0))", customerID)
+	return err
+}
+
+// AddFavorite adds the product after the last favorite of the customer. It
+// returns false, leaving the details unchanged, when the product already was
+// a favorite. Meant to run in a transaction.
+func (r *FavoriteRepository) AddFavorite(c context.Context, customerID string, productID int, details model.FavoriteDetails) (bool, error) {
+	db := conn(c, r.db)
+
+	if err := lockCustomerFavorites(c, db, customerID); err != nil {
+		return false, err
+	}
+
 	query := `
-		INSERT INTO customers_favorite_products (customer_id, product_id)
-		VALUES ($1, $2)
+		INSERT INTO customers_favorite_products (customer_id, product_id, note, tags, priority, position)
+		SELECT $1::uuid, $2::int, $3::text, COALESCE($4::text[], '{}'), $5::int, COALESCE(max(position) + 1, 0)
+		FROM customers_favorite_products
+		WHERE customer_id = $1::uuid
 		ON CONFLICT (customer_id, product_id) DO NOTHING
 	`
-	_, err := r.db.ExecContext(c, query, customerID, productID)
+	result, err := db.ExecContext(c, query, customerID, productID, details.Note, pq.Array(details.Tags), details.Priority)
 	if err != nil {
-		return err
+		return false, err
 	}
-	return nil
+
+	added, err := result.RowsAffected()
+	if err != nil || added == 0 {
+		return false, err
+	}
+
+	if err := recordFavoriteActivity(c, db, productID, model.FavoriteChangeAdded); err != nil {
+		return false, err
+	}
+
+	_, err = db.ExecContext(c,
+		"DELETE FROM favorite_tombstones WHERE customer_id = $1 AND product_id = $2",
+		customerID, productID,
+	)
+	return true, err
 }
 
-func (r *FavoriteRepository) RemoveFavorite(c context.Context, productID int) error {
-	query := `
-		DELETE FROM customers_favorite_products
-		WHERE product_id = $1
-	`
-	_, err := r.db.ExecContext(c, query, productID)
+// RemoveFavorite replaces the favorite with a tombstone and moves the
+// favorites after it up. It returns false when the product was not a favorite
+// of the customer. Meant to run in a transaction.
+func (r *FavoriteRepository) RemoveFavorite(c context.Context, customerID string, productID int) (bool, error) {
+	db := conn(c, r.db)
+
+	if err := lockCustomerFavorites(c, db, customerID); err != nil {
+		return false, err
+	}
+
+	var position int
+	err := db.QueryRowContext(c,
+		"DELETE FROM customers_favorite_products WHERE customer_id = $1 AND product_id = $2 RETURNING position",
+		customerID, productID,
+	).Scan(&position)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if _, err := db.ExecContext(c,
+		"UPDATE customers_favorite_products SET position = position - 1, sync_id = nextval('favorite_sync_seq') WHERE customer_id = $1 AND position > $2",
+		customerID, position,
+	); err != nil {
+		return false, err
+	}
+
+	if err := recordFavoriteActivity(c, db, productID, model.FavoriteChangeRemoved); err != nil {
+		return false, err
+	}
+
+	_, err = db.ExecContext(c, `
+		INSERT INTO favorite_tombstones (customer_id, product_id)
+		VALUES ($1, $2)
+		ON CONFLICT (customer_id, product_id)
+		DO UPDATE SET sync_id = nextval('favorite_sync_seq'), removed_at = now()
+	`, customerID, productID)
+	return true, err
+}
+
+// UpdateFavorite changes the details of the favorite and moves it when
+// update.Position is set, clamped to the positions in use. It returns nil
+// when the product is not a favorite of the customer. Meant to run in a
+// transaction.
+func (r *FavoriteRepository) UpdateFavorite(c context.Context, customerID string, productID int, update model.FavoriteUpdate) (*model.CustomerFavorite, error) {
+	db := conn(c, r.db)
+
+	if err := lockCustomerFavorites(c, db, customerID); err != nil {
+		return nil, err
+	}
+
+	if update.Position != nil {
+		// Favorites between the current and the target position shift by
+		// one towards the current position.
+		_, err := db.ExecContext(c, `
+			UPDATE customers_favorite_products f
+			SET position = CASE
+				WHEN f.product_id = $2 THEN m.target
+				WHEN m.current < m.target THEN f.position - 1
+				ELSE f.position + 1
+			END,
+			sync_id = nextval('favorite_sync_seq')
+			FROM (
+				SELECT position AS current, LEAST(GREATEST($3::int, 0), (
+					SELECT count(*) - 1 FROM customers_favorite_products WHERE customer_id = $1
+				)) AS target
+				FROM customers_favorite_products
+				WHERE customer_id = $1 AND product_id = $2
+			) m
+			WHERE f.customer_id = $1
+				AND f.position BETWEEN LEAST(m.current, m.target) AND GREATEST(m.current, m.target)
+		`, customerID, productID, *update.Position)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var tags any
+	if update.Tags != nil {
+		tags = pq.Array(*update.Tags)
+	}
+
+	favorite, err := scanFavorite(db.QueryRowContext(c, `
+		UPDATE customers_favorite_products
+		SET note = COALESCE($3, note), tags = COALESCE($4::text[], tags), priority = COALESCE($5, priority),
+			sync_id = nextval('favorite_sync_seq')
+		WHERE customer_id = $1 AND product_id = $2
+		RETURNING `+favoriteColumns,
+		customerID, productID, update.Note, tags, update.Priority,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return favorite, nil
+}
+
+// Reorder positions the favorites in the order of productIDs. It returns
+// false when productIDs does not list every favorite of the customer once.
+// Meant to run in a transaction.
+func (r *FavoriteRepository) Reorder(c context.Context, customerID string, productIDs []int) (bool, error) {
+	db := conn(c, r.db)
+
+	if err := lockCustomerFavorites(c, db, customerID); err != nil {
+		return false, err
+	}
+
+	var total int
+	if err := db.QueryRowContext(c,
+		"SELECT count(*) FROM customers_favorite_products WHERE customer_id = $1",
+		customerID,
+	).Scan(&total); err != nil {
+		return false, err
+	}
+	if total != len(productIDs) {
+		return false, nil
+	}
+
+	result, err := db.ExecContext(c, `
+		UPDATE customers_favorite_products f
+		SET position = o.position - 1, sync_id = nextval('favorite_sync_seq')
+		FROM unnest($2::int[]) WITH ORDINALITY AS o(product_id, position)
+		WHERE f.customer_id = $1 AND f.product_id = o.product_id
+	`, customerID, pq.Array(productIDs))
+	if err != nil {
+		return false, err
+	}
+
+	// Repeated IDs update a favorite once, leaving another one out.
+	updated, err := result.RowsAffected()
+	return err == nil && int(updated) == len(productIDs), err
+}
+
+// FindByCustomerID returns the favorites of the customer by position, only
+// the ones tagged with tag when it is not empty.
+func (r *FavoriteRepository) FindByCustomerID(c context.Context, customerID string, tag string) ([]model.CustomerFavorite, error) {
+	return findCustomerFavorites(c, conn(c, r.db), customerID, tag)
+}
+
+func findCustomerFavorites(c context.Context, q DBTX, customerID string, tag string) ([]model.CustomerFavorite, error) {
+	rows, err := q.QueryContext(c, `
+		SELECT `+favoriteColumns+`
+		FROM customers_favorite_products
+		WHERE customer_id = $1 AND ($2 = '' OR $2 = ANY (tags))
+		ORDER BY position, product_id
+	`, customerID, tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	favorites := []model.CustomerFavorite{}
+	for rows.Next() {
+		favorite, err := scanFavorite(rows)
+		if err != nil {
+			return nil, err
+		}
+		favorites = append(favorites, *favorite)
+	}
+
+	return favorites, rows.Err()
+}
+
+// FindChanges returns the favorites of the customer added or changed after
+// sinceID and the products removed after it, with the sync ID covering them.
+// Without sinceID every favorite is returned. It returns
+// domain.ErrSyncExpired when tombstones after sinceID were already removed.
+func (r *FavoriteRepository) FindChanges(c context.Context, customerID string, sinceID *int64) (*model.FavoriteChanges, error) {
+	tx, err := r.db.BeginTx(c, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Waiting for changes of the customer being committed makes every sync
+	// ID up to the sequence value visible to the queries below.
+	if _, err := tx.ExecContext(c, "SELECT pg_advisory_xact_lock_shared(hashtextextended($1, 0))", customerID); err != nil {
+		return nil, err
+	}
+
+	changes := model.FavoriteChanges{Added: []model.CustomerFavorite{}, Removed: []model.RemovedFavorite{}}
+	if err := tx.QueryRowContext(c,
+		"SELECT COALESCE(pg_sequence_last_value('favorite_sync_seq'), 0)",
+	).Scan(&changes.SyncID); err != nil {
+		return nil, err
+	}
+
+	since := int64(0)
+	if sinceID != nil {
+		var purgedID int64
+		if err := tx.QueryRowContext(c, "SELECT purged_sync_id FROM favorite_sync_horizon").Scan(&purgedID); err != nil {
+			return nil, err
+		}
+		if err := checkSyncHorizon(*sinceID, purgedID); err != nil {
+			return nil, err
+		}
+		since = *sinceID
+	}
+
+	rows, err := tx.QueryContext(c, `
+		SELECT `+favoriteColumns+`
+		FROM customers_favorite_products
+		WHERE customer_id = $1 AND sync_id > $2
+		ORDER BY sync_id
+	`, customerID, since)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	for rows.Next() {
+		favorite, err := scanFavorite(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		changes.Added = append(changes.Added, *favorite)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// A full sync has nothing to remove.
+	if sinceID != nil {
+		if changes.Removed, err = findFavoriteTombstones(c, tx, customerID, since); err != nil {
+			return nil, err
+		}
+	}
+
+	return &changes, tx.Commit()
+}
+
+// DeleteTombstones removes tombstones older than retention and moves the
+// sync horizon past them.
+func (r *FavoriteRepository) DeleteTombstones(c context.Context, retention time.Duration) (int64, error) {
+	var deleted int64
+	err := r.db.QueryRowContext(c, `
+		WITH purged AS (
+			DELETE FROM favorite_tombstones
+			WHERE removed_at < now() - make_interval(secs => $1)
+			RETURNING sync_id
+		), horizon AS (
+			UPDATE favorite_sync_horizon
+			SET purged_sync_id = GREATEST(purged_sync_id, (SELECT COALESCE(max(sync_id), 0) FROM purged))
+		)
+		SELECT count(*) FROM purged
+	`, retention.Seconds()).Scan(&deleted)
+	return deleted, err
+}
+
+// checkSyncHorizon returns domain.ErrSyncExpired when tombstones after sinceID
+// may have been purged. purgedID is the last sync ID purged, so a client that
+// synced up to it has seen every purged removal.
+func checkSyncHorizon(sinceID int64, purgedID int64) error {
+	if sinceID < purgedID {
+		return domain.ErrSyncExpired
 	}
 	return nil
 }
 
+func findFavoriteTombstones(c context.Context, q DBTX, customerID string, sinceID int64) ([]model.RemovedFavorite, error) {
+	rows, err := q.QueryContext(c, `
+		SELECT product_id, removed_at
+		FROM favorite_tombstones
+		WHERE customer_id = $1 AND sync_id > $2
+		ORDER BY sync_id
+	`, customerID, sinceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	removed := []model.RemovedFavorite{}
+	for rows.Next() {
+		var favorite model.RemovedFavorite
+		if err := rows.Scan(&favorite.ProductID, &favorite.RemovedAt); err != nil {
+			return nil, err
+		}
+		removed = append(removed, favorite)
+	}
+
+	return removed, rows.Err()
+}
+
 func (r *FavoriteRepository) FindProductsByCustomerID(c context.Context, customerID string) ([]int, error) {
 	query := `
 		SELECT product_id
 		FROM customers_favorite_products
 		WHERE customer_id = $1
+		ORDER BY position, product_id
 	`
-	rows, err := r.db.QueryContext(c, query, customerID)
+	rows, err := conn(c, r.db).QueryContext(c, query, customerID)
 	if err != nil {
 		return nil, err
 	}
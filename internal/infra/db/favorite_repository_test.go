@@ -0,0 +1,29 @@
+package db
+
+import (
+	"app/internal/domain"
+	"testing"
+)
+
+func TestCheckSyncHorizon(t *testing.T) {
+	tests := []struct {
+		name     string
+		sinceID  int64
+		purgedID int64
+		want     error
+	}{
+		{name: "nothing purged", sinceID: 0, purgedID: 0},
+		{name: "after the horizon", sinceID: 10, purgedID: 5},
+		{name: "at the horizon", sinceID: 5, purgedID: 5},
+		{name: "behind the horizon", sinceID: 4, purgedID: 5, want: domain.ErrSyncExpired},
+		{name: "token from an empty sequence behind the horizon", sinceID: 0, purgedID: 1, want: domain.ErrSyncExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkSyncHorizon(tt.sinceID, tt.purgedID); err != tt.want {
+				t.Errorf("checkSyncHorizon(%d, %d) = %v, want %v", tt.sinceID, tt.purgedID, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		db: db,
+	}
+}
+
+// Create reserves the key. It returns false when an unexpired record for the
+// same scope and key already exists.
+func (r *IdempotencyRepository) Create(c context.Context, key model.IdempotencyKey) (bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (scope, key, fingerprint, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (scope, key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+			response_status = NULL,
+			response_headers = NULL,
+			response_body = NULL,
+			created_at = now(),
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= now()
+	`
+	result, err := r.db.ExecContext(c, query, key.Scope, key.Key, key.Fingerprint, key.ExpiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *IdempotencyRepository) Find(c context.Context, scope string, key string) (*model.IdempotencyKey, error) {
+	query := `
+		SELECT scope, key, fingerprint, response_status, response_headers, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND key = $2 AND expires_at > now()
+	`
+
+	row := r.db.QueryRowContext(c, query, scope, key)
+
+	var (
+		record  model.IdempotencyKey
+		status  sql.NullInt64
+		headers []byte
+	)
+	if err := row.Scan(
+		&record.Scope,
+		&record.Key,
+		&record.Fingerprint,
+		&status,
+		&headers,
+		&record.ResponseBody,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	record.ResponseStatus = int(status.Int64)
+	if headers != nil {
+		if err := json.Unmarshal(headers, &record.ResponseHeaders); err != nil {
+			return nil, err
+		}
+	}
+
+	return &record, nil
+}
+
+func (r *IdempotencyRepository) Complete(c context.Context, key model.IdempotencyKey) error {
+	headers, err := json.Marshal(key.ResponseHeaders)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE idempotency_keys
+		SET response_status = $1, response_headers = $2, response_body = $3
+		WHERE scope = $4 AND key = $5
+	`
+	_, err = r.db.ExecContext(c, query, key.ResponseStatus, headers, key.ResponseBody, key.Scope, key.Key)
+	return err
+}
+
+func (r *IdempotencyRepository) Delete(c context.Context, scope string, key string) error {
+	query := "DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2"
+
+	_, err := r.db.ExecContext(c, query, scope, key)
+	return err
+}
+
+func (r *IdempotencyRepository) DeleteExpired(c context.Context) (int64, error) {
+	query := "DELETE FROM idempotency_keys WHERE expires_at <= now()"
+
+	result, err := r.db.ExecContext(c, query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID serializes migrations between replicas starting together.
+const migrationLockID = 72_310_001
+
+// Migrate applies, in order, every embedded migration not yet recorded in
+// schema_migrations. db/init.sql remains the baseline schema.
+func Migrate(c context.Context, db *sql.DB) error {
+	conn, err := db.Conn(c)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(c, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	if _, err := conn.ExecContext(c, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL DEFAULT now()
+		)
+	`); err != nil {
+		return err
+	}
+
+	applied, err := appliedMigrations(c, conn)
+	if err != nil {
+		return err
+	}
+
+	versions, err := migrationVersions()
+	if err != nil {
+		return err
+	}
+
+	for _, version := range versions {
+		if applied[version] {
+			continue
+		}
+
+		content, err := migrationFiles.ReadFile("migrations/" + version + ".sql")
+		if err != nil {
+			return err
+		}
+
+		tx, err := conn.BeginTx(c, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(c, string(content)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := tx.ExecContext(c, "INSERT INTO schema_migrations (version) VALUES ($1)", version); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+
+		log.Println("Applied migration", version)
+	}
+
+	return nil
+}
+
+// PendingMigrations returns the embedded migrations not applied to db.
+func PendingMigrations(c context.Context, db *sql.DB) ([]string, error) {
+	conn, err := db.Conn(c)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	applied, err := appliedMigrations(c, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := migrationVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, version := range versions {
+		if !applied[version] {
+			pending = append(pending, version)
+		}
+	}
+
+	return pending, nil
+}
+
+func appliedMigrations(c context.Context, conn *sql.Conn) (map[string]bool, error) {
+	rows, err := conn.QueryContext(c, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+
+	return applied, rows.Err()
+}
+
+func migrationVersions() ([]string, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, entry := range entries {
+		versions = append(versions, strings.TrimSuffix(entry.Name(), ".sql"))
+	}
+	sort.Strings(versions)
+
+	return versions, nil
+}
+
+// CheckMigrations reports an error when migrations are pending.
+func CheckMigrations(c context.Context, db *sql.DB) error {
+	pending, err := PendingMigrations(c, db)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- Emails only need to be unique among customers that are not deleted.
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS customers_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS customers_email_active_idx ON customers (email) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS customers_deleted_at_idx ON customers (deleted_at) WHERE deleted_at IS NOT NULL;
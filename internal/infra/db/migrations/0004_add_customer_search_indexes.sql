@@ -0,0 +1,26 @@
+-- Trigram search needs the pg_trgm and unaccent extensions. When they cannot
+-- be installed, customer search falls back to ranking in memory.
+DO $$
+BEGIN
+    CREATE EXTENSION IF NOT EXISTS pg_trgm;
+    CREATE EXTENSION IF NOT EXISTS unaccent;
+EXCEPTION WHEN insufficient_privilege OR undefined_file OR feature_not_supported THEN
+    RAISE NOTICE 'pg_trgm or unaccent unavailable: %', SQLERRM;
+END $$;
+
+DO $$
+BEGIN
+    IF (SELECT count(*) FROM pg_extension WHERE extname IN ('pg_trgm', 'unaccent')) = 2 THEN
+        -- unaccent is only STABLE, so it is wrapped to be usable in indexes.
+        EXECUTE $fn$
+            CREATE OR REPLACE FUNCTION search_normalize(input TEXT) RETURNS TEXT AS
+            'SELECT lower(public.unaccent(''public.unaccent'', input))'
+            LANGUAGE SQL IMMUTABLE PARALLEL SAFE STRICT
+        $fn$;
+
+        EXECUTE 'CREATE INDEX IF NOT EXISTS customers_name_trgm_idx ON customers
+            USING gin (search_normalize(name) gin_trgm_ops) WHERE deleted_at IS NULL';
+        EXECUTE 'CREATE INDEX IF NOT EXISTS customers_email_trgm_idx ON customers
+            USING gin (search_normalize(email) gin_trgm_ops) WHERE deleted_at IS NULL';
+    END IF;
+END $$;
@@ -0,0 +1,34 @@
+DROP INDEX IF EXISTS customers_email_active_idx;
+
+-- Emails are stored trimmed with a lowercase domain, and compared ignoring
+-- case. The local part keeps its case as typed. As in domain.NormalizeEmail,
+-- the domain is what follows the last @.
+UPDATE customers
+SET email = substring(trim(email) from '^(.*)@') || '@' || lower(substring(trim(email) from '@([^@]*)$'))
+WHERE email LIKE '%@%'
+    AND email <> substring(trim(email) from '^(.*)@') || '@' || lower(substring(trim(email) from '@([^@]*)$'));
+
+-- The unique index cannot be built while collisions exist. They are reported
+-- in the error, as the migration is rolled back so they can be merged,
+-- renamed or deleted before starting again.
+DO $$
+DECLARE
+    report TEXT;
+BEGIN
+    SELECT string_agg(normalized_email || ': ' || customer_ids, E'\n' ORDER BY normalized_email)
+    INTO report
+    FROM (
+        SELECT lower(email) AS normalized_email,
+            string_agg(id::text, ', ' ORDER BY created_at) AS customer_ids
+        FROM customers
+        WHERE deleted_at IS NULL
+        GROUP BY lower(email)
+        HAVING count(*) > 1
+    ) collisions;
+
+    IF report IS NOT NULL THEN
+        RAISE EXCEPTION E'active customers share an email ignoring case, resolve them first:\n%', report;
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS customers_email_lower_idx ON customers (lower(email)) WHERE deleted_at IS NULL;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS customer_imports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    format VARCHAR(10) NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT false,
+    payload BYTEA,
+    created_by UUID NOT NULL,
+    total_rows INT NOT NULL DEFAULT 0,
+    processed_rows INT NOT NULL DEFAULT 0,
+    created_rows INT NOT NULL DEFAULT 0,
+    failed_rows INT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    started_at TIMESTAMP,
+    heartbeat_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS customer_imports_pending_idx ON customer_imports (created_at) WHERE status IN ('pending', 'running');
+
+CREATE TABLE IF NOT EXISTS customer_import_errors (
+    id BIGSERIAL PRIMARY KEY,
+    import_id UUID NOT NULL REFERENCES customer_imports(id) ON DELETE CASCADE,
+    row_number INT NOT NULL,
+    field VARCHAR(50),
+    message TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS customer_import_errors_import_id_idx ON customer_import_errors (import_id, row_number);
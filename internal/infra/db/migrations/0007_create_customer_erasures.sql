@@ -0,0 +1,16 @@
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS erased_at TIMESTAMP;
+
+-- Receipts have no foreign key so they outlive whatever happens to the
+-- customer row. Each receipt hash covers the previous one, forming a chain.
+CREATE TABLE IF NOT EXISTS customer_erasure_receipts (
+    seq BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    customer_id UUID NOT NULL,
+    erased_by UUID NOT NULL,
+    erased_at TIMESTAMP NOT NULL,
+    actions JSONB NOT NULL,
+    previous_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS customer_erasure_receipts_customer_id_idx ON customer_erasure_receipts (customer_id);
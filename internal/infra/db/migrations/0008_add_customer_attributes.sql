@@ -0,0 +1,13 @@
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+-- Supports the containment (@>) filters of customer listings.
+CREATE INDEX IF NOT EXISTS customers_attributes_idx ON customers USING GIN (attributes jsonb_path_ops);
+
+CREATE TABLE IF NOT EXISTS customer_attribute_definitions (
+    name VARCHAR(64) PRIMARY KEY,
+    type VARCHAR(20) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT false,
+    enum JSONB,
+    description TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS customer_tags (
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (customer_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS customer_tags_tag_idx ON customer_tags (tag);
+
+CREATE TABLE IF NOT EXISTS customer_segments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    expression TEXT NOT NULL,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
@@ -0,0 +1,16 @@
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS state VARCHAR(20) NOT NULL DEFAULT 'active'
+    CHECK (state IN ('pending', 'active', 'suspended', 'closed'));
+
+CREATE INDEX IF NOT EXISTS customers_state_idx ON customers (state) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS customer_state_transitions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    from_state VARCHAR(20) NOT NULL,
+    to_state VARCHAR(20) NOT NULL,
+    reason TEXT,
+    actor UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS customer_state_transitions_customer_idx ON customer_state_transitions (customer_id, created_at);
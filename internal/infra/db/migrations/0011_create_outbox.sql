@@ -0,0 +1,22 @@
+-- Events are written in the transaction of the change they describe and
+-- delivered later by the dispatcher. They do not reference customers, so
+-- they outlive purged customers until the retention removes them. An event
+-- being published is claimed until claimed_until, so the sinks are called
+-- outside the claiming transaction.
+CREATE TABLE IF NOT EXISTS outbox (
+    seq BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    type VARCHAR(100) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT now(),
+    claimed_until TIMESTAMP,
+    last_error TEXT,
+    dispatched_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (next_attempt_at, seq) WHERE dispatched_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_aggregate_idx ON outbox (aggregate_id);
+CREATE INDEX IF NOT EXISTS outbox_dispatched_idx ON outbox (dispatched_at) WHERE dispatched_at IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Recent favorite changes, replayed to streams resuming from Last-Event-ID.
+-- Rows are removed after the stream history retention.
+CREATE TABLE IF NOT EXISTS favorite_changes (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    product_id INT NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS favorite_changes_customer_idx ON favorite_changes (customer_id, id);
+CREATE INDEX IF NOT EXISTS favorite_changes_changed_at_idx ON favorite_changes (changed_at);
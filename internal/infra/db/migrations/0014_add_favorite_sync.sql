@@ -0,0 +1,30 @@
+-- Every addition and removal of a favorite takes the next sync ID, so
+-- clients can ask for what changed after the last ID they synced.
+CREATE SEQUENCE IF NOT EXISTS favorite_sync_seq;
+
+ALTER TABLE customers_favorite_products
+    ADD COLUMN IF NOT EXISTS sync_id BIGINT NOT NULL DEFAULT nextval('favorite_sync_seq');
+
+CREATE INDEX IF NOT EXISTS customers_favorite_products_sync_idx ON customers_favorite_products (customer_id, sync_id);
+
+-- Removed favorites are kept as tombstones until the retention removes them.
+-- Adding the product again removes its tombstone.
+CREATE TABLE IF NOT EXISTS favorite_tombstones (
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    product_id INT NOT NULL,
+    sync_id BIGINT NOT NULL DEFAULT nextval('favorite_sync_seq'),
+    removed_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (customer_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS favorite_tombstones_sync_idx ON favorite_tombstones (customer_id, sync_id);
+CREATE INDEX IF NOT EXISTS favorite_tombstones_removed_at_idx ON favorite_tombstones (removed_at);
+
+-- The highest sync ID of a removed tombstone. Changes after an older sync
+-- ID may have been lost.
+CREATE TABLE IF NOT EXISTS favorite_sync_horizon (
+    id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+    purged_sync_id BIGINT NOT NULL
+);
+
+INSERT INTO favorite_sync_horizon (purged_sync_id) VALUES (0) ON CONFLICT DO NOTHING;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS favorite_collections (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    public BOOLEAN NOT NULL DEFAULT false,
+    position INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS favorite_collections_name_idx ON favorite_collections (customer_id, lower(name));
+
+-- Items reference the favorite, so removing a favorite removes it from every
+-- collection of the customer.
+CREATE TABLE IF NOT EXISTS favorite_collection_items (
+    collection_id UUID NOT NULL REFERENCES favorite_collections(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL,
+    product_id INT NOT NULL,
+    added_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (collection_id, product_id),
+    CONSTRAINT favorite_collection_items_favorite_fkey FOREIGN KEY (customer_id, product_id)
+        REFERENCES customers_favorite_products (customer_id, product_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS favorite_collection_items_favorite_idx ON favorite_collection_items (customer_id, product_id);
@@ -0,0 +1,16 @@
+-- Links to view the favorites of a customer, or one of its collections,
+-- without credentials. Only the SHA-256 of the token is stored.
+CREATE TABLE IF NOT EXISTS share_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    collection_id UUID REFERENCES favorite_collections(id) ON DELETE CASCADE,
+    token_hash BYTEA NOT NULL UNIQUE,
+    created_by UUID NOT NULL,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    views BIGINT NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS share_links_customer_idx ON share_links (customer_id, created_at);
@@ -0,0 +1,17 @@
+-- Customers can note why they saved a product, tag it and rank it with a
+-- priority. Position orders the favorites of each customer from 0.
+ALTER TABLE customers_favorite_products
+    ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS priority INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+
+UPDATE customers_favorite_products f
+SET position = o.position
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY customer_id ORDER BY created_at, product_id) - 1 AS position
+    FROM customers_favorite_products
+) o
+WHERE f.id = o.id;
+
+CREATE INDEX IF NOT EXISTS customers_favorite_products_position_idx ON customers_favorite_products (customer_id, position);
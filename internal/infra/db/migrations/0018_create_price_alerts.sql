@@ -0,0 +1,37 @@
+-- Prices read from the catalog, recorded only when they change.
+CREATE TABLE IF NOT EXISTS product_prices (
+    product_id INT NOT NULL,
+    price NUMERIC NOT NULL,
+    recorded_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (product_id, recorded_at)
+);
+
+-- Customers without settings get no alerts.
+CREATE TABLE IF NOT EXISTS price_alert_settings (
+    customer_id UUID PRIMARY KEY REFERENCES customers(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL,
+    threshold_type VARCHAR(20) NOT NULL CHECK (threshold_type IN ('percent', 'absolute')),
+    threshold NUMERIC NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- An alert compares the price of a favorite with the highest price since it
+-- was favorited or since its last alert. Alerts are claimed until
+-- claimed_until and notified outside the claiming transaction. notified_by
+-- lists the notifiers that accepted the alert, so a retry skips them.
+CREATE TABLE IF NOT EXISTS price_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    product_id INT NOT NULL,
+    reference_price NUMERIC NOT NULL,
+    price NUMERIC NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    notified_at TIMESTAMP,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    notified_by TEXT[] NOT NULL DEFAULT '{}',
+    claimed_until TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS price_alerts_customer_idx ON price_alerts (customer_id, product_id, created_at);
+CREATE INDEX IF NOT EXISTS price_alerts_pending_idx ON price_alerts (id) WHERE notified_at IS NULL;
@@ -0,0 +1,64 @@
+-- Every addition and removal of a favorite, without the customer, so the
+-- history outlives erased customers and expired tombstones.
+CREATE TABLE IF NOT EXISTS favorite_activity (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    occurred_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS favorite_activity_occurred_at_idx ON favorite_activity (occurred_at);
+
+-- Favorites removed before this migration are only known from their
+-- tombstones, and favorites added again lost their previous removal.
+INSERT INTO favorite_activity (product_id, type, occurred_at)
+SELECT product_id, 'added', created_at FROM customers_favorite_products
+WHERE NOT EXISTS (SELECT 1 FROM favorite_activity)
+UNION ALL
+SELECT product_id, 'removed', removed_at FROM favorite_tombstones
+WHERE NOT EXISTS (SELECT 1 FROM favorite_activity);
+
+-- The views are refreshed periodically; the unique indexes allow refreshing
+-- them concurrently with reads.
+CREATE MATERIALIZED VIEW IF NOT EXISTS favorite_daily_activity AS
+SELECT
+    occurred_at::date AS day,
+    product_id,
+    count(*) FILTER (WHERE type = 'added') AS added,
+    count(*) FILTER (WHERE type = 'removed') AS removed
+FROM favorite_activity
+GROUP BY 1, 2;
+
+CREATE UNIQUE INDEX IF NOT EXISTS favorite_daily_activity_idx ON favorite_daily_activity (day, product_id);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS favorite_product_counts AS
+SELECT product_id, count(*) AS favorites
+FROM customers_favorite_products
+GROUP BY product_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS favorite_product_counts_idx ON favorite_product_counts (product_id);
+CREATE INDEX IF NOT EXISTS favorite_product_counts_favorites_idx ON favorite_product_counts (favorites DESC, product_id);
+
+-- A single row counting the customers not deleted and their favorites.
+CREATE MATERIALIZED VIEW IF NOT EXISTS favorite_summary AS
+SELECT
+    true AS id,
+    count(*) AS customers,
+    count(*) FILTER (WHERE f.favorites > 0) AS customers_with_favorites,
+    COALESCE(sum(f.favorites), 0) AS favorites
+FROM customers cu
+CROSS JOIN LATERAL (
+    SELECT count(*) AS favorites
+    FROM customers_favorite_products p
+    WHERE p.customer_id = cu.id
+) f
+WHERE cu.deleted_at IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS favorite_summary_idx ON favorite_summary (id);
+
+CREATE TABLE IF NOT EXISTS favorite_analytics_refresh (
+    id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+    refreshed_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO favorite_analytics_refresh (refreshed_at) VALUES (now()) ON CONFLICT DO NOTHING;
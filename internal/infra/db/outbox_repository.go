@@ -0,0 +1,120 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+// Add stores the event. Called with a context carrying a transaction, the
+// event is only stored if the transaction commits.
+func (r *OutboxRepository) Add(c context.Context, event model.DomainEvent) error {
+	return insertOutboxEvent(c, conn(c, r.db), event)
+}
+
+func insertOutboxEvent(c context.Context, db DBTX, event model.DomainEvent) error {
+	_, err := db.ExecContext(c, `
+		INSERT INTO outbox (id, type, aggregate_id, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, event.ID, event.Type, event.AggregateID, []byte(event.Payload), event.OccurredAt)
+	return err
+}
+
+// ClaimDue claims for claimTimeout up to limit undispatched events whose
+// next attempt is due, oldest first, and returns them. Events claimed by
+// another dispatcher are skipped. The claim is committed right away, so the
+// events are published outside any transaction.
+func (r *OutboxRepository) ClaimDue(c context.Context, limit int, claimTimeout time.Duration) ([]model.OutboxEvent, error) {
+	rows, err := conn(c, r.db).QueryContext(c, `
+		WITH claimed AS (
+			UPDATE outbox
+			SET claimed_until = now() + make_interval(secs => $2)
+			WHERE seq IN (
+				SELECT seq
+				FROM outbox
+				WHERE dispatched_at IS NULL
+					AND next_attempt_at <= now()
+					AND (claimed_until IS NULL OR claimed_until <= now())
+				ORDER BY seq
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING seq, id, type, aggregate_id, payload, occurred_at, attempts
+		)
+		SELECT id, type, aggregate_id, payload, occurred_at, attempts
+		FROM claimed
+		ORDER BY seq
+	`, limit, claimTimeout.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []model.OutboxEvent
+	for rows.Next() {
+		var (
+			event   model.OutboxEvent
+			payload []byte
+		)
+		if err := rows.Scan(
+			&event.ID,
+			&event.Type,
+			&event.AggregateID,
+			&payload,
+			&event.OccurredAt,
+			&event.Attempts,
+		); err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *OutboxRepository) MarkDispatched(c context.Context, id string) error {
+	_, err := conn(c, r.db).ExecContext(c, `
+		UPDATE outbox
+		SET dispatched_at = now(), attempts = attempts + 1, last_error = NULL, claimed_until = NULL
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+// MarkFailed records a failed delivery, releases the claim on the event and
+// schedules the next attempt after retryAfter.
+func (r *OutboxRepository) MarkFailed(c context.Context, id string, deliveryErr string, retryAfter time.Duration) error {
+	_, err := conn(c, r.db).ExecContext(c, `
+		UPDATE outbox
+		SET attempts = attempts + 1,
+			last_error = $2,
+			next_attempt_at = now() + make_interval(secs => $3),
+			claimed_until = NULL
+		WHERE id = $1
+	`, id, deliveryErr, retryAfter.Seconds())
+	return err
+}
+
+// DeleteDispatched removes events dispatched longer than retention ago.
+func (r *OutboxRepository) DeleteDispatched(c context.Context, retention time.Duration) (int64, error) {
+	result, err := r.db.ExecContext(c,
+		"DELETE FROM outbox WHERE dispatched_at < now() - make_interval(secs => $1)",
+		retention.Seconds(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,253 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const priceAlertColumns = "id, customer_id, product_id, reference_price, price, created_at, notified_at, attempts, notified_by"
+
+type PriceAlertRepository struct {
+	db *sql.DB
+}
+
+func NewPriceAlertRepository(db *sql.DB) *PriceAlertRepository {
+	return &PriceAlertRepository{
+		db: db,
+	}
+}
+
+func scanPriceAlert(row rowScanner) (*model.PriceAlert, error) {
+	var alert model.PriceAlert
+	if err := row.Scan(
+		&alert.ID,
+		&alert.CustomerID,
+		&alert.ProductID,
+		&alert.ReferencePrice,
+		&alert.Price,
+		&alert.CreatedAt,
+		&alert.NotifiedAt,
+		&alert.Attempts,
+		pq.Array(&alert.NotifiedBy),
+	); err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// FindSettings returns nil when the customer never set up price alerts.
+func (r *PriceAlertRepository) FindSettings(c context.Context, customerID string) (*model.PriceAlertSettings, error) {
+	return findPriceAlertSettings(c, r.db, customerID)
+}
+
+func (r *PriceAlertRepository) SaveSettings(c context.Context, settings model.PriceAlertSettings) (*model.PriceAlertSettings, error) {
+	var saved model.PriceAlertSettings
+	err := r.db.QueryRowContext(c, `
+		INSERT INTO price_alert_settings (customer_id, enabled, threshold_type, threshold)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (customer_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled,
+			threshold_type = EXCLUDED.threshold_type,
+			threshold = EXCLUDED.threshold,
+			updated_at = now()
+		RETURNING customer_id, enabled, threshold_type, threshold, updated_at
+	`, settings.CustomerID, settings.Enabled, settings.ThresholdType, settings.Threshold).Scan(
+		&saved.CustomerID,
+		&saved.Enabled,
+		&saved.ThresholdType,
+		&saved.Threshold,
+		&saved.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// CreateAlerts alerts the customers with alerts enabled who favorited the
+// products of prices, when the new price dropped past their threshold from
+// the highest price since the product was favorited or last alerted. Meant
+// to run in the transaction that recorded prices.
+func (r *PriceAlertRepository) CreateAlerts(c context.Context, prices []model.ProductPrice) (int64, error) {
+	ids := make([]int64, 0, len(prices))
+	values := make([]float64, 0, len(prices))
+	for _, price := range prices {
+		ids = append(ids, int64(price.ProductID))
+		values = append(values, price.Price)
+	}
+
+	// The price recorded at the start of the window is part of the window,
+	// as it was the price until the next change.
+	result, err := conn(c, r.db).ExecContext(c, `
+		INSERT INTO price_alerts (customer_id, product_id, reference_price, price)
+		SELECT f.customer_id, f.product_id, w.reference_price, p.price
+		FROM unnest($1::int[], $2::numeric[]) AS p(product_id, price)
+		JOIN customers_favorite_products f ON f.product_id = p.product_id
+		JOIN customers cu ON cu.id = f.customer_id
+			AND cu.deleted_at IS NULL
+			AND cu.state IN ('pending', 'active')
+		JOIN price_alert_settings s ON s.customer_id = f.customer_id AND s.enabled
+		CROSS JOIN LATERAL (
+			SELECT GREATEST(f.created_at, (
+				SELECT max(a.created_at)
+				FROM price_alerts a
+				WHERE a.customer_id = f.customer_id AND a.product_id = f.product_id
+			)) AS since
+		) x
+		CROSS JOIN LATERAL (
+			SELECT GREATEST(
+				(
+					SELECT h.price
+					FROM product_prices h
+					WHERE h.product_id = f.product_id AND h.recorded_at <= x.since
+					ORDER BY h.recorded_at DESC
+					LIMIT 1
+				),
+				(
+					SELECT max(h.price)
+					FROM product_prices h
+					WHERE h.product_id = f.product_id AND h.recorded_at > x.since
+				)
+			) AS reference_price
+		) w
+		WHERE p.price < w.reference_price
+			AND CASE s.threshold_type
+				WHEN 'percent' THEN p.price <= w.reference_price * (1 - s.threshold / 100)
+				ELSE p.price <= w.reference_price - s.threshold
+			END
+	`, pq.Array(ids), pq.Array(values))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ClaimPending claims for claimTimeout up to limit alerts after afterID not
+// notified yet with fewer than maxAttempts failed notifications, oldest
+// first, and returns them. Alerts claimed by another notifier are skipped.
+// The claim is committed right away, so the alerts are notified outside any
+// transaction.
+func (r *PriceAlertRepository) ClaimPending(c context.Context, afterID int64, limit int, maxAttempts int, claimTimeout time.Duration) ([]model.PriceAlert, error) {
+	rows, err := conn(c, r.db).QueryContext(c, `
+		UPDATE price_alerts
+		SET claimed_until = now() + make_interval(secs => $4)
+		WHERE id IN (
+			SELECT id
+			FROM price_alerts
+			WHERE id > $1
+				AND notified_at IS NULL
+				AND attempts < $3
+				AND (claimed_until IS NULL OR claimed_until <= now())
+			ORDER BY id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+priceAlertColumns,
+		afterID, limit, maxAttempts, claimTimeout.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []model.PriceAlert
+	for rows.Next() {
+		alert, err := scanPriceAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, *alert)
+	}
+
+	// UPDATE ... RETURNING does not keep the order of the subquery.
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].ID < alerts[j].ID })
+
+	return alerts, rows.Err()
+}
+
+// MarkNotifiedBy records that the notifier accepted the alert.
+func (r *PriceAlertRepository) MarkNotifiedBy(c context.Context, id int64, notifier string) error {
+	_, err := conn(c, r.db).ExecContext(c, `
+		UPDATE price_alerts
+		SET notified_by = array_append(notified_by, $2)
+		WHERE id = $1 AND NOT $2 = ANY (notified_by)
+	`, id, notifier)
+	return err
+}
+
+// MarkNotified completes the alert once every notifier accepted it.
+func (r *PriceAlertRepository) MarkNotified(c context.Context, id int64) error {
+	_, err := conn(c, r.db).ExecContext(c, `
+		UPDATE price_alerts
+		SET notified_at = now(), attempts = attempts + 1, last_error = NULL, claimed_until = NULL
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+// MarkFailed releases the claim on the alert, so the notifiers that did not
+// accept it are called again on the next attempt.
+func (r *PriceAlertRepository) MarkFailed(c context.Context, id int64, notifyErr string) error {
+	_, err := conn(c, r.db).ExecContext(c, `
+		UPDATE price_alerts
+		SET attempts = attempts + 1, last_error = $2, claimed_until = NULL
+		WHERE id = $1
+	`, id, notifyErr)
+	return err
+}
+
+// FindByCustomerID returns the alerts of the customer, newest first.
+func (r *PriceAlertRepository) FindByCustomerID(c context.Context, customerID string) ([]model.PriceAlert, error) {
+	return findPriceAlerts(c, r.db, customerID)
+}
+
+func findPriceAlertSettings(c context.Context, q DBTX, customerID string) (*model.PriceAlertSettings, error) {
+	var settings model.PriceAlertSettings
+	err := q.QueryRowContext(c, `
+		SELECT customer_id, enabled, threshold_type, threshold, updated_at
+		FROM price_alert_settings
+		WHERE customer_id = $1
+	`, customerID).Scan(
+		&settings.CustomerID,
+		&settings.Enabled,
+		&settings.ThresholdType,
+		&settings.Threshold,
+		&settings.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func findPriceAlerts(c context.Context, q DBTX, customerID string) ([]model.PriceAlert, error) {
+	rows, err := q.QueryContext(c, `
+		SELECT `+priceAlertColumns+`
+		FROM price_alerts
+		WHERE customer_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []model.PriceAlert{}
+	for rows.Next() {
+		alert, err := scanPriceAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, *alert)
+	}
+
+	return alerts, rows.Err()
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// priceCheckLockID serializes price checks between replicas, so a change is
+// recorded once.
+const priceCheckLockID = 72_310_003
+
+type ProductPriceRepository struct {
+	db *sql.DB
+}
+
+func NewProductPriceRepository(db *sql.DB) *ProductPriceRepository {
+	return &ProductPriceRepository{
+		db: db,
+	}
+}
+
+// Record records the prices of the products that differ from their last
+// recorded price and returns them. Meant to run in a transaction, which
+// waits for other price checks to end.
+func (r *ProductPriceRepository) Record(c context.Context, products []model.Product) ([]model.ProductPrice, error) {
+	db := conn(c, r.db)
+
+	if _, err := db.ExecContext(c, "SELECT pg_advisory_xact_lock($1)", priceCheckLockID); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(products))
+	prices := make([]float64, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, int64(product.ID))
+		prices = append(prices, product.Price)
+	}
+
+	rows, err := db.QueryContext(c, `
+		INSERT INTO product_prices (product_id, price)
+		SELECT p.product_id, p.price
+		FROM unnest($1::int[], $2::numeric[]) AS p(product_id, price)
+		WHERE p.price IS DISTINCT FROM (
+			SELECT h.price
+			FROM product_prices h
+			WHERE h.product_id = p.product_id
+			ORDER BY h.recorded_at DESC
+			LIMIT 1
+		)
+		ON CONFLICT (product_id, recorded_at) DO NOTHING
+		RETURNING product_id, price, recorded_at
+	`, pq.Array(ids), pq.Array(prices))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanProductPrices(rows)
+}
+
+// FindByProductID returns the price changes of the product, oldest first.
+func (r *ProductPriceRepository) FindByProductID(c context.Context, productID int) ([]model.ProductPrice, error) {
+	rows, err := r.db.QueryContext(c, `
+		SELECT product_id, price, recorded_at
+		FROM product_prices
+		WHERE product_id = $1
+		ORDER BY recorded_at
+	`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanProductPrices(rows)
+}
+
+func scanProductPrices(rows *sql.Rows) ([]model.ProductPrice, error) {
+	prices := []model.ProductPrice{}
+	for rows.Next() {
+		var price model.ProductPrice
+		if err := rows.Scan(&price.ProductID, &price.Price, &price.RecordedAt); err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+
+	return prices, rows.Err()
+}
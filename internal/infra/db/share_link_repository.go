@@ -0,0 +1,126 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+)
+
+const shareLinkColumns = `
+	id, customer_id, COALESCE(collection_id::text, ''), created_by, expires_at,
+	revoked_at, views, last_viewed_at, created_at`
+
+type ShareLinkRepository struct {
+	db *sql.DB
+}
+
+func NewShareLinkRepository(db *sql.DB) *ShareLinkRepository {
+	return &ShareLinkRepository{
+		db: db,
+	}
+}
+
+func scanShareLink(row rowScanner) (*model.ShareLink, error) {
+	var link model.ShareLink
+	if err := row.Scan(
+		&link.ID,
+		&link.CustomerID,
+		&link.CollectionID,
+		&link.CreatedBy,
+		&link.ExpiresAt,
+		&link.RevokedAt,
+		&link.Views,
+		&link.LastViewedAt,
+		&link.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *ShareLinkRepository) Create(c context.Context, link model.ShareLink, tokenHash []byte) (*model.ShareLink, error) {
+	query := `
+		INSERT INTO share_links (id, customer_id, collection_id, token_hash, created_by, expires_at)
+		VALUES ($1, $2, NULLIF($3::text, '')::uuid, $4, $5, $6)
+		RETURNING ` + shareLinkColumns
+
+	return scanShareLink(r.db.QueryRowContext(c, query,
+		link.ID,
+		link.CustomerID,
+		link.CollectionID,
+		tokenHash,
+		link.CreatedBy,
+		link.ExpiresAt,
+	))
+}
+
+func (r *ShareLinkRepository) FindByCustomerID(c context.Context, customerID string) ([]model.ShareLink, error) {
+	return findShareLinks(c, r.db, customerID)
+}
+
+// FindActiveByTokenHash returns the link with the token unless it was revoked
+// or expired. It returns nil otherwise.
+func (r *ShareLinkRepository) FindActiveByTokenHash(c context.Context, tokenHash []byte) (*model.ShareLink, error) {
+	query := `
+		SELECT ` + shareLinkColumns + `
+		FROM share_links
+		WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > now())
+	`
+
+	link, err := scanShareLink(r.db.QueryRowContext(c, query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// Revoke disables the link for good. It returns false when the customer has
+// no link with the ID.
+func (r *ShareLinkRepository) Revoke(c context.Context, customerID string, id string) (bool, error) {
+	result, err := r.db.ExecContext(c,
+		"UPDATE share_links SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1 AND customer_id = $2",
+		id, customerID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	revoked, err := result.RowsAffected()
+	return revoked > 0, err
+}
+
+func (r *ShareLinkRepository) RecordView(c context.Context, id string) error {
+	_, err := r.db.ExecContext(c,
+		"UPDATE share_links SET views = views + 1, last_viewed_at = now() WHERE id = $1",
+		id,
+	)
+	return err
+}
+
+func findShareLinks(c context.Context, q DBTX, customerID string) ([]model.ShareLink, error) {
+	rows, err := q.QueryContext(c, `
+		SELECT `+shareLinkColumns+`
+		FROM share_links
+		WHERE customer_id = $1
+		ORDER BY created_at, id
+	`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []model.ShareLink{}
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *link)
+	}
+
+	return links, rows.Err()
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// DBTX is implemented by both *sql.DB and *sql.Tx.
+type DBTX interface {
+	ExecContext(c context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(c context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(c context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+// Transactor runs functions in a database transaction carried by their
+// context. Repositories called with that context take part in the
+// transaction.
+type Transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// Do runs fn in a transaction committed when fn returns nil and rolled back
+// otherwise. When c already carries a transaction fn joins it.
+func (t *Transactor) Do(c context.Context, fn func(c context.Context) error) error {
+	return inTx(c, t.db, fn)
+}
+
+func inTx(c context.Context, db *sql.DB, fn func(c context.Context) error) error {
+	if _, ok := c.Value(txKey{}).(*sql.Tx); ok {
+		return fn(c)
+	}
+
+	tx, err := db.BeginTx(c, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(c, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// conn returns the transaction carried by c, or db when there is none.
+func conn(c context.Context, db *sql.DB) DBTX {
+	if tx, ok := c.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
@@ -15,15 +15,15 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 
 func (r *UserRepository) Create(user model.User) (*model.User, error) {
 	query := `
-		INSERT INTO users (id, username, password)
-		VALUES ($1, $2, $3)
-		RETURNING id, username, password, created_at
+		INSERT INTO users (id, username, password, role)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, username, password, role, created_at
 	`
 
-	row := r.DB.QueryRow(query, user.ID, user.Username, user.Password)
+	row := r.DB.QueryRow(query, user.ID, user.Username, user.Password, user.Role)
 
 	var createdUser model.User
-	if err := row.Scan(&createdUser.ID, &createdUser.Username, &createdUser.Password, &createdUser.CreatedAt); err != nil {
+	if err := row.Scan(&createdUser.ID, &createdUser.Username, &createdUser.Password, &createdUser.Role, &createdUser.CreatedAt); err != nil {
 		return nil, err
 	}
 
@@ -32,7 +32,7 @@ func (r *UserRepository) Create(user model.User) (*model.User, error) {
 
 func (r *UserRepository) FindByUsername(username string) (*model.User, error) {
 	query := `
-		SELECT id, username, password, created_at
+		SELECT id, username, password, role, created_at
 		FROM users
 		WHERE username = $1
 	`
@@ -40,7 +40,7 @@ func (r *UserRepository) FindByUsername(username string) (*model.User, error) {
 	row := r.DB.QueryRow(query, username)
 
 	var user model.User
-	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Role, &user.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -50,3 +50,15 @@ func (r *UserRepository) FindByUsername(username string) (*model.User, error) {
 
 	return &user, nil
 }
+
+// SetRole changes the role of the user. It returns false when there is no
+// user with the username.
+func (r *UserRepository) SetRole(username string, role string) (bool, error) {
+	result, err := r.DB.Exec("UPDATE users SET role = $2 WHERE username = $1", username, role)
+	if err != nil {
+		return false, err
+	}
+
+	updated, err := result.RowsAffected()
+	return updated > 0, err
+}
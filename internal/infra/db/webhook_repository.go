@@ -0,0 +1,374 @@
+package db
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const webhookSubscriptionColumns = "id, url, event_types, created_by, created_at, updated_at"
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, status, attempts,
+	CASE WHEN status = 'pending' THEN next_attempt_at END, last_status_code, COALESCE(last_error, ''),
+	created_at, finished_at`
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{
+		db: db,
+	}
+}
+
+func scanWebhookSubscription(row rowScanner) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+	if err := row.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		pq.Array(&subscription.EventTypes),
+		&subscription.CreatedBy,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func scanWebhookDelivery(row rowScanner, extra ...any) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	dest := []any{
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&delivery.CreatedAt,
+		&delivery.FinishedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *WebhookRepository) Create(c context.Context, subscription model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	query := `
+		INSERT INTO webhook_subscriptions (id, url, event_types, secret, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + webhookSubscriptionColumns
+
+	created, err := scanWebhookSubscription(r.db.QueryRowContext(c, query,
+		subscription.ID,
+		subscription.URL,
+		pq.Array(subscription.EventTypes),
+		subscription.Secret,
+		subscription.CreatedBy,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	created.Secret = subscription.Secret
+	return created, nil
+}
+
+func (r *WebhookRepository) FindAll(c context.Context) ([]model.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(c, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []model.WebhookSubscription{}
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func (r *WebhookRepository) FindByID(c context.Context, id string) (*model.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE id = $1
+	`
+
+	subscription, err := scanWebhookSubscription(r.db.QueryRowContext(c, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// Update replaces the URL and event types of the subscription, and its
+// secret unless empty. It returns nil when the subscription does not exist.
+func (r *WebhookRepository) Update(c context.Context, subscription model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $2, event_types = $3, secret = COALESCE(NULLIF($4, ''), secret), updated_at = now()
+		WHERE id = $1
+		RETURNING ` + webhookSubscriptionColumns
+
+	updated, err := scanWebhookSubscription(r.db.QueryRowContext(c, query,
+		subscription.ID,
+		subscription.URL,
+		pq.Array(subscription.EventTypes),
+		subscription.Secret,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// Delete removes the subscription with its deliveries. It returns false when
+// the subscription does not exist.
+func (r *WebhookRepository) Delete(c context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(c, "DELETE FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+// Enqueue creates a pending delivery of the event for every subscription to
+// its type. Enqueueing an event again does nothing, so events dispatched more
+// than once are delivered once per subscription.
+func (r *WebhookRepository) Enqueue(c context.Context, event model.DomainEvent, payload []byte) error {
+	_, err := conn(c, r.db).ExecContext(c, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, aggregate_id, payload)
+		SELECT id, $1::uuid, $2::text, $3::uuid, $4::jsonb
+		FROM webhook_subscriptions
+		WHERE $2::text = ANY(event_types)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`, event.ID, event.Type, event.AggregateID, payload)
+	return err
+}
+
+// ClaimDue claims for claimTimeout up to limit pending deliveries whose next
+// attempt is due, the longest due first, and returns them. Deliveries claimed
+// by another worker are skipped. The claim is committed right away, so the
+// deliveries are sent outside any transaction.
+func (r *WebhookRepository) ClaimDue(c context.Context, limit int, claimTimeout time.Duration) ([]model.DueWebhookDelivery, error) {
+	rows, err := conn(c, r.db).QueryContext(c, `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET claimed_until = now() + make_interval(secs => $2)
+			WHERE id IN (
+				SELECT id
+				FROM webhook_deliveries
+				WHERE status = 'pending'
+					AND next_attempt_at <= now()
+					AND (claimed_until IS NULL OR claimed_until <= now())
+				ORDER BY next_attempt_at, created_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, subscription_id, event_id, event_type, payload, attempts, next_attempt_at, created_at
+		)
+		SELECT d.id, s.url, s.secret, d.event_id, d.event_type, d.payload, d.attempts
+		FROM claimed d
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		ORDER BY d.next_attempt_at, d.created_at
+	`, limit, claimTimeout.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []model.DueWebhookDelivery
+	for rows.Next() {
+		var delivery model.DueWebhookDelivery
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.URL,
+			&delivery.Secret,
+			&delivery.EventID,
+			&delivery.Type,
+			&delivery.Payload,
+			&delivery.Attempts,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RecordAttempt logs an attempt of the delivery, moves it to status and
+// releases its claim. A pending delivery is attempted again after retryAfter.
+func (r *WebhookRepository) RecordAttempt(
+	c context.Context,
+	deliveryID string,
+	attempt model.WebhookDeliveryAttempt,
+	status string,
+	retryAfter time.Duration,
+) error {
+	db := conn(c, r.db)
+
+	if _, err := db.ExecContext(c, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		ON CONFLICT (delivery_id, attempt) DO NOTHING
+	`, deliveryID, attempt.Attempt, attempt.StatusCode, attempt.Error, attempt.DurationMs); err != nil {
+		return err
+	}
+
+	_, err := db.ExecContext(c, `
+		UPDATE webhook_deliveries
+		SET attempts = $2,
+			status = $3::text,
+			last_status_code = $4,
+			last_error = NULLIF($5, ''),
+			next_attempt_at = now() + make_interval(secs => $6),
+			claimed_until = NULL,
+			finished_at = CASE WHEN $3::text = 'pending' THEN NULL ELSE now() END
+		WHERE id = $1
+	`, deliveryID, attempt.Attempt, status, attempt.StatusCode, attempt.Error, retryAfter.Seconds())
+	return err
+}
+
+// FindDeliveries returns one page of the deliveries of the subscription,
+// newest first, only those with status when it is not empty.
+func (r *WebhookRepository) FindDeliveries(
+	c context.Context,
+	subscriptionID string,
+	status string,
+	limit int,
+	offset int,
+) ([]model.WebhookDelivery, int, error) {
+	rows, err := r.db.QueryContext(c, `
+		SELECT `+webhookDeliveryColumns+`, count(*) OVER ()
+		FROM webhook_deliveries
+		WHERE subscription_id = $1 AND ($2::text = '' OR status = $2)
+		ORDER BY created_at DESC, id
+		LIMIT $3 OFFSET $4
+	`, subscriptionID, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries := []model.WebhookDelivery{}
+	total := 0
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	return deliveries, total, rows.Err()
+}
+
+// FindDelivery returns the delivery with its payload and attempts, or nil
+// when the subscription has no such delivery.
+func (r *WebhookRepository) FindDelivery(c context.Context, subscriptionID string, deliveryID string) (*model.WebhookDelivery, error) {
+	var payload []byte
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(c, `
+		SELECT `+webhookDeliveryColumns+`, payload
+		FROM webhook_deliveries
+		WHERE id = $1 AND subscription_id = $2
+	`, deliveryID, subscriptionID), &payload)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	delivery.Payload = payload
+
+	rows, err := r.db.QueryContext(c, `
+		SELECT attempt, status_code, COALESCE(error, ''), duration_ms, created_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY attempt
+	`, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	delivery.AttemptLog = []model.WebhookDeliveryAttempt{}
+	for rows.Next() {
+		var attempt model.WebhookDeliveryAttempt
+		if err := rows.Scan(
+			&attempt.Attempt,
+			&attempt.StatusCode,
+			&attempt.Error,
+			&attempt.DurationMs,
+			&attempt.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		delivery.AttemptLog = append(delivery.AttemptLog, attempt)
+	}
+
+	return delivery, rows.Err()
+}
+
+// Redeliver makes the delivery pending and due now, keeping its attempt log.
+// It returns nil when the subscription has no such delivery.
+func (r *WebhookRepository) Redeliver(c context.Context, subscriptionID string, deliveryID string) (*model.WebhookDelivery, error) {
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(c, `
+		UPDATE webhook_deliveries
+		SET status = 'pending', next_attempt_at = now(), finished_at = NULL
+		WHERE id = $1 AND subscription_id = $2
+		RETURNING `+webhookDeliveryColumns,
+		deliveryID, subscriptionID,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// DeleteFinished removes deliveries that succeeded or were dead-lettered
+// longer than retention ago.
+func (r *WebhookRepository) DeleteFinished(c context.Context, retention time.Duration) (int64, error) {
+	result, err := r.db.ExecContext(c,
+		"DELETE FROM webhook_deliveries WHERE finished_at < now() - make_interval(secs => $1)",
+		retention.Seconds(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,23 @@
+package events
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"log"
+)
+
+// LogSink writes events to the application log.
+type LogSink struct{}
+
+func NewLogSink() *LogSink {
+	return &LogSink{}
+}
+
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+func (s *LogSink) Publish(c context.Context, event model.DomainEvent) error {
+	log.Printf("Event %s %s aggregate=%s payload=%s", event.Type, event.ID, event.AggregateID, event.Payload)
+	return nil
+}
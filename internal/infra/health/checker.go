@@ -0,0 +1,137 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check is a named dependency check. It fails when Run returns an error or
+// does not finish within the checker timeout.
+type Check struct {
+	Name string
+	Run  func(c context.Context) error
+}
+
+type Result struct {
+	Name      string        `json:"name"`
+	Status    string        `json:"status"`
+	Latency   time.Duration `json:"-"`
+	Error     string        `json:"error,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+
+	cancelled bool
+}
+
+type Report struct {
+	Status    string    `json:"status"`
+	Checks    []Result  `json:"checks"`
+	CheckedAt time.Time `json:"checked_at"`
+	Cached    bool      `json:"cached"`
+}
+
+func (r Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+// Checker runs every check concurrently and caches the report for cacheTTL
+// so frequent probes do not hammer the dependencies. Checks do not run with
+// the context of the probe that triggered them, since their report is shared
+// with every later probe.
+type Checker struct {
+	checks   []Check
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu     sync.Mutex
+	cached *Report
+}
+
+func NewChecker(timeout time.Duration, cacheTTL time.Duration, checks ...Check) *Checker {
+	return &Checker{
+		checks:   checks,
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// Run returns the cached report, or runs the checks when it is older than
+// cacheTTL. Reports with checks cancelled before they finished are not
+// cached.
+func (c *Checker) Run() Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil && time.Since(c.cached.CheckedAt) < c.cacheTTL {
+		report := *c.cached
+		report.Cached = true
+		return report
+	}
+
+	report := Report{
+		Status:    StatusOK,
+		Checks:    make([]Result, len(c.checks)),
+		CheckedAt: time.Now(),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			report.Checks[i] = c.runCheck(check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	cancelled := false
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+		cancelled = cancelled || result.cancelled
+	}
+
+	if !cancelled {
+		c.cached = &report
+	}
+	return report
+}
+
+func (c *Checker) runCheck(check Check) Result {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- check.Run(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{
+		Name:      check.Name,
+		Status:    StatusOK,
+		Latency:   time.Since(start),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+		result.cancelled = errors.Is(err, context.Canceled)
+	}
+
+	return result
+}
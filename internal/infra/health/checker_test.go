@@ -0,0 +1,80 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckerCachesReports(t *testing.T) {
+	var runs atomic.Int32
+	checker := NewChecker(time.Second, time.Hour, Check{Name: "database", Run: func(c context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+
+	first := checker.Run()
+	second := checker.Run()
+
+	if !first.Healthy() || first.Cached {
+		t.Errorf("first report = %+v, want a healthy report not cached", first)
+	}
+	if !second.Healthy() || !second.Cached {
+		t.Errorf("second report = %+v, want a healthy cached report", second)
+	}
+	if n := runs.Load(); n != 1 {
+		t.Errorf("check ran %d times, want 1", n)
+	}
+}
+
+func TestCheckerFailsSlowChecks(t *testing.T) {
+	checker := NewChecker(10*time.Millisecond, time.Hour, Check{Name: "catalog", Run: func(c context.Context) error {
+		<-c.Done()
+		return c.Err()
+	}})
+
+	report := checker.Run()
+	if report.Healthy() {
+		t.Fatal("report is healthy, want a failure")
+	}
+	if report.Checks[0].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("error = %q, want %q", report.Checks[0].Error, context.DeadlineExceeded.Error())
+	}
+	if again := checker.Run(); !again.Cached {
+		t.Error("timed out report was not cached")
+	}
+}
+
+func TestCheckerDoesNotCacheCancelledChecks(t *testing.T) {
+	var runs atomic.Int32
+	checker := NewChecker(time.Second, time.Hour, Check{Name: "database", Run: func(c context.Context) error {
+		if runs.Add(1) == 1 {
+			return context.Canceled
+		}
+		return nil
+	}})
+
+	if report := checker.Run(); report.Healthy() {
+		t.Fatal("cancelled report is healthy, want a failure")
+	}
+
+	report := checker.Run()
+	if !report.Healthy() || report.Cached {
+		t.Errorf("report after cancellation = %+v, want a fresh healthy report", report)
+	}
+}
+
+func TestCheckerBoundsChecksByTimeout(t *testing.T) {
+	checker := NewChecker(time.Second, time.Hour, Check{Name: "database", Run: func(c context.Context) error {
+		if _, ok := c.Deadline(); !ok {
+			return errors.New("check has no deadline")
+		}
+		return nil
+	}})
+
+	if report := checker.Run(); !report.Healthy() {
+		t.Errorf("report = %+v, want healthy", report)
+	}
+}
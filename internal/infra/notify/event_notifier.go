@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"app/internal/domain/model"
+	"app/internal/infra/db"
+	"context"
+)
+
+// EventNotifier turns price alerts into favorite.price_dropped domain events,
+// delivered by the outbox to its sinks and to webhook subscriptions.
+type EventNotifier struct {
+	outboxRepo *db.OutboxRepository
+}
+
+func NewEventNotifier(outboxRepo *db.OutboxRepository) *EventNotifier {
+	return &EventNotifier{outboxRepo: outboxRepo}
+}
+
+func (n *EventNotifier) Name() string {
+	return "event"
+}
+
+// Notify records the event, in the transaction carried by c if any.
+func (n *EventNotifier) Notify(c context.Context, alert model.PriceAlert) error {
+	event, err := model.NewDomainEvent(model.EventFavoritePriceDropped, alert.CustomerID, model.PriceDroppedEventPayload{
+		CustomerID:     alert.CustomerID,
+		ProductID:      alert.ProductID,
+		ReferencePrice: alert.ReferencePrice,
+		Price:          alert.Price,
+	})
+	if err != nil {
+		return err
+	}
+	return n.outboxRepo.Add(c, event)
+}
@@ -0,0 +1,24 @@
+package notify
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"log"
+)
+
+// LogNotifier writes price alerts to the application log.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Name() string {
+	return "log"
+}
+
+func (n *LogNotifier) Notify(c context.Context, alert model.PriceAlert) error {
+	log.Printf("Price alert %d customer=%s product=%d price=%.2f reference=%.2f",
+		alert.ID, alert.CustomerID, alert.ProductID, alert.Price, alert.ReferencePrice)
+	return nil
+}
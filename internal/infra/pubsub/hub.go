@@ -0,0 +1,154 @@
+package pubsub
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// maxReconnectDelay is the longest wait between attempts to reconnect the
+// listener to the database.
+const maxReconnectDelay = time.Minute
+
+// Hub listens for notifications on a PostgreSQL channel and wakes the
+// subscribers of their payload. Every replica listens on its own, so changes
+// committed on any of them reach the subscribers of all.
+//
+// Wakeups carry no data: subscribers read what changed from the database,
+// and several notifications may be folded into one wakeup.
+type Hub struct {
+	listener *pq.Listener
+	channel  string
+
+	mu          sync.Mutex
+	subscribers map[string]map[chan struct{}]struct{}
+	closed      bool
+}
+
+func NewHub(dsn string, channel string, reconnectDelay time.Duration) *Hub {
+	return &Hub{
+		listener: pq.NewListener(dsn, reconnectDelay, maxReconnectDelay, func(event pq.ListenerEventType, err error) {
+			switch event {
+			case pq.ListenerEventDisconnected:
+				log.Println("Notification listener disconnected:", err)
+			case pq.ListenerEventReconnected:
+				log.Println("Notification listener reconnected")
+			case pq.ListenerEventConnectionAttemptFailed:
+				log.Println("Notification listener failed to connect:", err)
+			}
+		}),
+		channel:     channel,
+		subscribers: make(map[string]map[chan struct{}]struct{}),
+	}
+}
+
+// Subscribe returns a channel woken on every notification with key as the
+// payload, and a function ending the subscription. The channel is closed
+// when the hub stops.
+func (h *Hub) Subscribe(key string) (<-chan struct{}, func()) {
+	wake := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(wake)
+		return wake, func() {}
+	}
+
+	if h.subscribers[key] == nil {
+		h.subscribers[key] = make(map[chan struct{}]struct{})
+	}
+	h.subscribers[key][wake] = struct{}{}
+
+	return wake, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		if _, ok := h.subscribers[key][wake]; !ok {
+			return
+		}
+		delete(h.subscribers[key], wake)
+		if len(h.subscribers[key]) == 0 {
+			delete(h.subscribers, key)
+		}
+		close(wake)
+	}
+}
+
+// Run listens until c is done, then closes every subscription.
+func (h *Hub) Run(c context.Context) error {
+	defer h.close()
+
+	if err := h.listener.Listen(h.channel); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-c.Done():
+			return nil
+		case notification := <-h.listener.Notify:
+			// Notifications sent while the connection was down are lost,
+			// so everyone is woken after reconnecting to catch up.
+			if notification == nil {
+				h.wakeAll()
+				continue
+			}
+			h.wake(notification.Extra)
+		}
+	}
+}
+
+// Check fails while the listener is not connected to the database.
+func (h *Hub) Check(c context.Context) error {
+	return h.listener.Ping()
+}
+
+func (h *Hub) wake(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for wake := range h.subscribers[key] {
+		signal(wake)
+	}
+}
+
+func (h *Hub) wakeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, subscribers := range h.subscribers {
+		for wake := range subscribers {
+			signal(wake)
+		}
+	}
+}
+
+func (h *Hub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for key, subscribers := range h.subscribers {
+		for wake := range subscribers {
+			close(wake)
+		}
+		delete(h.subscribers, key)
+	}
+
+	if err := h.listener.Close(); err != nil {
+		log.Println("Failed to close notification listener:", err)
+	}
+}
+
+// signal wakes a subscriber unless a wakeup is already pending.
+func signal(wake chan struct{}) {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,157 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable clock for MemoryStore.now.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestMemoryStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	store.lastSweep = clock.now
+	return store, clock
+}
+
+func TestMemoryStoreTokenBucket(t *testing.T) {
+	// 60 requests per minute refill one token per second, up to a burst of 3.
+	limit := Limit{Requests: 60, Window: time.Minute, Burst: 3}
+
+	type step struct {
+		advance       time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantRetryIn   time.Duration
+		wantResetIn   time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "burst then rejected",
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 2, wantResetIn: time.Second},
+				{wantAllowed: true, wantRemaining: 1, wantResetIn: 2 * time.Second},
+				{wantAllowed: true, wantRemaining: 0, wantResetIn: 3 * time.Second},
+				{wantAllowed: false, wantRemaining: 0, wantRetryIn: time.Second, wantResetIn: 3 * time.Second},
+			},
+		},
+		{
+			name: "refills one token per second",
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 2, wantResetIn: time.Second},
+				{wantAllowed: true, wantRemaining: 1, wantResetIn: 2 * time.Second},
+				{wantAllowed: true, wantRemaining: 0, wantResetIn: 3 * time.Second},
+				{advance: 500 * time.Millisecond, wantAllowed: false, wantRemaining: 0, wantRetryIn: 500 * time.Millisecond, wantResetIn: 2500 * time.Millisecond},
+				{advance: 500 * time.Millisecond, wantAllowed: true, wantRemaining: 0, wantResetIn: 3 * time.Second},
+			},
+		},
+		{
+			name: "refill is capped at the burst",
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 2, wantResetIn: time.Second},
+				{advance: time.Hour, wantAllowed: true, wantRemaining: 2, wantResetIn: time.Second},
+				{wantAllowed: true, wantRemaining: 1, wantResetIn: 2 * time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, clock := newTestMemoryStore()
+			for i, step := range tt.steps {
+				clock.Advance(step.advance)
+
+				result, err := store.Allow(t.Context(), "client", limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want := Result{
+					Allowed:   step.wantAllowed,
+					Limit:     3,
+					Remaining: step.wantRemaining,
+					ResetIn:   step.wantResetIn,
+					RetryIn:   step.wantRetryIn,
+				}
+				if result != want {
+					t.Errorf("request %d: result = %+v, want %+v", i+1, result, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreSeparatesKeys(t *testing.T) {
+	store, _ := newTestMemoryStore()
+	limit := Limit{Requests: 1, Window: time.Minute}
+
+	for _, key := range []string{"auth:ip:203.0.113.7", "auth:ip:203.0.113.8", "api:ip:203.0.113.7"} {
+		result, err := store.Allow(t.Context(), key, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed {
+			t.Errorf("first request of %s was rejected", key)
+		}
+	}
+
+	result, err := store.Allow(t.Context(), "auth:ip:203.0.113.7", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed {
+		t.Error("second request of auth:ip:203.0.113.7 was allowed")
+	}
+}
+
+func TestMemoryStoreUsesRequestsWithoutBurst(t *testing.T) {
+	store, _ := newTestMemoryStore()
+	limit := Limit{Requests: 2, Window: time.Minute}
+
+	for i, wantAllowed := range []bool{true, true, false} {
+		result, err := store.Allow(t.Context(), "client", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Allowed != wantAllowed || result.Limit != 2 {
+			t.Errorf("request %d: allowed = %v, limit = %d, want %v, 2", i+1, result.Allowed, result.Limit, wantAllowed)
+		}
+	}
+}
+
+func TestMemoryStoreSweepsFullBuckets(t *testing.T) {
+	store, clock := newTestMemoryStore()
+	limit := Limit{Requests: 60, Window: time.Minute, Burst: 3}
+
+	if _, err := store.Allow(t.Context(), "idle", limit); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(sweepInterval)
+	if _, err := store.Allow(t.Context(), "active", limit); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := store.buckets["idle"]; ok {
+		t.Error("idle bucket was not swept")
+	}
+	if _, ok := store.buckets["active"]; !ok {
+		t.Error("active bucket was swept")
+	}
+}
@@ -0,0 +1,39 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit describes a token bucket: Burst tokens at most, refilled at a rate
+// of Requests per Window.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+	Burst    int
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	ResetIn   time.Duration
+	RetryIn   time.Duration
+}
+
+// Store keeps the bucket state. The in-memory store is per process; a shared
+// implementation is needed to enforce limits across replicas.
+type Store interface {
+	Allow(c context.Context, key string, limit Limit) (Result, error)
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+func (l Limit) ratePerSecond() float64 {
+	return float64(l.Requests) / l.Window.Seconds()
+}
@@ -0,0 +1,64 @@
+package search
+
+import (
+	"app/internal/domain/model"
+	"context"
+	"math"
+	"sort"
+)
+
+// CustomerMemoryIndex ranks customers in memory. It is the fallback used
+// when the database has no trigram support.
+type CustomerMemoryIndex struct {
+	load      func(c context.Context) ([]model.Customer, error)
+	threshold float64
+}
+
+func NewCustomerMemoryIndex(load func(c context.Context) ([]model.Customer, error), threshold float64) *CustomerMemoryIndex {
+	return &CustomerMemoryIndex{
+		load:      load,
+		threshold: threshold,
+	}
+}
+
+func (i *CustomerMemoryIndex) Search(c context.Context, query string, limit int, offset int) ([]model.CustomerMatch, int, error) {
+	customers, err := i.load(c)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query = Normalize(query)
+
+	var matches []model.CustomerMatch
+	for _, customer := range customers {
+		score := math.Max(
+			WordSimilarity(query, Normalize(customer.Name)),
+			WordSimilarity(query, Normalize(customer.Email)),
+		)
+		if score >= i.threshold {
+			matches = append(matches, model.CustomerMatch{Customer: customer, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		if matches[a].Name != matches[b].Name {
+			return matches[a].Name < matches[b].Name
+		}
+		return matches[a].ID < matches[b].ID
+	})
+
+	total := len(matches)
+	if offset >= total {
+		return nil, total, nil
+	}
+
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	return matches[offset:end], total, nil
+}
@@ -0,0 +1,75 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize lowercases s and strips its accents, like search_normalize in the
+// database.
+func Normalize(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(t, s)
+	if err != nil {
+		result = s
+	}
+	return strings.ToLower(result)
+}
+
+// words splits s on anything that is not a letter or digit, as pg_trgm does.
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// trigrams returns the pg_trgm trigrams of every word in s, each word padded
+// with two spaces before and one after.
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range words(s) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+func similarity(a map[string]bool, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for trigram := range a {
+		if b[trigram] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// WordSimilarity approximates pg_trgm word_similarity: the best similarity
+// between query and any run of consecutive words of text.
+func WordSimilarity(query string, text string) float64 {
+	queryTrigrams := trigrams(query)
+	textWords := words(text)
+
+	best := 0.0
+	for start := range textWords {
+		for end := start + 1; end <= len(textWords); end++ {
+			score := similarity(queryTrigrams, trigrams(strings.Join(textWords[start:end], " ")))
+			if score > best {
+				best = score
+			}
+		}
+	}
+
+	return best
+}
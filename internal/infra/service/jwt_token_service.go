@@ -16,9 +16,10 @@ func NewTokenService(secretKey string, expireTime time.Duration) *jwtTokenServic
 	return &jwtTokenService{secretKey: secretKey, expireTime: expireTime}
 }
 
-func (s *jwtTokenService) Generate(userID string) (string, error) {
+func (s *jwtTokenService) Generate(userID string, role string) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": userID,
+		"role":    role,
 		"exp":     time.Now().Add(s.expireTime).Unix(),
 		"iat":     time.Now().Unix(),
 	}
@@ -33,10 +34,10 @@ func (s *jwtTokenService) Generate(userID string) (string, error) {
 	return signedToken, nil
 }
 
-func (s *jwtTokenService) Validate(token string) (string, error) {
+func (s *jwtTokenService) Validate(token string) (*TokenClaims, error) {
 	token, err := parseTokenPrefix(token)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	claims := jwt.MapClaims{}
@@ -44,15 +45,18 @@ func (s *jwtTokenService) Validate(token string) (string, error) {
 		return []byte(s.secretKey), nil
 	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	userID, ok := claims["user_id"].(string)
 	if !ok {
-		return "", errors.New("invalid token")
+		return nil, errors.New("invalid token")
 	}
 
-	return userID, nil
+	// Tokens issued before roles existed carry no role claim.
+	role, _ := claims["role"].(string)
+
+	return &TokenClaims{UserID: userID, Role: role}, nil
 }
 
 func parseTokenPrefix(token string) (string, error) {
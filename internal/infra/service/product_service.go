@@ -2,23 +2,36 @@ package service
 
 import (
 	"app/internal/domain/model"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 )
 
-type ProductService struct{}
+type ProductService struct {
+	url    string
+	client *http.Client
+}
 
-func NewProductService() *ProductService {
-	return &ProductService{}
+func NewProductService(url string, timeout time.Duration) *ProductService {
+	return &ProductService{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
 }
 
 func (s *ProductService) GetAll() ([]model.Product, error) {
-	resp, err := http.Get("https://fakestoreapi.com/products")
+	resp, err := s.client.Get(s.url)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("product catalog returned status %d", resp.StatusCode)
+	}
+
 	var products []model.Product
 	if err := json.NewDecoder(resp.Body).Decode(&products); err != nil {
 		return nil, err
@@ -26,3 +39,23 @@ func (s *ProductService) GetAll() ([]model.Product, error) {
 
 	return products, nil
 }
+
+// Ping checks that the product catalog is reachable.
+func (s *ProductService) Ping(c context.Context) error {
+	req, err := http.NewRequestWithContext(c, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("product catalog returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
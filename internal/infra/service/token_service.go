@@ -1,6 +1,11 @@
 package service
 
+type TokenClaims struct {
+	UserID string
+	Role   string
+}
+
 type TokenService interface {
-	Generate(userID string) (string, error)
-	Validate(token string) (string, error)
+	Generate(userID string, role string) (string, error)
+	Validate(token string) (*TokenClaims, error)
 }
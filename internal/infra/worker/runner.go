@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Job is a background task run every Interval until the runner stops.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(c context.Context) error
+}
+
+type Runner struct {
+	jobs    []Job
+	wg      sync.WaitGroup
+	cancel  context.CancelFunc
+	running atomic.Int32
+}
+
+func NewRunner() *Runner {
+	return &Runner{}
+}
+
+// Add registers a job. Jobs must be added before Start.
+func (r *Runner) Add(job Job) {
+	r.jobs = append(r.jobs, job)
+}
+
+func (r *Runner) Start(c context.Context) {
+	c, r.cancel = context.WithCancel(c)
+
+	for _, job := range r.jobs {
+		r.wg.Add(1)
+		r.running.Add(1)
+		go func(job Job) {
+			defer r.wg.Done()
+			defer r.running.Add(-1)
+			r.loop(c, job)
+		}(job)
+	}
+}
+
+// Stop cancels every job and waits for the running ones to return, or for c
+// to be done.
+func (r *Runner) Stop(c context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-c.Done():
+		return c.Err()
+	}
+}
+
+// Check reports an error unless every registered job is running.
+func (r *Runner) Check(c context.Context) error {
+	if int(r.running.Load()) != len(r.jobs) {
+		return errors.New("background workers are not running")
+	}
+	return nil
+}
+
+func (r *Runner) loop(c context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Done():
+			return
+		case <-ticker.C:
+			if err := job.Run(c); err != nil && c.Err() == nil {
+				log.Println("Error running job", job.Name, err)
+			}
+		}
+	}
+}